@@ -8,7 +8,9 @@ import (
 	"github.com/pos-saas/restaurant-pos/config"
 	"github.com/pos-saas/restaurant-pos/internal/controller"
 	"github.com/pos-saas/restaurant-pos/internal/database"
+	"github.com/pos-saas/restaurant-pos/internal/events"
 	"github.com/pos-saas/restaurant-pos/internal/middleware"
+	"github.com/pos-saas/restaurant-pos/internal/permissions"
 	"github.com/pos-saas/restaurant-pos/internal/repository"
 	"github.com/pos-saas/restaurant-pos/internal/service"
 )
@@ -26,54 +28,263 @@ func main() {
 	defer pool.Close()
 
 	gin.SetMode(cfg.Server.GinMode)
-	r := gin.Default()
+	r := gin.New()
+	// El token de /events puede llegar en la URL: no debe quedar en el log
+	r.Use(middleware.Logger("access_token"), gin.Recovery())
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:3000"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Terminal-Token"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 	}))
 
 	// Repositories
+	txManager := repository.NewTxManager(pool)
 	authRepo := repository.NewAuthRepository(pool)
+	roleRepo := repository.NewRoleRepository(pool)
+	sessionRepo := repository.NewSessionRepository(pool)
+	terminalRepo := repository.NewTerminalRepository(pool)
 	productRepo := repository.NewProductRepository(pool)
 	categoryRepo := repository.NewCategoryRepository(pool)
 	saleRepo := repository.NewSaleRepository(pool)
+	checkRepo := repository.NewSaleCheckRepository(pool)
+	refundRepo := repository.NewRefundRepository(pool)
+	cashSessionRepo := repository.NewCashSessionRepository(pool)
+	reportRepo := repository.NewReportRepository(pool)
+	taxRateRepo := repository.NewTaxRateRepository(pool)
+	kitchenRepo := repository.NewKitchenRepository(pool)
+	modifierRepo := repository.NewModifierRepository(pool)
+	variantRepo := repository.NewProductVariantRepository(pool)
+	comboRepo := repository.NewComboRepository(pool)
+	inventoryRepo := repository.NewInventoryRepository(pool)
+	purchasingRepo := repository.NewPurchasingRepository(pool)
+	tableRepo := repository.NewTableRepository(pool)
+
+	// Eventos en tiempo real; en memoria mientras haya una sola instancia
+	broker := events.NewMemoryBroker()
 
 	// Services
-	authService := service.NewAuthService(authRepo, cfg.JWT.Secret, cfg.JWT.ExpirationHours)
-	productService := service.NewProductService(productRepo, categoryRepo)
-	saleService := service.NewSaleService(saleRepo, productRepo, authRepo)
-	pdfService := service.NewPDFService(saleRepo, productRepo, authRepo)
+	authService := service.NewAuthService(txManager, authRepo, roleRepo, sessionRepo, terminalRepo, cfg.JWT.Secret, cfg.JWT.AccessTTL(), cfg.JWT.RefreshTTL())
+	productService := service.NewProductService(txManager, productRepo, categoryRepo, taxRateRepo, variantRepo, comboRepo, kitchenRepo, broker)
+	saleService := service.NewSaleService(txManager, saleRepo, productRepo, variantRepo, comboRepo, categoryRepo, taxRateRepo, modifierRepo, inventoryRepo, authRepo, cashSessionRepo, kitchenRepo, broker)
+	refundService := service.NewRefundService(txManager, refundRepo, saleRepo, cashSessionRepo)
+	cashSessionService := service.NewCashSessionService(txManager, cashSessionRepo)
+	reportService := service.NewReportService(reportRepo, authRepo)
+	taxRateService := service.NewTaxRateService(taxRateRepo)
+	restaurantService := service.NewRestaurantService(authRepo, taxRateRepo)
+	userService := service.NewUserService(txManager, authRepo, roleRepo, sessionRepo)
+	roleService := service.NewRoleService(txManager, roleRepo)
+	terminalService := service.NewTerminalService(txManager, terminalRepo, authRepo, sessionRepo)
+	modifierService := service.NewModifierService(txManager, modifierRepo, productRepo)
+	inventoryService := service.NewInventoryService(txManager, inventoryRepo, productRepo, variantRepo, modifierRepo)
+	purchasingService := service.NewPurchasingService(txManager, purchasingRepo, inventoryRepo, productRepo, variantRepo, comboRepo, categoryRepo, taxRateRepo, authRepo)
+	tableService := service.NewTableService(tableRepo)
+	orderService := service.NewOrderService(txManager, saleRepo, checkRepo, tableRepo, inventoryRepo, categoryRepo, taxRateRepo, authRepo, kitchenRepo, broker, saleService)
+	kitchenService := service.NewKitchenService(txManager, kitchenRepo, broker)
+	checkService := service.NewCheckService(txManager, saleRepo, checkRepo, authRepo, taxRateRepo, categoryRepo, orderService)
+	receiptService := service.NewReceiptService(saleRepo, productRepo, authRepo, kitchenRepo)
+	pdfService := service.NewPDFService(saleRepo, checkRepo, refundRepo, productRepo, authRepo, cashSessionRepo)
 
 	// Controllers
 	authCtrl := controller.NewAuthController(authService)
 	productCtrl := controller.NewProductController(productService)
-	categoryCtrl := controller.NewCategoryController(categoryRepo)
-	saleCtrl := controller.NewSaleController(saleService, pdfService)
+	categoryCtrl := controller.NewCategoryController(categoryRepo, taxRateRepo, kitchenRepo)
+	saleCtrl := controller.NewSaleController(saleService, pdfService, receiptService)
+	refundCtrl := controller.NewRefundController(refundService, pdfService)
+	cashSessionCtrl := controller.NewCashSessionController(cashSessionService, pdfService)
+	reportCtrl := controller.NewReportController(reportService)
+	taxRateCtrl := controller.NewTaxRateController(taxRateService)
+	restaurantCtrl := controller.NewRestaurantController(restaurantService)
+	userCtrl := controller.NewUserController(userService)
+	roleCtrl := controller.NewRoleController(roleService)
+	terminalCtrl := controller.NewTerminalController(terminalService, authService)
+	modifierCtrl := controller.NewModifierController(modifierService)
+	inventoryCtrl := controller.NewInventoryController(inventoryService)
+	purchasingCtrl := controller.NewPurchasingController(purchasingService)
+	tableCtrl := controller.NewTableController(tableService)
+	orderCtrl := controller.NewOrderController(orderService)
+	checkCtrl := controller.NewCheckController(checkService, pdfService)
+	kitchenCtrl := controller.NewKitchenController(kitchenService, receiptService)
+	eventCtrl := controller.NewEventController(broker, authService)
 
 	// Public routes
 	api := r.Group("/api/v1")
 	api.POST("/auth/register", authCtrl.Register)
 	api.POST("/auth/login", authCtrl.Login)
+	api.POST("/auth/refresh", authCtrl.Refresh)
+	api.POST("/auth/logout", authCtrl.Logout)
+
+	// Terminales compartidas: se identifican con X-Terminal-Token y el usuario
+	// entra con su PIN
+	api.GET("/terminal/users", terminalCtrl.Users)
+	api.POST("/terminal/switch", terminalCtrl.SwitchUser)
+
+	// Cada ruta protegida exige un permiso; los de cada rol están en el paquete permissions
+	can := middleware.RequirePermission
+
+	// EventSource no envía cabeceras: el token puede ir en ?access_token=.
+	// Cada usuario recibe solo los eventos que puede consultar
+	api.GET("/events", middleware.TokenFromQuery("access_token"), middleware.AuthRequired(cfg.JWT.Secret, authService), can(permissions.MenuView), eventCtrl.Stream)
 
 	// Protected routes
 	protected := api.Group("")
-	protected.Use(middleware.AuthRequired(cfg.JWT.Secret))
+	protected.Use(middleware.AuthRequired(cfg.JWT.Secret, authService))
 	{
-		protected.GET("/categories", categoryCtrl.List)
-		protected.POST("/categories", categoryCtrl.Create)
-
-		protected.GET("/products", productCtrl.List)
-		protected.GET("/products/:id", productCtrl.GetByID)
-		protected.POST("/products", productCtrl.Create)
-		protected.PUT("/products/:id", productCtrl.Update)
-		protected.DELETE("/products/:id", productCtrl.Delete)
-
-		protected.POST("/sales", saleCtrl.Create)
-		protected.GET("/sales/:id", saleCtrl.GetByID)
-		protected.GET("/sales/:id/pdf", saleCtrl.GeneratePDF)
+		protected.GET("/restaurant", can(permissions.MenuView), restaurantCtrl.Get)
+		protected.PUT("/restaurant", can(permissions.SettingsManage), restaurantCtrl.Update)
+
+		users := protected.Group("/users", can(permissions.UsersManage))
+		users.GET("", userCtrl.List)
+		users.GET("/:id", userCtrl.Get)
+		users.POST("", userCtrl.Create)
+		users.PUT("/:id", userCtrl.Update)
+		users.DELETE("/:id", userCtrl.Delete)
+		users.POST("/:id/password", userCtrl.ResetPassword)
+		users.PUT("/:id/pin", userCtrl.SetPIN)
+		users.DELETE("/:id/pin", userCtrl.ClearPIN)
+
+		roles := protected.Group("/roles", can(permissions.UsersManage))
+		roles.GET("", roleCtrl.List)
+		roles.GET("/:id", roleCtrl.Get)
+		roles.POST("", roleCtrl.Create)
+		roles.PUT("/:id", roleCtrl.Update)
+		roles.DELETE("/:id", roleCtrl.Delete)
+		protected.GET("/permissions", can(permissions.UsersManage), roleCtrl.Permissions)
+
+		terminals := protected.Group("/terminals", can(permissions.SettingsManage))
+		terminals.GET("", terminalCtrl.List)
+		terminals.GET("/:id", terminalCtrl.Get)
+		terminals.POST("", terminalCtrl.Create)
+		terminals.PUT("/:id", terminalCtrl.Update)
+		terminals.DELETE("/:id", terminalCtrl.Delete)
+
+		protected.GET("/tax-rates", can(permissions.MenuView), taxRateCtrl.List)
+		protected.POST("/tax-rates", can(permissions.SettingsManage), taxRateCtrl.Create)
+		protected.PUT("/tax-rates/:id", can(permissions.SettingsManage), taxRateCtrl.Update)
+		protected.DELETE("/tax-rates/:id", can(permissions.SettingsManage), taxRateCtrl.Delete)
+
+		protected.GET("/categories", can(permissions.MenuView), categoryCtrl.List)
+		protected.POST("/categories", can(permissions.ProductsWrite), categoryCtrl.Create)
+
+		protected.GET("/products", can(permissions.MenuView), productCtrl.List)
+		protected.GET("/products/:id", can(permissions.MenuView), productCtrl.GetByID)
+		protected.POST("/products", can(permissions.ProductsWrite), productCtrl.Create)
+		protected.PUT("/products/:id", can(permissions.ProductsWrite), productCtrl.Update)
+		protected.DELETE("/products/:id", can(permissions.ProductsWrite), productCtrl.Delete)
+		protected.GET("/products/:id/variants", can(permissions.MenuView), productCtrl.ListVariants)
+		protected.POST("/products/:id/variants", can(permissions.ProductsWrite), productCtrl.CreateVariant)
+		protected.PUT("/products/:id/variants/:variant_id", can(permissions.ProductsWrite), productCtrl.UpdateVariant)
+		protected.DELETE("/products/:id/variants/:variant_id", can(permissions.ProductsWrite), productCtrl.DeleteVariant)
+		protected.GET("/products/:id/combo-slots", can(permissions.MenuView), productCtrl.ListComboSlots)
+		protected.PUT("/products/:id/combo-slots", can(permissions.ProductsWrite), productCtrl.SetComboSlots)
+		protected.GET("/products/:id/modifier-groups", can(permissions.MenuView), modifierCtrl.ListProductGroups)
+		protected.PUT("/products/:id/modifier-groups", can(permissions.ProductsWrite), modifierCtrl.SetProductGroups)
+		protected.GET("/products/:id/recipe", can(permissions.InventoryView), inventoryCtrl.GetProductRecipe)
+		protected.PUT("/products/:id/recipe", can(permissions.InventoryWrite), inventoryCtrl.SetProductRecipe)
+
+		protected.GET("/modifier-groups", can(permissions.MenuView), modifierCtrl.ListGroups)
+		protected.GET("/modifier-groups/:id", can(permissions.MenuView), modifierCtrl.GetGroup)
+		protected.POST("/modifier-groups", can(permissions.ProductsWrite), modifierCtrl.CreateGroup)
+		protected.PUT("/modifier-groups/:id", can(permissions.ProductsWrite), modifierCtrl.UpdateGroup)
+		protected.DELETE("/modifier-groups/:id", can(permissions.ProductsWrite), modifierCtrl.DeleteGroup)
+		protected.POST("/modifier-groups/:id/options", can(permissions.ProductsWrite), modifierCtrl.CreateOption)
+		protected.PUT("/modifier-groups/:id/options/:option_id", can(permissions.ProductsWrite), modifierCtrl.UpdateOption)
+		protected.DELETE("/modifier-groups/:id/options/:option_id", can(permissions.ProductsWrite), modifierCtrl.DeleteOption)
+		protected.GET("/modifier-groups/:id/options/:option_id/recipe", can(permissions.InventoryView), inventoryCtrl.GetOptionRecipe)
+		protected.PUT("/modifier-groups/:id/options/:option_id/recipe", can(permissions.InventoryWrite), inventoryCtrl.SetOptionRecipe)
+
+		protected.GET("/inventory/ingredients", can(permissions.InventoryView), inventoryCtrl.ListIngredients)
+		protected.GET("/inventory/ingredients/:id", can(permissions.InventoryView), inventoryCtrl.GetIngredient)
+		protected.POST("/inventory/ingredients", can(permissions.InventoryWrite), inventoryCtrl.CreateIngredient)
+		protected.PUT("/inventory/ingredients/:id", can(permissions.InventoryWrite), inventoryCtrl.UpdateIngredient)
+		protected.DELETE("/inventory/ingredients/:id", can(permissions.InventoryWrite), inventoryCtrl.DeleteIngredient)
+		protected.POST("/inventory/ingredients/:id/adjustments", can(permissions.InventoryWrite), inventoryCtrl.Adjust)
+		protected.GET("/inventory/ingredients/:id/movements", can(permissions.InventoryView), inventoryCtrl.ListMovements)
+		protected.GET("/inventory/low-stock", can(permissions.InventoryView), inventoryCtrl.LowStock)
+
+		suppliers := protected.Group("/suppliers", can(permissions.PurchasingManage))
+		suppliers.GET("", purchasingCtrl.ListSuppliers)
+		suppliers.GET("/:id", purchasingCtrl.GetSupplier)
+		suppliers.POST("", purchasingCtrl.CreateSupplier)
+		suppliers.PUT("/:id", purchasingCtrl.UpdateSupplier)
+		suppliers.DELETE("/:id", purchasingCtrl.DeleteSupplier)
+
+		purchaseOrders := protected.Group("/purchase-orders", can(permissions.PurchasingManage))
+		purchaseOrders.GET("", purchasingCtrl.ListOrders)
+		purchaseOrders.POST("", purchasingCtrl.CreateOrder)
+		purchaseOrders.GET("/:id", purchasingCtrl.GetOrder)
+		purchaseOrders.POST("/:id/receipts", purchasingCtrl.Receive)
+		purchaseOrders.POST("/:id/cancel", purchasingCtrl.CancelOrder)
+		protected.GET("/purchase-ledger", can(permissions.PurchasingManage), purchasingCtrl.Ledger)
+
+		protected.GET("/sales", can(permissions.SalesView), saleCtrl.List)
+		protected.POST("/sales", can(permissions.SalesCreate), saleCtrl.Create)
+		protected.GET("/sales/:id", can(permissions.SalesView), saleCtrl.GetByID)
+		protected.GET("/sales/:id/pdf", can(permissions.SalesView), saleCtrl.GeneratePDF)
+		protected.GET("/sales/:id/receipt.escpos", can(permissions.SalesView), saleCtrl.ReceiptESCPOS)
+		protected.GET("/sales/:id/receipt.pdf", can(permissions.SalesView), saleCtrl.ReceiptPDF)
+		protected.POST("/sales/:id/cancel", can(permissions.SalesCancel), saleCtrl.Cancel)
+
+		protected.GET("/table-areas", can(permissions.MenuView), tableCtrl.ListAreas)
+		protected.POST("/table-areas", can(permissions.SettingsManage), tableCtrl.CreateArea)
+		protected.PUT("/table-areas/:id", can(permissions.SettingsManage), tableCtrl.UpdateArea)
+		protected.DELETE("/table-areas/:id", can(permissions.SettingsManage), tableCtrl.DeleteArea)
+		protected.GET("/tables", can(permissions.MenuView), tableCtrl.List)
+		protected.GET("/tables/:id", can(permissions.MenuView), tableCtrl.GetByID)
+		protected.POST("/tables", can(permissions.SettingsManage), tableCtrl.Create)
+		protected.PUT("/tables/:id", can(permissions.SettingsManage), tableCtrl.Update)
+		protected.DELETE("/tables/:id", can(permissions.SettingsManage), tableCtrl.Delete)
+
+		orders := protected.Group("/orders", can(permissions.OrdersManage))
+		orders.GET("", orderCtrl.List)
+		orders.POST("", orderCtrl.Open)
+		orders.GET("/:id", orderCtrl.GetByID)
+		orders.POST("/:id/items", orderCtrl.AddItems)
+		orders.DELETE("/:id/items/:item_id", orderCtrl.RemoveItem)
+		orders.POST("/:id/transfer", orderCtrl.Transfer)
+		orders.POST("/:id/split", orderCtrl.Split)
+		orders.POST("/:id/merge", orderCtrl.Merge)
+		orders.GET("/:id/checks", checkCtrl.List)
+		orders.POST("/:id/checks", checkCtrl.Split)
+		orders.GET("/:id/checks/:check_id", checkCtrl.GetByID)
+		orders.GET("/:id/checks/:check_id/pdf", checkCtrl.GeneratePDF)
+		// Cobrar una cuenta crea la venta
+		protected.POST("/orders/:id/close", can(permissions.SalesCreate), orderCtrl.Close)
+		protected.POST("/orders/:id/checks/:check_id/pay", can(permissions.SalesCreate), checkCtrl.Pay)
+		protected.POST("/orders/:id/void", can(permissions.SalesCancel), orderCtrl.Void)
+
+		protected.GET("/kitchen/stations", can(permissions.MenuView), kitchenCtrl.ListStations)
+		protected.POST("/kitchen/stations", can(permissions.SettingsManage), kitchenCtrl.CreateStation)
+		protected.PUT("/kitchen/stations/:id", can(permissions.SettingsManage), kitchenCtrl.UpdateStation)
+		protected.DELETE("/kitchen/stations/:id", can(permissions.SettingsManage), kitchenCtrl.DeleteStation)
+		protected.GET("/kitchen/tickets", can(permissions.KitchenView), kitchenCtrl.ListTickets)
+		protected.GET("/kitchen/tickets/:id", can(permissions.KitchenView), kitchenCtrl.GetTicket)
+		protected.POST("/kitchen/tickets/:id/status", can(permissions.KitchenUpdate), kitchenCtrl.UpdateTicketStatus)
+		protected.GET("/kitchen/tickets/:id/ticket.escpos", can(permissions.KitchenView), kitchenCtrl.TicketESCPOS)
+		protected.GET("/kitchen/tickets/:id/ticket.pdf", can(permissions.KitchenView), kitchenCtrl.TicketPDF)
+
+		protected.GET("/sales/:id/refunds", can(permissions.SalesView), refundCtrl.List)
+		protected.POST("/sales/:id/refunds", can(permissions.SalesRefund), refundCtrl.Create)
+		protected.GET("/sales/:id/refunds/:refund_id", can(permissions.SalesView), refundCtrl.GetByID)
+		protected.GET("/sales/:id/refunds/:refund_id/pdf", can(permissions.SalesView), refundCtrl.GeneratePDF)
+
+		protected.GET("/cash-sessions", can(permissions.CashManage), cashSessionCtrl.List)
+		protected.POST("/cash-sessions", can(permissions.CashOperate), cashSessionCtrl.Open)
+		protected.GET("/cash-sessions/current", can(permissions.CashOperate), cashSessionCtrl.Current)
+		protected.GET("/cash-sessions/:id", can(permissions.CashOperate), cashSessionCtrl.GetByID)
+		protected.POST("/cash-sessions/:id/close", can(permissions.CashOperate), cashSessionCtrl.Close)
+		protected.GET("/cash-sessions/:id/z-report", can(permissions.CashOperate), cashSessionCtrl.ZReport)
+
+		reports := protected.Group("/reports", can(permissions.ReportsView))
+		reports.GET("/summary", reportCtrl.Summary)
+		reports.GET("/sales-by-day", reportCtrl.ByDay)
+		reports.GET("/sales-by-hour", reportCtrl.ByHour)
+		reports.GET("/top-products", reportCtrl.TopProducts)
+		reports.GET("/sales-by-category", reportCtrl.ByCategory)
+		reports.GET("/sales-by-cashier", reportCtrl.ByCashier)
+		reports.GET("/tips-by-cashier", reportCtrl.TipsByCashier)
+		reports.GET("/food-cost", purchasingCtrl.FoodCost)
 	}
 
 	addr := ":" + cfg.Server.Port
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -29,8 +30,19 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-	Secret         string
-	ExpirationHours int
+	Secret string
+	// AccessMinutes es la vigencia del access token; se renueva con el refresh
+	// token, que vence tras RefreshDays sin usarse
+	AccessMinutes int
+	RefreshDays   int
+}
+
+func (j JWTConfig) AccessTTL() time.Duration {
+	return time.Duration(j.AccessMinutes) * time.Minute
+}
+
+func (j JWTConfig) RefreshTTL() time.Duration {
+	return time.Duration(j.RefreshDays) * 24 * time.Hour
 }
 
 func (d DatabaseConfig) DSN() string {
@@ -43,7 +55,8 @@ func (d DatabaseConfig) DSN() string {
 func Load() (*Config, error) {
 	_ = godotenv.Load()
 
-	expHours, _ := strconv.Atoi(getEnv("JWT_EXPIRATION_HOURS", "24"))
+	accessMinutes, _ := strconv.Atoi(getEnv("JWT_ACCESS_MINUTES", "15"))
+	refreshDays, _ := strconv.Atoi(getEnv("JWT_REFRESH_DAYS", "30"))
 
 	return &Config{
 		Server: ServerConfig{
@@ -59,8 +72,9 @@ func Load() (*Config, error) {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		JWT: JWTConfig{
-			Secret:         getEnv("JWT_SECRET", "change-me-in-production"),
-			ExpirationHours: expHours,
+			Secret:        getEnv("JWT_SECRET", "change-me-in-production"),
+			AccessMinutes: accessMinutes,
+			RefreshDays:   refreshDays,
 		},
 	}, nil
 }
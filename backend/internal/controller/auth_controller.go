@@ -44,13 +44,14 @@ func (c *AuthController) Register(ctx *gin.Context) {
 
 // Login godoc
 // @Summary      Iniciar sesión
-// @Description  Autentica usuario y devuelve JWT
+// @Description  Autentica al usuario por su email y devuelve JWT; si el email existe en varios restaurantes responde 409 con la lista para elegir
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        body  body  service.LoginInput  true  "Credenciales"
 // @Success      200   {object}  service.AuthResponse
 // @Failure      401   {object}  map[string]string
+// @Failure      409   {object}  map[string]interface{}
 // @Router       /auth/login [post]
 func (c *AuthController) Login(ctx *gin.Context) {
 	var input service.LoginInput
@@ -61,9 +62,62 @@ func (c *AuthController) Login(ctx *gin.Context) {
 
 	resp, err := c.authService.Login(ctx.Request.Context(), input)
 	if err != nil {
+		if choice, ok := err.(*service.RestaurantChoiceError); ok {
+			ctx.JSON(http.StatusConflict, gin.H{"error": choice.Error(), "restaurants": choice.Restaurants})
+			return
+		}
 		handleError(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, resp)
 }
+
+// Refresh godoc
+// @Summary      Renovar sesión
+// @Description  Canjea el refresh token por un access token y un refresh token nuevos; un refresh token ya usado cierra la sesión
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  service.RefreshInput  true  "Refresh token"
+// @Success      200   {object}  service.AuthResponse
+// @Failure      401   {object}  map[string]string
+// @Router       /auth/refresh [post]
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	var input service.RefreshInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	resp, err := c.authService.Refresh(ctx.Request.Context(), input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Logout godoc
+// @Summary      Cerrar sesión
+// @Description  Revoca la sesión del refresh token; sus access tokens dejan de valer
+// @Tags         auth
+// @Accept       json
+// @Param        body  body  service.RefreshInput  true  "Refresh token"
+// @Success      204
+// @Router       /auth/logout [post]
+func (c *AuthController) Logout(ctx *gin.Context) {
+	var input service.RefreshInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	if err := c.authService.Logout(ctx.Request.Context(), input); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
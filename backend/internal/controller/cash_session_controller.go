@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/middleware"
+	"github.com/pos-saas/restaurant-pos/internal/service"
+)
+
+type CashSessionController struct {
+	cashSessionService *service.CashSessionService
+	pdfService         *service.PDFService
+}
+
+func NewCashSessionController(cashSessionService *service.CashSessionService, pdfService *service.PDFService) *CashSessionController {
+	return &CashSessionController{cashSessionService: cashSessionService, pdfService: pdfService}
+}
+
+func (c *CashSessionController) getIDs(ctx *gin.Context) (restaurantID, userID uuid.UUID, ok bool) {
+	rid, ok1 := ctx.Get("restaurant_id")
+	uid, ok2 := ctx.Get("user_id")
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	ridStr, ok1 := rid.(string)
+	uidStr, ok2 := uid.(string)
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedRid, err := uuid.Parse(ridStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedUid, err := uuid.Parse(uidStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return parsedRid, parsedUid, true
+}
+
+func (c *CashSessionController) Open(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.OpenCashSessionInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	session, err := c.cashSessionService.Open(ctx.Request.Context(), restaurantID, userID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, session)
+}
+
+func (c *CashSessionController) Current(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	summary, err := c.cashSessionService.Current(ctx.Request.Context(), restaurantID, userID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, summary)
+}
+
+func (c *CashSessionController) List(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	sessions, err := c.cashSessionService.List(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+func (c *CashSessionController) GetByID(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	summary, err := c.cashSessionService.GetByID(ctx.Request.Context(), restaurantID, sessionID, userID, middleware.Permissions(ctx))
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, summary)
+}
+
+func (c *CashSessionController) Close(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var input service.CloseCashSessionInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	summary, err := c.cashSessionService.Close(ctx.Request.Context(), restaurantID, sessionID, userID, middleware.Permissions(ctx), input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, summary)
+}
+
+func (c *CashSessionController) ZReport(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if _, err := c.cashSessionService.GetByID(ctx.Request.Context(), restaurantID, sessionID, userID, middleware.Permissions(ctx)); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	pdfBytes, err := c.pdfService.GenerateZReport(ctx.Request.Context(), restaurantID, sessionID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename=corte-caja-"+sessionID.String()+".pdf")
+	ctx.Header("Content-Type", "application/pdf")
+	ctx.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
@@ -5,22 +5,27 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
 	"github.com/pos-saas/restaurant-pos/internal/models"
 	"github.com/pos-saas/restaurant-pos/internal/repository"
 )
 
 type CategoryController struct {
 	categoryRepo *repository.CategoryRepository
+	taxRateRepo  *repository.TaxRateRepository
+	kitchenRepo  *repository.KitchenRepository
 }
 
-func NewCategoryController(categoryRepo *repository.CategoryRepository) *CategoryController {
-	return &CategoryController{categoryRepo: categoryRepo}
+func NewCategoryController(categoryRepo *repository.CategoryRepository, taxRateRepo *repository.TaxRateRepository, kitchenRepo *repository.KitchenRepository) *CategoryController {
+	return &CategoryController{categoryRepo: categoryRepo, taxRateRepo: taxRateRepo, kitchenRepo: kitchenRepo}
 }
 
 type CreateCategoryInput struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	SortOrder   int    `json:"sort_order"`
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	SortOrder   int     `json:"sort_order"`
+	TaxRateID   *string `json:"tax_rate_id"`
+	StationID   *string `json:"station_id"` // estación de cocina de sus productos
 }
 
 func (c *CategoryController) getRestaurantID(ctx *gin.Context) (uuid.UUID, bool) {
@@ -54,12 +59,50 @@ func (c *CategoryController) Create(ctx *gin.Context) {
 		return
 	}
 
+	var taxRateID *uuid.UUID
+	if input.TaxRateID != nil && *input.TaxRateID != "" {
+		id, err := uuid.Parse(*input.TaxRateID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "tax_rate_id inválido"})
+			return
+		}
+		if _, err := c.taxRateRepo.GetByID(ctx.Request.Context(), restaurantID, id); err != nil {
+			if errors.Is(err, errors.ErrNotFound) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "tasa de impuesto no encontrada"})
+				return
+			}
+			handleError(ctx, err)
+			return
+		}
+		taxRateID = &id
+	}
+
+	var stationID *uuid.UUID
+	if input.StationID != nil && *input.StationID != "" {
+		id, err := uuid.Parse(*input.StationID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "station_id inválido"})
+			return
+		}
+		if _, err := c.kitchenRepo.GetStation(ctx.Request.Context(), restaurantID, id); err != nil {
+			if errors.Is(err, errors.ErrNotFound) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "estación no encontrada"})
+				return
+			}
+			handleError(ctx, err)
+			return
+		}
+		stationID = &id
+	}
+
 	cat := &models.Category{
 		ID:           uuid.New(),
 		RestaurantID: restaurantID,
 		Name:         input.Name,
 		Description:  input.Description,
 		SortOrder:    input.SortOrder,
+		TaxRateID:    taxRateID,
+		StationID:    stationID,
 	}
 	if err := c.categoryRepo.Create(ctx.Request.Context(), cat); err != nil {
 		handleError(ctx, err)
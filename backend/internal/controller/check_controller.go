@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/middleware"
+	"github.com/pos-saas/restaurant-pos/internal/service"
+)
+
+type CheckController struct {
+	checkService *service.CheckService
+	pdfService   *service.PDFService
+}
+
+func NewCheckController(checkService *service.CheckService, pdfService *service.PDFService) *CheckController {
+	return &CheckController{checkService: checkService, pdfService: pdfService}
+}
+
+func (c *CheckController) getIDs(ctx *gin.Context) (restaurantID, userID uuid.UUID, ok bool) {
+	rid, ok1 := ctx.Get("restaurant_id")
+	uid, ok2 := ctx.Get("user_id")
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	ridStr, ok1 := rid.(string)
+	uidStr, ok2 := uid.(string)
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedRid, err := uuid.Parse(ridStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedUid, err := uuid.Parse(uidStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return parsedRid, parsedUid, true
+}
+
+// parseParam lee un UUID de la ruta
+func (c *CheckController) parseParam(ctx *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(name))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (c *CheckController) List(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	checks, err := c.checkService.List(ctx.Request.Context(), restaurantID, orderID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, checks)
+}
+
+func (c *CheckController) Split(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.SplitChecksInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	checks, err := c.checkService.Split(ctx.Request.Context(), restaurantID, orderID, middleware.Permissions(ctx), input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, checks)
+}
+
+func (c *CheckController) GetByID(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+	checkID, ok := c.parseParam(ctx, "check_id")
+	if !ok {
+		return
+	}
+
+	check, err := c.checkService.Get(ctx.Request.Context(), restaurantID, orderID, checkID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, check)
+}
+
+func (c *CheckController) Pay(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+	checkID, ok := c.parseParam(ctx, "check_id")
+	if !ok {
+		return
+	}
+
+	var input service.PayCheckInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	check, err := c.checkService.Pay(ctx.Request.Context(), restaurantID, orderID, checkID, userID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, check)
+}
+
+func (c *CheckController) GeneratePDF(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+	checkID, ok := c.parseParam(ctx, "check_id")
+	if !ok {
+		return
+	}
+
+	pdfBytes, err := c.pdfService.GenerateCheckTicket(ctx.Request.Context(), restaurantID, orderID, checkID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename=ticket-"+orderID.String()+"-"+checkID.String()[:8]+".pdf")
+	ctx.Header("Content-Type", "application/pdf")
+	ctx.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
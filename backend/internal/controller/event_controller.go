@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/events"
+	"github.com/pos-saas/restaurant-pos/internal/middleware"
+	"github.com/pos-saas/restaurant-pos/internal/permissions"
+)
+
+// heartbeatInterval mantiene viva la conexión a través de proxies que cierran
+// las conexiones inactivas
+const heartbeatInterval = 25 * time.Second
+
+// eventPermissions es el permiso que hace falta para recibir cada tipo de
+// evento, el mismo que para consultar el recurso en la API
+var eventPermissions = map[string]string{
+	events.SaleCreated:                permissions.SalesView,
+	events.SaleCancelled:              permissions.SalesView,
+	events.ProductUpdated:             permissions.MenuView,
+	events.ProductDeleted:             permissions.MenuView,
+	events.KitchenTicketCreated:       permissions.KitchenView,
+	events.KitchenTicketStatusChanged: permissions.KitchenView,
+	events.KitchenTicketUpdated:       permissions.KitchenView,
+}
+
+type EventController struct {
+	broker   events.Broker
+	sessions middleware.SessionValidator
+}
+
+func NewEventController(broker events.Broker, sessions middleware.SessionValidator) *EventController {
+	return &EventController{broker: broker, sessions: sessions}
+}
+
+func (c *EventController) getRestaurantID(ctx *gin.Context) (uuid.UUID, bool) {
+	rid, ok := ctx.Get("restaurant_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		return uuid.Nil, false
+	}
+	ridStr, ok := rid.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return uuid.Nil, false
+	}
+	parsed, err := uuid.Parse(ridStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id inválido"})
+		return uuid.Nil, false
+	}
+	return parsed, true
+}
+
+// Stream envía los eventos del restaurante como server-sent events. Cada
+// mensaje lleva el tipo en event y el evento completo en JSON en data; solo
+// se envían los tipos que el usuario puede consultar. La conexión se cierra
+// cuando expira el token o, al comprobarlo en cada heartbeat, se cerró la
+// sesión o se desactivó al usuario; el cliente debe reconectar con un token
+// nuevo.
+func (c *EventController) Stream(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+	perms := middleware.Permissions(ctx)
+	raw, _ := ctx.Get("claims")
+	claims, ok := raw.(*middleware.Claims)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return
+	}
+	expired := func() bool {
+		return claims.ExpiresAt != nil && !time.Now().Before(claims.ExpiresAt.Time)
+	}
+
+	ch, cancel := c.broker.Subscribe(restaurantID)
+	defer cancel()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	// Un comentario inicial envía las cabeceras sin esperar al primer evento
+	fmt.Fprint(ctx.Writer, ": conectado\n\n")
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				// Cliente lento: se cierra para que se reconecte y vuelva a consultar
+				return false
+			}
+			if expired() {
+				return false
+			}
+			if !perms.Has(eventPermissions[ev.Type]) {
+				return true
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				return true
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+			return true
+		case <-heartbeat.C:
+			if expired() {
+				return false
+			}
+			// Ante un error al consultar también se cierra: al reconectar se
+			// vuelve a validar el token
+			if err := c.sessions.ValidateSession(ctx.Request.Context(), claims.SessionID, claims.UserID); err != nil {
+				return false
+			}
+			fmt.Fprint(w, ": ping\n\n")
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
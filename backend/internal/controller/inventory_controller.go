@@ -0,0 +1,300 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/service"
+)
+
+type InventoryController struct {
+	inventoryService *service.InventoryService
+}
+
+func NewInventoryController(inventoryService *service.InventoryService) *InventoryController {
+	return &InventoryController{inventoryService: inventoryService}
+}
+
+func (c *InventoryController) getIDs(ctx *gin.Context) (restaurantID, userID uuid.UUID, ok bool) {
+	rid, ok1 := ctx.Get("restaurant_id")
+	uid, ok2 := ctx.Get("user_id")
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	ridStr, ok1 := rid.(string)
+	uidStr, ok2 := uid.(string)
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedRid, err := uuid.Parse(ridStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedUid, err := uuid.Parse(uidStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return parsedRid, parsedUid, true
+}
+
+// parseParam lee un UUID de la ruta
+func (c *InventoryController) parseParam(ctx *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(name))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (c *InventoryController) ListIngredients(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	ingredients, err := c.inventoryService.ListIngredients(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ingredients)
+}
+
+func (c *InventoryController) GetIngredient(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	ingredientID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	ingredient, err := c.inventoryService.GetIngredient(ctx.Request.Context(), restaurantID, ingredientID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ingredient)
+}
+
+func (c *InventoryController) CreateIngredient(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.IngredientInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	ingredient, err := c.inventoryService.CreateIngredient(ctx.Request.Context(), restaurantID, userID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, ingredient)
+}
+
+func (c *InventoryController) UpdateIngredient(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	ingredientID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.IngredientInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	ingredient, err := c.inventoryService.UpdateIngredient(ctx.Request.Context(), restaurantID, ingredientID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ingredient)
+}
+
+func (c *InventoryController) DeleteIngredient(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	ingredientID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.inventoryService.DeleteIngredient(ctx.Request.Context(), restaurantID, ingredientID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *InventoryController) Adjust(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	ingredientID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.StockAdjustmentInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	ingredient, err := c.inventoryService.Adjust(ctx.Request.Context(), restaurantID, ingredientID, userID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ingredient)
+}
+
+func (c *InventoryController) ListMovements(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	ingredientID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.ListMovementsInput
+	if err := ctx.ShouldBindQuery(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "parámetros inválidos: " + err.Error()})
+		return
+	}
+
+	movements, err := c.inventoryService.ListMovements(ctx.Request.Context(), restaurantID, ingredientID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, movements)
+}
+
+func (c *InventoryController) LowStock(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	ingredients, err := c.inventoryService.LowStock(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ingredients)
+}
+
+func (c *InventoryController) GetProductRecipe(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	productID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	recipe, err := c.inventoryService.GetProductRecipe(ctx.Request.Context(), restaurantID, productID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, recipe)
+}
+
+func (c *InventoryController) SetProductRecipe(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	productID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.SetRecipeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	recipe, err := c.inventoryService.SetProductRecipe(ctx.Request.Context(), restaurantID, productID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, recipe)
+}
+
+func (c *InventoryController) GetOptionRecipe(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	groupID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+	optionID, ok := c.parseParam(ctx, "option_id")
+	if !ok {
+		return
+	}
+
+	recipe, err := c.inventoryService.GetOptionRecipe(ctx.Request.Context(), restaurantID, groupID, optionID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, recipe)
+}
+
+func (c *InventoryController) SetOptionRecipe(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	groupID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+	optionID, ok := c.parseParam(ctx, "option_id")
+	if !ok {
+		return
+	}
+
+	var input service.SetRecipeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	recipe, err := c.inventoryService.SetOptionRecipe(ctx.Request.Context(), restaurantID, groupID, optionID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, recipe)
+}
@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/service"
+)
+
+type KitchenController struct {
+	kitchenService *service.KitchenService
+	receiptService *service.ReceiptService
+}
+
+func NewKitchenController(kitchenService *service.KitchenService, receiptService *service.ReceiptService) *KitchenController {
+	return &KitchenController{kitchenService: kitchenService, receiptService: receiptService}
+}
+
+func (c *KitchenController) getIDs(ctx *gin.Context) (restaurantID, userID uuid.UUID, ok bool) {
+	rid, ok1 := ctx.Get("restaurant_id")
+	uid, ok2 := ctx.Get("user_id")
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	ridStr, ok1 := rid.(string)
+	uidStr, ok2 := uid.(string)
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedRid, err := uuid.Parse(ridStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedUid, err := uuid.Parse(uidStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return parsedRid, parsedUid, true
+}
+
+// parseParam lee un UUID de la ruta
+func (c *KitchenController) parseParam(ctx *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(name))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (c *KitchenController) ListStations(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	stations, err := c.kitchenService.ListStations(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, stations)
+}
+
+func (c *KitchenController) CreateStation(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.KitchenStationInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	station, err := c.kitchenService.CreateStation(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, station)
+}
+
+func (c *KitchenController) UpdateStation(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	stationID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.KitchenStationInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	station, err := c.kitchenService.UpdateStation(ctx.Request.Context(), restaurantID, stationID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, station)
+}
+
+func (c *KitchenController) DeleteStation(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	stationID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.kitchenService.DeleteStation(ctx.Request.Context(), restaurantID, stationID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListTickets es la consulta periódica de la pantalla de cocina
+func (c *KitchenController) ListTickets(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.ListKitchenTicketsInput
+	if err := ctx.ShouldBindQuery(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "parámetros inválidos: " + err.Error()})
+		return
+	}
+
+	tickets, err := c.kitchenService.ListTickets(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, tickets)
+}
+
+func (c *KitchenController) GetTicket(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	ticketID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	ticket, err := c.kitchenService.GetTicket(ctx.Request.Context(), restaurantID, ticketID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ticket)
+}
+
+func (c *KitchenController) UpdateTicketStatus(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	ticketID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.KitchenTicketStatusInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	ticket, err := c.kitchenService.UpdateStatus(ctx.Request.Context(), restaurantID, ticketID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ticket)
+}
+
+// TicketESCPOS devuelve la comanda en comandos ESC/POS para la impresora de la estación
+func (c *KitchenController) TicketESCPOS(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	ticketID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var opts service.ReceiptOptions
+	if err := ctx.ShouldBindQuery(&opts); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "parámetros inválidos: " + err.Error()})
+		return
+	}
+
+	data, err := c.receiptService.KitchenTicketESCPOS(ctx.Request.Context(), restaurantID, ticketID, opts)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename=comanda-"+ticketID.String()+".escpos")
+	ctx.Data(http.StatusOK, "application/octet-stream", data)
+}
+
+func (c *KitchenController) TicketPDF(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	ticketID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var opts service.ReceiptOptions
+	if err := ctx.ShouldBindQuery(&opts); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "parámetros inválidos: " + err.Error()})
+		return
+	}
+
+	pdfBytes, err := c.receiptService.KitchenTicketPDF(ctx.Request.Context(), restaurantID, ticketID, opts)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename=comanda-"+ticketID.String()+".pdf")
+	ctx.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
@@ -0,0 +1,254 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/service"
+)
+
+type ModifierController struct {
+	modifierService *service.ModifierService
+}
+
+func NewModifierController(modifierService *service.ModifierService) *ModifierController {
+	return &ModifierController{modifierService: modifierService}
+}
+
+func (c *ModifierController) getRestaurantID(ctx *gin.Context) (uuid.UUID, bool) {
+	rid, ok := ctx.Get("restaurant_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		return uuid.Nil, false
+	}
+	ridStr, ok := rid.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return uuid.Nil, false
+	}
+	parsed, err := uuid.Parse(ridStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id inválido"})
+		return uuid.Nil, false
+	}
+	return parsed, true
+}
+
+// parseParam lee un UUID de la ruta
+func (c *ModifierController) parseParam(ctx *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(name))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (c *ModifierController) ListGroups(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	groups, err := c.modifierService.ListGroups(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, groups)
+}
+
+func (c *ModifierController) GetGroup(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+	groupID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	group, err := c.modifierService.GetGroup(ctx.Request.Context(), restaurantID, groupID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, group)
+}
+
+func (c *ModifierController) CreateGroup(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.ModifierGroupInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	group, err := c.modifierService.CreateGroup(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, group)
+}
+
+func (c *ModifierController) UpdateGroup(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+	groupID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.ModifierGroupInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	group, err := c.modifierService.UpdateGroup(ctx.Request.Context(), restaurantID, groupID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, group)
+}
+
+func (c *ModifierController) DeleteGroup(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+	groupID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.modifierService.DeleteGroup(ctx.Request.Context(), restaurantID, groupID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *ModifierController) CreateOption(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+	groupID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.ModifierOptionInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	option, err := c.modifierService.CreateOption(ctx.Request.Context(), restaurantID, groupID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, option)
+}
+
+func (c *ModifierController) UpdateOption(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+	groupID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+	optionID, ok := c.parseParam(ctx, "option_id")
+	if !ok {
+		return
+	}
+
+	var input service.ModifierOptionInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	option, err := c.modifierService.UpdateOption(ctx.Request.Context(), restaurantID, groupID, optionID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, option)
+}
+
+func (c *ModifierController) DeleteOption(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+	groupID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+	optionID, ok := c.parseParam(ctx, "option_id")
+	if !ok {
+		return
+	}
+
+	if err := c.modifierService.DeleteOption(ctx.Request.Context(), restaurantID, groupID, optionID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *ModifierController) ListProductGroups(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+	productID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	groups, err := c.modifierService.ListProductGroups(ctx.Request.Context(), restaurantID, productID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, groups)
+}
+
+func (c *ModifierController) SetProductGroups(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+	productID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.SetProductModifiersInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	groups, err := c.modifierService.SetProductGroups(ctx.Request.Context(), restaurantID, productID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, groups)
+}
@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/middleware"
+	"github.com/pos-saas/restaurant-pos/internal/service"
+)
+
+type OrderController struct {
+	orderService *service.OrderService
+}
+
+func NewOrderController(orderService *service.OrderService) *OrderController {
+	return &OrderController{orderService: orderService}
+}
+
+func (c *OrderController) getIDs(ctx *gin.Context) (restaurantID, userID uuid.UUID, ok bool) {
+	rid, ok1 := ctx.Get("restaurant_id")
+	uid, ok2 := ctx.Get("user_id")
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	ridStr, ok1 := rid.(string)
+	uidStr, ok2 := uid.(string)
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedRid, err := uuid.Parse(ridStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedUid, err := uuid.Parse(uidStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return parsedRid, parsedUid, true
+}
+
+// parseParam lee un UUID de la ruta
+func (c *OrderController) parseParam(ctx *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(name))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (c *OrderController) List(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.ListOrdersInput
+	if err := ctx.ShouldBindQuery(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "parámetros inválidos: " + err.Error()})
+		return
+	}
+
+	orders, err := c.orderService.List(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, orders)
+}
+
+func (c *OrderController) Open(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.OpenOrderInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	order, err := c.orderService.Open(ctx.Request.Context(), restaurantID, userID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, order)
+}
+
+func (c *OrderController) GetByID(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	order, err := c.orderService.Get(ctx.Request.Context(), restaurantID, orderID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (c *OrderController) AddItems(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.AddOrderItemsInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	order, err := c.orderService.AddItems(ctx.Request.Context(), restaurantID, orderID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (c *OrderController) RemoveItem(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := c.parseParam(ctx, "item_id")
+	if !ok {
+		return
+	}
+
+	order, err := c.orderService.RemoveItem(ctx.Request.Context(), restaurantID, orderID, itemID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (c *OrderController) Transfer(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.TransferOrderInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	order, err := c.orderService.Transfer(ctx.Request.Context(), restaurantID, orderID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+// Split responde con la cuenta nueva
+func (c *OrderController) Split(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.SplitOrderInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	order, err := c.orderService.Split(ctx.Request.Context(), restaurantID, orderID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, order)
+}
+
+func (c *OrderController) Merge(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.MergeOrderInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	order, err := c.orderService.Merge(ctx.Request.Context(), restaurantID, orderID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (c *OrderController) Close(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.CloseOrderInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	sale, err := c.orderService.Close(ctx.Request.Context(), restaurantID, orderID, userID, middleware.Permissions(ctx), input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, sale)
+}
+
+func (c *OrderController) Void(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.CancelSaleInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	sale, err := c.orderService.Void(ctx.Request.Context(), restaurantID, orderID, userID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, sale)
+}
@@ -145,3 +145,150 @@ func (c *ProductController) Delete(ctx *gin.Context) {
 	}
 	ctx.Status(http.StatusNoContent)
 }
+
+func (c *ProductController) ListVariants(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	productID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	variants, err := c.productService.ListVariants(ctx.Request.Context(), restaurantID, productID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, variants)
+}
+
+func (c *ProductController) CreateVariant(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	productID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var input service.ProductVariantInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	variant, err := c.productService.CreateVariant(ctx.Request.Context(), restaurantID, productID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, variant)
+}
+
+func (c *ProductController) UpdateVariant(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	productID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	variantID, err := uuid.Parse(ctx.Param("variant_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de variante inválido"})
+		return
+	}
+
+	var input service.ProductVariantInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	variant, err := c.productService.UpdateVariant(ctx.Request.Context(), restaurantID, productID, variantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, variant)
+}
+
+func (c *ProductController) DeleteVariant(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	productID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	variantID, err := uuid.Parse(ctx.Param("variant_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de variante inválido"})
+		return
+	}
+
+	if err := c.productService.DeleteVariant(ctx.Request.Context(), restaurantID, productID, variantID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *ProductController) ListComboSlots(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	productID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	slots, err := c.productService.ListComboSlots(ctx.Request.Context(), restaurantID, productID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, slots)
+}
+
+func (c *ProductController) SetComboSlots(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	productID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var input service.SetComboSlotsInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	slots, err := c.productService.SetComboSlots(ctx.Request.Context(), restaurantID, productID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, slots)
+}
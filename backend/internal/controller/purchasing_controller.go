@@ -0,0 +1,281 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/service"
+)
+
+type PurchasingController struct {
+	purchasingService *service.PurchasingService
+}
+
+func NewPurchasingController(purchasingService *service.PurchasingService) *PurchasingController {
+	return &PurchasingController{purchasingService: purchasingService}
+}
+
+func (c *PurchasingController) getIDs(ctx *gin.Context) (restaurantID, userID uuid.UUID, ok bool) {
+	rid, ok1 := ctx.Get("restaurant_id")
+	uid, ok2 := ctx.Get("user_id")
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	ridStr, ok1 := rid.(string)
+	uidStr, ok2 := uid.(string)
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedRid, err := uuid.Parse(ridStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedUid, err := uuid.Parse(uidStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return parsedRid, parsedUid, true
+}
+
+// parseParam lee un UUID de la ruta
+func (c *PurchasingController) parseParam(ctx *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(name))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (c *PurchasingController) ListSuppliers(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	activeOnly := ctx.Query("active") != "false"
+	suppliers, err := c.purchasingService.ListSuppliers(ctx.Request.Context(), restaurantID, activeOnly)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, suppliers)
+}
+
+func (c *PurchasingController) GetSupplier(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	supplierID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	supplier, err := c.purchasingService.GetSupplier(ctx.Request.Context(), restaurantID, supplierID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, supplier)
+}
+
+func (c *PurchasingController) CreateSupplier(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.SupplierInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	supplier, err := c.purchasingService.CreateSupplier(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, supplier)
+}
+
+func (c *PurchasingController) UpdateSupplier(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	supplierID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.SupplierInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	supplier, err := c.purchasingService.UpdateSupplier(ctx.Request.Context(), restaurantID, supplierID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, supplier)
+}
+
+func (c *PurchasingController) DeleteSupplier(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	supplierID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.purchasingService.DeleteSupplier(ctx.Request.Context(), restaurantID, supplierID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *PurchasingController) ListOrders(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.ListPurchaseOrdersInput
+	if err := ctx.ShouldBindQuery(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "parámetros inválidos: " + err.Error()})
+		return
+	}
+
+	orders, err := c.purchasingService.ListOrders(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, orders)
+}
+
+func (c *PurchasingController) GetOrder(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	order, err := c.purchasingService.GetOrder(ctx.Request.Context(), restaurantID, orderID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (c *PurchasingController) CreateOrder(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.CreatePurchaseOrderInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	order, err := c.purchasingService.CreateOrder(ctx.Request.Context(), restaurantID, userID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, order)
+}
+
+func (c *PurchasingController) Receive(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.ReceiveInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	order, err := c.purchasingService.Receive(ctx.Request.Context(), restaurantID, orderID, userID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (c *PurchasingController) CancelOrder(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	order, err := c.purchasingService.CancelOrder(ctx.Request.Context(), restaurantID, orderID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (c *PurchasingController) Ledger(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.PurchaseLedgerInput
+	if err := ctx.ShouldBindQuery(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "parámetros inválidos: " + err.Error()})
+		return
+	}
+
+	entries, err := c.purchasingService.Ledger(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, entries)
+}
+
+func (c *PurchasingController) FoodCost(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	costs, err := c.purchasingService.FoodCost(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, costs)
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/service"
+)
+
+type ReportController struct {
+	reportService *service.ReportService
+}
+
+func NewReportController(reportService *service.ReportService) *ReportController {
+	return &ReportController{reportService: reportService}
+}
+
+func (c *ReportController) getRestaurantID(ctx *gin.Context) (uuid.UUID, bool) {
+	rid, ok := ctx.Get("restaurant_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		return uuid.Nil, false
+	}
+	ridStr, ok := rid.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return uuid.Nil, false
+	}
+	parsed, err := uuid.Parse(ridStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id inválido"})
+		return uuid.Nil, false
+	}
+	return parsed, true
+}
+
+// bindRange obtiene el restaurante y el rango de fechas de la query
+func (c *ReportController) bindRange(ctx *gin.Context) (uuid.UUID, service.ReportRangeInput, bool) {
+	var input service.ReportRangeInput
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return uuid.Nil, input, false
+	}
+	if err := ctx.ShouldBindQuery(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "parámetros inválidos: " + err.Error()})
+		return uuid.Nil, input, false
+	}
+	return restaurantID, input, true
+}
+
+func (c *ReportController) Summary(ctx *gin.Context) {
+	restaurantID, input, ok := c.bindRange(ctx)
+	if !ok {
+		return
+	}
+	result, err := c.reportService.Summary(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c *ReportController) ByDay(ctx *gin.Context) {
+	restaurantID, input, ok := c.bindRange(ctx)
+	if !ok {
+		return
+	}
+	result, err := c.reportService.RevenueByDay(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c *ReportController) ByHour(ctx *gin.Context) {
+	restaurantID, input, ok := c.bindRange(ctx)
+	if !ok {
+		return
+	}
+	result, err := c.reportService.RevenueByHour(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c *ReportController) TopProducts(ctx *gin.Context) {
+	restaurantID, input, ok := c.bindRange(ctx)
+	if !ok {
+		return
+	}
+	result, err := c.reportService.TopProducts(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c *ReportController) ByCategory(ctx *gin.Context) {
+	restaurantID, input, ok := c.bindRange(ctx)
+	if !ok {
+		return
+	}
+	result, err := c.reportService.RevenueByCategory(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c *ReportController) ByCashier(ctx *gin.Context) {
+	restaurantID, input, ok := c.bindRange(ctx)
+	if !ok {
+		return
+	}
+	result, err := c.reportService.SalesByCashier(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c *ReportController) TipsByCashier(ctx *gin.Context) {
+	restaurantID, input, ok := c.bindRange(ctx)
+	if !ok {
+		return
+	}
+	result, err := c.reportService.TipsByCashier(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/service"
+)
+
+type RestaurantController struct {
+	restaurantService *service.RestaurantService
+}
+
+func NewRestaurantController(restaurantService *service.RestaurantService) *RestaurantController {
+	return &RestaurantController{restaurantService: restaurantService}
+}
+
+func (c *RestaurantController) getRestaurantID(ctx *gin.Context) (uuid.UUID, bool) {
+	rid, ok := ctx.Get("restaurant_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		return uuid.Nil, false
+	}
+	ridStr, ok := rid.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return uuid.Nil, false
+	}
+	parsed, err := uuid.Parse(ridStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id inválido"})
+		return uuid.Nil, false
+	}
+	return parsed, true
+}
+
+func (c *RestaurantController) Get(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	restaurant, err := c.restaurantService.Get(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, restaurant)
+}
+
+func (c *RestaurantController) Update(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.UpdateRestaurantInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	restaurant, err := c.restaurantService.Update(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, restaurant)
+}
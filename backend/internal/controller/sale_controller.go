@@ -5,16 +5,18 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/middleware"
 	"github.com/pos-saas/restaurant-pos/internal/service"
 )
 
 type SaleController struct {
-	saleService *service.SaleService
-	pdfService  *service.PDFService
+	saleService    *service.SaleService
+	pdfService     *service.PDFService
+	receiptService *service.ReceiptService
 }
 
-func NewSaleController(saleService *service.SaleService, pdfService *service.PDFService) *SaleController {
-	return &SaleController{saleService: saleService, pdfService: pdfService}
+func NewSaleController(saleService *service.SaleService, pdfService *service.PDFService, receiptService *service.ReceiptService) *SaleController {
+	return &SaleController{saleService: saleService, pdfService: pdfService, receiptService: receiptService}
 }
 
 func (c *SaleController) getIDs(ctx *gin.Context) (restaurantID, userID uuid.UUID, ok bool) {
@@ -55,7 +57,7 @@ func (c *SaleController) Create(ctx *gin.Context) {
 		return
 	}
 
-	sale, err := c.saleService.Create(ctx.Request.Context(), restaurantID, userID, input)
+	sale, err := c.saleService.Create(ctx.Request.Context(), restaurantID, userID, middleware.Permissions(ctx), input)
 	if err != nil {
 		handleError(ctx, err)
 		return
@@ -81,14 +83,67 @@ func (c *SaleController) GetByID(ctx *gin.Context) {
 		return
 	}
 
+	taxes, err := c.saleService.GetTaxes(ctx.Request.Context(), sale.ID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
 		"sale":       sale,
 		"items":      items,
+		"taxes":      taxes,
 		"payments":   payments,
 		"restaurant": restaurant,
 	})
 }
 
+func (c *SaleController) List(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.ListSalesInput
+	if err := ctx.ShouldBindQuery(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "parámetros inválidos: " + err.Error()})
+		return
+	}
+
+	result, err := c.saleService.List(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c *SaleController) Cancel(ctx *gin.Context) {
+	restaurantID, userID, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	saleID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var input service.CancelSaleInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	sale, err := c.saleService.Cancel(ctx.Request.Context(), restaurantID, saleID, userID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, sale)
+}
+
 func (c *SaleController) GeneratePDF(ctx *gin.Context) {
 	restaurantID, _, ok := c.getIDs(ctx)
 	if !ok {
@@ -111,3 +166,62 @@ func (c *SaleController) GeneratePDF(ctx *gin.Context) {
 	ctx.Header("Content-Type", "application/pdf")
 	ctx.Data(http.StatusOK, "application/pdf", pdfBytes)
 }
+
+// ReceiptESCPOS devuelve el ticket en comandos ESC/POS para enviarlo tal cual
+// a la impresora térmica
+func (c *SaleController) ReceiptESCPOS(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	saleID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var opts service.ReceiptOptions
+	if err := ctx.ShouldBindQuery(&opts); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "parámetros inválidos: " + err.Error()})
+		return
+	}
+
+	data, err := c.receiptService.SaleESCPOS(ctx.Request.Context(), restaurantID, saleID, middleware.Permissions(ctx), opts)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename=ticket-"+saleID.String()+".escpos")
+	ctx.Data(http.StatusOK, "application/octet-stream", data)
+}
+
+// ReceiptPDF devuelve el ticket como PDF del ancho del papel térmico
+func (c *SaleController) ReceiptPDF(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	saleID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var opts service.ReceiptOptions
+	if err := ctx.ShouldBindQuery(&opts); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "parámetros inválidos: " + err.Error()})
+		return
+	}
+
+	pdfBytes, err := c.receiptService.SalePDF(ctx.Request.Context(), restaurantID, saleID, opts)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename=ticket-"+saleID.String()+".pdf")
+	ctx.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
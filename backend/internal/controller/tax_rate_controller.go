@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/service"
+)
+
+type TaxRateController struct {
+	taxRateService *service.TaxRateService
+}
+
+func NewTaxRateController(taxRateService *service.TaxRateService) *TaxRateController {
+	return &TaxRateController{taxRateService: taxRateService}
+}
+
+func (c *TaxRateController) getRestaurantID(ctx *gin.Context) (uuid.UUID, bool) {
+	rid, ok := ctx.Get("restaurant_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		return uuid.Nil, false
+	}
+	ridStr, ok := rid.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return uuid.Nil, false
+	}
+	parsed, err := uuid.Parse(ridStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id inválido"})
+		return uuid.Nil, false
+	}
+	return parsed, true
+}
+
+func (c *TaxRateController) List(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	rates, err := c.taxRateService.List(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, rates)
+}
+
+func (c *TaxRateController) Create(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.TaxRateInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	rate, err := c.taxRateService.Create(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, rate)
+}
+
+func (c *TaxRateController) Update(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	taxRateID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var input service.TaxRateInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	rate, err := c.taxRateService.Update(ctx.Request.Context(), restaurantID, taxRateID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, rate)
+}
+
+func (c *TaxRateController) Delete(ctx *gin.Context) {
+	restaurantID, ok := c.getRestaurantID(ctx)
+	if !ok {
+		return
+	}
+
+	taxRateID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if err := c.taxRateService.Delete(ctx.Request.Context(), restaurantID, taxRateID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
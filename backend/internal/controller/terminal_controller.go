@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/service"
+)
+
+type TerminalController struct {
+	terminalService *service.TerminalService
+	authService     *service.AuthService
+}
+
+func NewTerminalController(terminalService *service.TerminalService, authService *service.AuthService) *TerminalController {
+	return &TerminalController{terminalService: terminalService, authService: authService}
+}
+
+func (c *TerminalController) getIDs(ctx *gin.Context) (restaurantID, userID uuid.UUID, ok bool) {
+	rid, ok1 := ctx.Get("restaurant_id")
+	uid, ok2 := ctx.Get("user_id")
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	ridStr, ok1 := rid.(string)
+	uidStr, ok2 := uid.(string)
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error interno"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedRid, err := uuid.Parse(ridStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	parsedUid, err := uuid.Parse(uidStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user_id inválido"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return parsedRid, parsedUid, true
+}
+
+// parseParam lee un UUID de la ruta
+func (c *TerminalController) parseParam(ctx *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(name))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// terminalTokenHeader lleva el token que identifica a una terminal compartida
+const terminalTokenHeader = "X-Terminal-Token"
+
+func (c *TerminalController) List(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	terminals, err := c.terminalService.List(ctx.Request.Context(), restaurantID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, terminals)
+}
+
+func (c *TerminalController) Get(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	terminalID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	terminal, err := c.terminalService.Get(ctx.Request.Context(), restaurantID, terminalID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, terminal)
+}
+
+func (c *TerminalController) Create(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.TerminalInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	created, err := c.terminalService.Create(ctx.Request.Context(), restaurantID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, created)
+}
+
+func (c *TerminalController) Update(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	terminalID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var input service.TerminalInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	terminal, err := c.terminalService.Update(ctx.Request.Context(), restaurantID, terminalID, input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, terminal)
+}
+
+func (c *TerminalController) Delete(ctx *gin.Context) {
+	restaurantID, _, ok := c.getIDs(ctx)
+	if !ok {
+		return
+	}
+	terminalID, ok := c.parseParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.terminalService.Delete(ctx.Request.Context(), restaurantID, terminalID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// Users godoc
+// @Summary      Usuarios de la terminal
+// @Description  Usuarios activos con PIN que se pueden elegir en la terminal compartida
+// @Tags         terminal
+// @Produce      json
+// @Param        X-Terminal-Token  header  string  true  "Token de la terminal"
+// @Success      200  {array}   service.TerminalUser
+// @Failure      401  {object}  map[string]string
+// @Router       /terminal/users [get]
+func (c *TerminalController) Users(ctx *gin.Context) {
+	users, err := c.terminalService.Users(ctx.Request.Context(), ctx.GetHeader(terminalTokenHeader))
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, users)
+}
+
+// SwitchUser godoc
+// @Summary      Cambiar de usuario con PIN
+// @Description  Abre una sesión del usuario elegido en la terminal compartida y cierra la del anterior; tras 5 PIN incorrectos el usuario queda bloqueado 15 minutos
+// @Tags         terminal
+// @Accept       json
+// @Produce      json
+// @Param        X-Terminal-Token  header  string                  true  "Token de la terminal"
+// @Param        body              body    service.PINSwitchInput  true  "Usuario y PIN"
+// @Success      200  {object}  service.AuthResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Router       /terminal/switch [post]
+func (c *TerminalController) SwitchUser(ctx *gin.Context) {
+	var input service.PINSwitchInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "datos inválidos: " + err.Error()})
+		return
+	}
+
+	resp, err := c.authService.SwitchUser(ctx.Request.Context(), ctx.GetHeader(terminalTokenHeader), input)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
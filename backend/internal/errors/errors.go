@@ -13,6 +13,7 @@ var (
 	ErrConflict         = errors.New("el recurso ya existe")
 	ErrInternal         = errors.New("error interno del servidor")
 	ErrInvalidCredentials = errors.New("credenciales inválidas")
+	ErrTooManyRequests  = errors.New("demasiados intentos")
 )
 
 // AppError representa un error de aplicación con código HTTP
@@ -63,5 +64,8 @@ func HTTPStatus(err error) int {
 	if errors.Is(err, ErrConflict) {
 		return http.StatusConflict
 	}
+	if errors.Is(err, ErrTooManyRequests) {
+		return http.StatusTooManyRequests
+	}
 	return http.StatusInternalServerError
 }
@@ -0,0 +1,214 @@
+// Package escpos arma tickets para impresoras térmicas con comandos ESC/POS.
+//
+// El ancho se mide en columnas de la fuente A: 32 en papel de 58 mm y 48 en
+// papel de 80 mm. El texto se corta por palabras al ancho disponible, que se
+// reduce a la mitad con letra de doble ancho. Los caracteres se envían en la
+// página de códigos PC850, que cubre los acentos del español.
+package escpos
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// Columnas por línea según el ancho del papel
+const (
+	Width58 = 32
+	Width80 = 48
+)
+
+type Align byte
+
+const (
+	AlignLeft   Align = 0
+	AlignCenter Align = 1
+	AlignRight  Align = 2
+)
+
+const (
+	esc = 0x1b
+	gs  = 0x1d
+)
+
+// Printer acumula los comandos de un ticket
+type Printer struct {
+	buf         bytes.Buffer
+	width       int
+	doubleWidth bool
+}
+
+// New inicia un ticket de width columnas
+func New(width int) *Printer {
+	p := &Printer{width: width}
+	p.buf.Write([]byte{esc, '@'})    // reinicia la impresora
+	p.buf.Write([]byte{esc, 't', 2}) // página de códigos PC850
+	return p
+}
+
+// Width devuelve las columnas disponibles con el tamaño de letra actual
+func (p *Printer) Width() int {
+	if p.doubleWidth {
+		return p.width / 2
+	}
+	return p.width
+}
+
+func (p *Printer) Align(a Align) {
+	p.buf.Write([]byte{esc, 'a', byte(a)})
+}
+
+func (p *Printer) Bold(on bool) {
+	p.buf.Write([]byte{esc, 'E', boolByte(on)})
+}
+
+// Size cambia a letra de doble ancho y/o doble alto
+func (p *Printer) Size(doubleWidth, doubleHeight bool) {
+	var n byte
+	if doubleWidth {
+		n |= 0x10
+	}
+	if doubleHeight {
+		n |= 0x01
+	}
+	p.doubleWidth = doubleWidth
+	p.buf.Write([]byte{gs, '!', n})
+}
+
+// Line imprime el texto cortado al ancho disponible
+func (p *Printer) Line(text string) {
+	for _, l := range Wrap(text, p.Width()) {
+		p.write(l)
+	}
+}
+
+// Columns imprime left a la izquierda y right alineado a la derecha
+func (p *Printer) Columns(left, right string) {
+	for _, l := range Columns(left, right, p.Width()) {
+		p.write(l)
+	}
+}
+
+// Separator imprime una línea de guiones a todo el ancho
+func (p *Printer) Separator() {
+	p.write(strings.Repeat("-", p.Width()))
+}
+
+// Feed avanza n líneas en blanco
+func (p *Printer) Feed(n int) {
+	if n > 0 {
+		p.buf.Write([]byte{esc, 'd', byte(n)})
+	}
+}
+
+// Cut avanza el papel hasta la cuchilla y hace un corte parcial
+func (p *Printer) Cut() {
+	p.buf.Write([]byte{gs, 'V', 'B', 3})
+}
+
+// OpenDrawer envía el pulso de apertura al cajón conectado a la impresora
+func (p *Printer) OpenDrawer() {
+	p.buf.Write([]byte{esc, 'p', 0, 25, 250})
+}
+
+func (p *Printer) Bytes() []byte {
+	return p.buf.Bytes()
+}
+
+func (p *Printer) write(line string) {
+	for _, r := range line {
+		p.buf.WriteByte(encode(r))
+	}
+	p.buf.WriteByte('\n')
+}
+
+// Wrap corta el texto por palabras en líneas de hasta width columnas; una
+// palabra más larga que el ancho se parte. La sangría inicial se repite en
+// las líneas de continuación.
+func Wrap(text string, width int) []string {
+	if width <= 0 {
+		return []string{text}
+	}
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		body := strings.TrimLeft(para, " ")
+		indent := para[:len(para)-len(body)]
+		if len(indent) >= width {
+			indent = ""
+		}
+		avail := width - len(indent)
+
+		line := ""
+		for _, word := range strings.Fields(body) {
+			for utf8.RuneCountInString(word) > avail {
+				if line != "" {
+					lines = append(lines, indent+line)
+					line = ""
+				}
+				r := []rune(word)
+				lines = append(lines, indent+string(r[:avail]))
+				word = string(r[avail:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= avail:
+				line += " " + word
+			default:
+				lines = append(lines, indent+line)
+				line = word
+			}
+		}
+		lines = append(lines, indent+line)
+	}
+	return lines
+}
+
+// Columns arma líneas de width columnas con left a la izquierda y right al
+// final de la última; si no caben juntas, left ocupa sus propias líneas
+func Columns(left, right string, width int) []string {
+	rw := utf8.RuneCountInString(right)
+	lines := Wrap(left, width)
+	last := lines[len(lines)-1]
+	if gap := width - utf8.RuneCountInString(last) - rw; gap >= 1 {
+		lines[len(lines)-1] = last + strings.Repeat(" ", gap) + right
+		return lines
+	}
+	if rw >= width {
+		return append(lines, right)
+	}
+	return append(lines, strings.Repeat(" ", width-rw)+right)
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// pc850 son los caracteres fuera de ASCII que suelen aparecer en un ticket
+var pc850 = map[rune]byte{
+	'á': 0xa0, 'é': 0x82, 'í': 0xa1, 'ó': 0xa2, 'ú': 0xa3,
+	'Á': 0xb5, 'É': 0x90, 'Í': 0xd6, 'Ó': 0xe0, 'Ú': 0xe9,
+	'ñ': 0xa4, 'Ñ': 0xa5, 'ü': 0x81, 'Ü': 0x9a,
+	'¿': 0xa8, '¡': 0xad, 'º': 0xa7, 'ª': 0xa6,
+	'à': 0x85, 'è': 0x8a, 'ò': 0x95, 'ç': 0x87, 'Ç': 0x80,
+}
+
+// encode convierte una runa a PC850; lo que no existe se imprime como '?'.
+// Los caracteres de control se vuelven espacios: el texto viene de nombres y
+// notas que escribe el usuario y no debe poder enviar comandos a la impresora
+// (p. ej. ESC p abre el cajón).
+func encode(r rune) byte {
+	if r < 0x20 || r == 0x7f {
+		return ' '
+	}
+	if r < 0x80 {
+		return byte(r)
+	}
+	if b, ok := pc850[r]; ok {
+		return b
+	}
+	return '?'
+}
@@ -0,0 +1,88 @@
+package escpos
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"Café con leche", 8, []string{"Café con", "leche"}},
+		{"abcdefghij xy", 4, []string{"abcd", "efgh", "ij", "xy"}},
+		{"ab cdefghi", 4, []string{"ab", "cdef", "ghi"}},
+		{"ñññññ", 3, []string{"ñññ", "ññ"}},
+		{"  - uno dos tres", 10, []string{"  - uno", "  dos tres"}},
+		{"  abcdefghij", 6, []string{"  abcd", "  efgh", "  ij"}},
+		{"      ab", 4, []string{"ab"}},
+		{"uno   dos", 20, []string{"uno dos"}},
+		{"a\nb", 10, []string{"a", "b"}},
+		{"", 10, []string{""}},
+		{"sin ancho", 0, []string{"sin ancho"}},
+	}
+	for _, tt := range tests {
+		got := Wrap(tt.text, tt.width)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Wrap(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+// Ninguna línea pasa del ancho, aunque el texto lleve tildes
+func TestWrapWidth(t *testing.T) {
+	text := "  Ración de jamón ibérico con pan tostado y tomate, extraaaaaaaaaaaaaaaaaa"
+	for width := 1; width <= 40; width++ {
+		for _, l := range Wrap(text, width) {
+			if n := utf8.RuneCountInString(l); n > width {
+				t.Errorf("Wrap(_, %d): %q tiene %d columnas", width, l, n)
+			}
+		}
+	}
+}
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		left, right string
+		width       int
+		want        []string
+	}{
+		{"Total", "10.00", 20, []string{"Total          10.00"}},
+		{"Añejo", "1.00", 10, []string{"Añejo 1.00"}},
+		{"abcd", "xy", 6, []string{"abcd", "    xy"}},
+		{"Hamburguesa", "12.50", 14, []string{"Hamburguesa", "         12.50"}},
+		{"Tarta de queso casera", "4.50", 16, []string{"Tarta de queso", "casera      4.50"}},
+		{"a", "123456", 5, []string{"a", "123456"}},
+	}
+	for _, tt := range tests {
+		got := Columns(tt.left, tt.right, tt.width)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Columns(%q, %q, %d) = %q, want %q", tt.left, tt.right, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want byte
+	}{
+		{'A', 'A'},
+		{'~', '~'},
+		{'á', 0xa0},
+		{'Ñ', 0xa5},
+		{'€', '?'},
+		{0x1b, ' '},
+		{'\t', ' '},
+		{0x00, ' '},
+		{0x7f, ' '},
+	}
+	for _, tt := range tests {
+		if got := encode(tt.r); got != tt.want {
+			t.Errorf("encode(%q) = %#x, want %#x", tt.r, got, tt.want)
+		}
+	}
+}
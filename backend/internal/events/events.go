@@ -0,0 +1,118 @@
+// Package events reparte en tiempo real los cambios de cada restaurante a las
+// pantallas conectadas (caja, cocina).
+//
+// Los servicios publican después de confirmar la transacción; un evento es un
+// aviso, no la fuente de verdad: un cliente que se reconecta vuelve a consultar
+// la API. MemoryBroker funciona dentro de un solo proceso; con varias réplicas
+// se sustituye por otra implementación de Broker (p. ej. LISTEN/NOTIFY de
+// Postgres) sin tocar a quien publica ni al endpoint.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de evento
+const (
+	SaleCreated                = "sale.created"
+	SaleCancelled              = "sale.cancelled"
+	ProductUpdated             = "product.updated"
+	ProductDeleted             = "product.deleted" // data solo lleva el id
+	KitchenTicketCreated       = "kitchen_ticket.created"
+	KitchenTicketStatusChanged = "kitchen_ticket.status_changed" // también al anularse
+	KitchenTicketUpdated       = "kitchen_ticket.updated"        // se quitaron líneas
+)
+
+// Event es un cambio de un restaurante. Data es el recurso tal como lo
+// devuelve la API; ID lo asigna el broker y crece con cada evento.
+type Event struct {
+	ID           uint64      `json:"id"`
+	Type         string      `json:"type"`
+	RestaurantID uuid.UUID   `json:"restaurant_id"`
+	Data         interface{} `json:"data"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// New arma un evento para publicar
+func New(eventType string, restaurantID uuid.UUID, data interface{}) Event {
+	return Event{Type: eventType, RestaurantID: restaurantID, Data: data, CreatedAt: time.Now()}
+}
+
+// Publisher es lo que necesitan los servicios
+type Publisher interface {
+	Publish(ev Event)
+}
+
+// Broker reparte los eventos entre los suscriptores de cada restaurante.
+// Subscribe devuelve el canal y la función que cancela la suscripción; el
+// canal se cierra al cancelar o si el suscriptor no consume a tiempo.
+type Broker interface {
+	Publisher
+	Subscribe(restaurantID uuid.UUID) (<-chan Event, func())
+}
+
+// subscriberBuffer son los eventos que puede acumular una conexión lenta
+// antes de que se la desconecte
+const subscriberBuffer = 64
+
+// MemoryBroker es un Broker en memoria para una sola instancia del servidor
+type MemoryBroker struct {
+	mu   sync.Mutex
+	seq  uint64
+	subs map[uuid.UUID]map[chan Event]struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subs: make(map[uuid.UUID]map[chan Event]struct{})}
+}
+
+// Publish no bloquea: el suscriptor con el búfer lleno se desconecta para que
+// vuelva a conectarse y consulte lo que se perdió
+func (b *MemoryBroker) Publish(ev Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	ev.ID = b.seq
+	for ch := range b.subs[ev.RestaurantID] {
+		select {
+		case ch <- ev:
+		default:
+			b.remove(ev.RestaurantID, ch)
+		}
+	}
+}
+
+func (b *MemoryBroker) Subscribe(restaurantID uuid.UUID) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[restaurantID] == nil {
+		b.subs[restaurantID] = make(map[chan Event]struct{})
+	}
+	b.subs[restaurantID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(restaurantID, ch)
+	}
+	return ch, cancel
+}
+
+// remove cierra el canal una sola vez. Llamar con mu tomado.
+func (b *MemoryBroker) remove(restaurantID uuid.UUID, ch chan Event) {
+	subs := b.subs[restaurantID]
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+	delete(subs, ch)
+	close(ch)
+	if len(subs) == 0 {
+		delete(b.subs, restaurantID)
+	}
+}
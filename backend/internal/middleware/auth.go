@@ -1,24 +1,36 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/permissions"
 )
 
 type Claims struct {
-	UserID       string `json:"user_id"`
-	RestaurantID string `json:"restaurant_id"`
-	Email        string `json:"email"`
-	Role         string `json:"role"`
+	UserID       string   `json:"user_id"`
+	RestaurantID string   `json:"restaurant_id"`
+	Email        string   `json:"email"`
+	Role         string   `json:"role"`
+	Permissions  []string `json:"permissions"`
+	SessionID    string   `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-// AuthRequired valida el JWT y extrae el contexto del usuario
-func AuthRequired(secret string) gin.HandlerFunc {
+// SessionValidator confirma que la sesión del token siga abierta y su usuario
+// activo; devuelve errors.ErrUnauthorized si no
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, sessionID, userID string) error
+}
+
+// AuthRequired valida el JWT y extrae el contexto del usuario. Además de la
+// firma y la expiración, consulta la sesión: un logout, un refresh token
+// reutilizado o desactivar al usuario invalidan el token de inmediato.
+func AuthRequired(secret string, sessions SessionValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -51,32 +63,58 @@ func AuthRequired(secret string) gin.HandlerFunc {
 			return
 		}
 
+		if err := sessions.ValidateSession(c.Request.Context(), claims.SessionID, claims.UserID); err != nil {
+			if errors.Is(err, errors.ErrUnauthorized) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "sesión cerrada o usuario inactivo"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "error interno del servidor"})
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
 		c.Set("restaurant_id", claims.RestaurantID)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		c.Set("session_id", claims.SessionID)
+		c.Set("permissions", permissions.NewSet(claims.Permissions...))
 		c.Set("claims", claims)
 		c.Next()
 	}
 }
 
-// RequireRole restringe el acceso por rol
-func RequireRole(roles ...string) gin.HandlerFunc {
+// RequirePermission restringe el acceso a quien tiene el permiso
+func RequirePermission(perm string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "no autorizado"})
+		if !Permissions(c).Has(perm) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "acceso denegado: falta el permiso " + perm})
 			return
 		}
+		c.Next()
+	}
+}
+
+// Permissions devuelve los permisos del usuario autenticado
+func Permissions(c *gin.Context) permissions.Set {
+	if perms, ok := c.Get("permissions"); ok {
+		if set, ok := perms.(permissions.Set); ok {
+			return set
+		}
+	}
+	return permissions.Set{}
+}
 
-		roleStr := role.(string)
-		for _, r := range roles {
-			if roleStr == r {
-				c.Next()
-				return
+// TokenFromQuery pasa el token del parámetro name a la cabecera Authorization
+// cuando el cliente no puede enviar cabeceras (EventSource). Va antes de
+// AuthRequired y solo en las rutas que lo necesitan; el parámetro debe
+// ocultarse en el log (ver Logger).
+func TokenFromQuery(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query(name); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
 			}
 		}
-
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "acceso denegado: rol insuficiente"})
+		c.Next()
 	}
 }
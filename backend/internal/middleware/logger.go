@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Logger es el logger de gin con los parámetros indicados de la URL ocultos,
+// p. ej. el access_token que EventSource envía en la query
+func Logger(redact ...string) gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+		// Mismo formato que gin.Logger
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			redactQuery(param.Path, redact),
+			param.ErrorMessage,
+		)
+	})
+}
+
+// redactQuery reemplaza el valor de los parámetros names en la query de path
+func redactQuery(path string, names []string) string {
+	base, rawQuery, ok := strings.Cut(path, "?")
+	if !ok {
+		return path
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// Una query malformada no se puede revisar: no se registra
+		return base
+	}
+	changed := false
+	for _, name := range names {
+		if _, ok := query[name]; ok {
+			query.Set(name, "oculto")
+			changed = true
+		}
+	}
+	if !changed {
+		return path
+	}
+	return base + "?" + query.Encode()
+}
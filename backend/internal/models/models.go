@@ -4,20 +4,28 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+	"github.com/pos-saas/restaurant-pos/internal/quantity"
 )
 
 // Restaurant representa un restaurante (tenant)
 type Restaurant struct {
-	ID        uuid.UUID  `json:"id"`
-	Name      string     `json:"name"`
-	Email     string     `json:"email"`
-	Phone     string     `json:"phone,omitempty"`
-	Address   string     `json:"address,omitempty"`
-	TaxID     string     `json:"tax_id,omitempty"`
-	LogoURL   string     `json:"logo_url,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"-" db:"deleted_at"`
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Slug     string    `json:"slug"` // código para elegir el restaurante al iniciar sesión
+	Email    string    `json:"email"`
+	Phone    string    `json:"phone,omitempty"`
+	Address  string    `json:"address,omitempty"`
+	TaxID    string    `json:"tax_id,omitempty"`
+	LogoURL  string    `json:"logo_url,omitempty"`
+	Timezone string    `json:"timezone"`
+	// PricesIncludeTax indica si los precios del menú ya incluyen impuestos
+	PricesIncludeTax bool       `json:"prices_include_tax"`
+	DefaultTaxRateID *uuid.UUID `json:"default_tax_rate_id,omitempty"`
+	MaxDiscountBps   int64      `json:"max_discount_bps"` // tope de descuento sin rol admin (1000 = 10%)
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"-" db:"deleted_at"`
 }
 
 // User representa un usuario del sistema
@@ -26,73 +34,666 @@ type User struct {
 	RestaurantID uuid.UUID  `json:"restaurant_id"`
 	Email        string     `json:"email"`
 	PasswordHash string     `json:"-" db:"password_hash"`
-	Role         string     `json:"role"` // admin, cajero
+	Role         string     `json:"role"` // rol predefinido (admin, manager, cajero, mesero, cocina) o propio del restaurante
 	Active       bool       `json:"active"`
+	HasPIN       bool       `json:"has_pin"` // puede entrar con PIN en una terminal
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 	DeletedAt    *time.Time `json:"-" db:"deleted_at"`
 }
 
-// Category representa una categoría de productos
-type Category struct {
+// AuthSession es un inicio de sesión; sus refresh tokens se rotan en cada uso
+type AuthSession struct {
+	ID           uuid.UUID  `json:"id"`
+	RestaurantID uuid.UUID  `json:"restaurant_id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	TerminalID   *uuid.UUID `json:"terminal_id,omitempty"` // sesión abierta con PIN
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokeReason string     `json:"revoke_reason,omitempty"`
+}
+
+// Terminal es un equipo compartido (caja, tablet) donde se cambia de usuario
+// con PIN; se identifica con un token que solo se muestra al registrarla
+type Terminal struct {
+	ID           uuid.UUID  `json:"id"`
+	RestaurantID uuid.UUID  `json:"restaurant_id"`
+	Name         string     `json:"name"`
+	TokenHash    string     `json:"-"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// RefreshToken guarda solo el hash del token que recibe el cliente
+type RefreshToken struct {
+	ID        uuid.UUID  `json:"id"`
+	SessionID uuid.UUID  `json:"session_id"`
+	TokenHash string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// Role es un rol propio del restaurante; los predefinidos están en el paquete permissions
+type Role struct {
 	ID           uuid.UUID `json:"id"`
 	RestaurantID uuid.UUID `json:"restaurant_id"`
 	Name         string    `json:"name"`
-	Description  string    `json:"description,omitempty"`
-	SortOrder    int       `json:"sort_order"`
+	Description  string    `json:"description"`
+	Permissions  []string  `json:"permissions"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
-// Product representa un producto del menú
-type Product struct {
+// Category representa una categoría de productos
+type Category struct {
 	ID           uuid.UUID  `json:"id"`
 	RestaurantID uuid.UUID  `json:"restaurant_id"`
-	CategoryID   *uuid.UUID `json:"category_id,omitempty"`
 	Name         string     `json:"name"`
 	Description  string     `json:"description,omitempty"`
-	Price        float64    `json:"price"`
-	ImageURL     string     `json:"image_url,omitempty"`
-	Active       bool       `json:"active"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	SortOrder    int        `json:"sort_order"`
+	TaxRateID    *uuid.UUID `json:"tax_rate_id,omitempty"`
+	StationID    *uuid.UUID `json:"station_id,omitempty"` // estación de cocina de sus productos
 }
 
+// Product representa un producto del menú
+type Product struct {
+	ID           uuid.UUID   `json:"id"`
+	RestaurantID uuid.UUID   `json:"restaurant_id"`
+	CategoryID   *uuid.UUID  `json:"category_id,omitempty"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description,omitempty"`
+	Price        money.Money `json:"price"`
+	ImageURL     string      `json:"image_url,omitempty"`
+	Active       bool        `json:"active"`
+	TaxRateID    *uuid.UUID  `json:"tax_rate_id,omitempty"`
+	StationID    *uuid.UUID  `json:"station_id,omitempty"` // si es nil se usa la de la categoría
+	Type         string      `json:"type"`                 // simple, combo
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+	// Variants son los tamaños/variantes; si hay alguna activa, se vende por variante
+	Variants []*ProductVariant `json:"variants,omitempty" db:"-"`
+	// Slots son los espacios a elegir de un combo
+	Slots []*ComboSlot `json:"slots,omitempty" db:"-"`
+}
+
+// Tipos de producto
+const (
+	ProductTypeSimple = "simple"
+	ProductTypeCombo  = "combo"
+)
+
+// ComboSlot es un espacio de un combo (ej. "Bebida") con los productos que se
+// pueden elegir. Quantity es cuántas unidades del componente lleva cada combo.
+type ComboSlot struct {
+	ID           uuid.UUID          `json:"id"`
+	RestaurantID uuid.UUID          `json:"restaurant_id"`
+	ComboID      uuid.UUID          `json:"combo_id"`
+	Name         string             `json:"name"`
+	Quantity     int                `json:"quantity"`
+	Required     bool               `json:"required"`
+	SortOrder    int                `json:"sort_order"`
+	Options      []*ComboSlotOption `json:"options" db:"-"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+// ComboSlotOption es un producto permitido en el espacio con su recargo
+type ComboSlotOption struct {
+	SlotID      uuid.UUID   `json:"slot_id"`
+	ProductID   uuid.UUID   `json:"product_id"`
+	ProductName string      `json:"product_name"`
+	Upcharge    money.Money `json:"upcharge"`
+	SortOrder   int         `json:"sort_order"`
+}
+
+// ProductVariant es un tamaño o variante de un producto con su propio precio
+type ProductVariant struct {
+	ID           uuid.UUID   `json:"id"`
+	RestaurantID uuid.UUID   `json:"restaurant_id"`
+	ProductID    uuid.UUID   `json:"product_id"`
+	Name         string      `json:"name"`
+	SKU          string      `json:"sku,omitempty"`
+	Price        money.Money `json:"price"`
+	Active       bool        `json:"active"`
+	SortOrder    int         `json:"sort_order"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// ModifierGroup agrupa opciones que se eligen al vender un producto (ej.
+// "Salsas", "Término de la carne"). MaxSelect = 0 significa sin límite.
+type ModifierGroup struct {
+	ID           uuid.UUID         `json:"id"`
+	RestaurantID uuid.UUID         `json:"restaurant_id"`
+	Name         string            `json:"name"`
+	MinSelect    int               `json:"min_select"`
+	MaxSelect    int               `json:"max_select"`
+	Required     bool              `json:"required"`
+	SortOrder    int               `json:"sort_order"`
+	Options      []*ModifierOption `json:"options" db:"-"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// ModifierOption es una opción de un grupo con su precio adicional
+type ModifierOption struct {
+	ID        uuid.UUID   `json:"id"`
+	GroupID   uuid.UUID   `json:"group_id"`
+	Name      string      `json:"name"`
+	Price     money.Money `json:"price"`
+	Active    bool        `json:"active"`
+	SortOrder int         `json:"sort_order"`
+}
+
+// TaxRate representa una tasa de impuesto (ej. IVA 16%, tasa 0%, exento)
+type TaxRate struct {
+	ID           uuid.UUID `json:"id"`
+	RestaurantID uuid.UUID `json:"restaurant_id"`
+	Name         string    `json:"name"`
+	RateBps      int64     `json:"rate_bps"` // puntos básicos: 1600 = 16%
+	Exempt       bool      `json:"exempt"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Estados de una venta
+const (
+	SaleStatusPending   = "pending"
+	SaleStatusCompleted = "completed"
+	SaleStatusCancelled = "cancelled"
+)
+
+// Tipos de descuento
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
 // Sale representa una venta
 type Sale struct {
-	ID           uuid.UUID  `json:"id"`
-	RestaurantID uuid.UUID  `json:"restaurant_id"`
-	UserID       uuid.UUID  `json:"user_id"`
-	Total        float64    `json:"total"`
-	Status       string     `json:"status"` // pending, completed, cancelled
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	ID             uuid.UUID   `json:"id"`
+	RestaurantID   uuid.UUID   `json:"restaurant_id"`
+	UserID         uuid.UUID   `json:"user_id"`
+	Subtotal       money.Money `json:"subtotal"` // total sin impuestos
+	TaxTotal       money.Money `json:"tax_total"`
+	Total          money.Money `json:"total"`
+	Status         string      `json:"status"`                  // pending, completed, cancelled
+	DiscountType   string      `json:"discount_type,omitempty"` // percent, fixed
+	DiscountValue  money.Money `json:"discount_value,omitempty"`
+	DiscountAmount money.Money `json:"discount_amount"`
+	DiscountReason string      `json:"discount_reason,omitempty"`
+	DiscountTotal  money.Money `json:"discount_total"` // descuento del ticket + descuentos de línea
+	TipTotal       money.Money `json:"tip_total"`      // no forma parte de Total
+	CancelledAt    *time.Time  `json:"cancelled_at,omitempty"`
+	CancelledBy    *uuid.UUID  `json:"cancelled_by,omitempty"`
+	CancelReason   string      `json:"cancel_reason,omitempty"`
+	CashSessionID  *uuid.UUID  `json:"cash_session_id,omitempty"`
+	TableID        *uuid.UUID  `json:"table_id,omitempty"`
+	OpenedAt       *time.Time  `json:"opened_at,omitempty"` // solo cuentas abiertas en mesa
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
 }
 
 // SaleItem representa un item en una venta
 type SaleItem struct {
-	ID        uuid.UUID  `json:"id"`
-	SaleID    uuid.UUID  `json:"sale_id"`
-	ProductID uuid.UUID  `json:"product_id"`
-	Quantity  int        `json:"quantity"`
-	UnitPrice float64    `json:"unit_price"`
-	Subtotal  float64    `json:"subtotal"`
-	Notes     string     `json:"notes,omitempty"`
-	Toppings  []*Topping `json:"toppings,omitempty" db:"-"`
+	ID             uuid.UUID   `json:"id"`
+	SaleID         uuid.UUID   `json:"sale_id"`
+	ProductID      uuid.UUID   `json:"product_id"`
+	VariantID      *uuid.UUID  `json:"variant_id,omitempty"`
+	VariantName    string      `json:"variant_name,omitempty"` // copia al momento de la venta
+	Quantity       int         `json:"quantity"`
+	UnitPrice      money.Money `json:"unit_price"`
+	Subtotal       money.Money `json:"subtotal"` // precio de lista de la línea (incluye toppings)
+	TaxRateID      *uuid.UUID  `json:"tax_rate_id,omitempty"`
+	TaxRateBps     int64       `json:"tax_rate_bps"`
+	TaxAmount      money.Money `json:"tax_amount"`
+	Total          money.Money `json:"total"`                   // lo cobrado por la línea, con descuentos e impuesto
+	DiscountType   string      `json:"discount_type,omitempty"` // percent, fixed
+	DiscountValue  money.Money `json:"discount_value,omitempty"`
+	DiscountAmount money.Money `json:"discount_amount"`
+	DiscountReason string      `json:"discount_reason,omitempty"`
+	TicketDiscount money.Money `json:"ticket_discount"` // parte prorrateada del descuento del ticket
+	Notes          string      `json:"notes,omitempty"`
+	ParentItemID   *uuid.UUID  `json:"parent_item_id,omitempty"`  // línea del combo al que pertenece
+	ComboSlotName  string      `json:"combo_slot_name,omitempty"` // copia del nombre del espacio
+	Toppings       []*Topping  `json:"toppings,omitempty" db:"-"`
+	// Components son las líneas hijas de un combo. Su total es 0: lo cobrado va
+	// en la línea del combo, y unit_price/subtotal muestran recargos y extras.
+	Components []*SaleItem `json:"components,omitempty" db:"-"`
 }
 
-// Topping representa un adicional/topping
+// Topping representa un adicional/topping. Name y Price son copia de la opción
+// del catálogo al momento de la venta; Quantity es el total de la línea.
 type Topping struct {
-	ID        uuid.UUID `json:"id"`
-	SaleItemID uuid.UUID `json:"sale_item_id"`
-	Name      string    `json:"name"`
-	Price     float64   `json:"price"`
-	Quantity  int       `json:"quantity"`
+	ID               uuid.UUID   `json:"id"`
+	SaleItemID       uuid.UUID   `json:"sale_item_id"`
+	ModifierOptionID *uuid.UUID  `json:"modifier_option_id,omitempty"`
+	Name             string      `json:"name"`
+	Price            money.Money `json:"price"`
+	Quantity         int         `json:"quantity"`
+}
+
+// SaleTax es el desglose de impuestos de una venta por tasa
+type SaleTax struct {
+	ID        uuid.UUID   `json:"id"`
+	SaleID    uuid.UUID   `json:"sale_id"`
+	TaxRateID *uuid.UUID  `json:"tax_rate_id,omitempty"`
+	Name      string      `json:"name"`
+	RateBps   int64       `json:"rate_bps"`
+	Exempt    bool        `json:"exempt"`
+	Base      money.Money `json:"base"`
+	Amount    money.Money `json:"amount"`
 }
 
 // SalePayment representa un método de pago en una venta
 type SalePayment struct {
-	ID        uuid.UUID `json:"id"`
-	SaleID    uuid.UUID `json:"sale_id"`
-	Method    string    `json:"method"` // cash, card, transfer
-	Amount    float64   `json:"amount"`
-	Reference string    `json:"reference,omitempty"`
+	ID        uuid.UUID   `json:"id"`
+	SaleID    uuid.UUID   `json:"sale_id"`
+	Method    string      `json:"method"` // cash, card, transfer
+	Amount    money.Money `json:"amount"`
+	Tip       money.Money `json:"tip"` // propina cobrada además de Amount
+	Reference string      `json:"reference,omitempty"`
+	CheckID   *uuid.UUID  `json:"check_id,omitempty"` // subcuenta que cubre, si la venta se dividió
+	// CashSessionID es el turno de quien cobró el pago; con subcuentas puede
+	// no ser el turno de la venta
+	CashSessionID *uuid.UUID `json:"cash_session_id,omitempty"`
+}
+
+// Estados de una subcuenta
+const (
+	SaleCheckOpen = "open"
+	SaleCheckPaid = "paid"
+)
+
+// SaleCheck es una subcuenta de una venta dividida. Se cobra por separado y
+// tiene su propio ticket; la venta se completa al cobrar la última.
+type SaleCheck struct {
+	ID        uuid.UUID        `json:"id"`
+	SaleID    uuid.UUID        `json:"sale_id"`
+	Number    int              `json:"number"`
+	TaxTotal  money.Money      `json:"tax_total"`
+	Total     money.Money      `json:"total"`
+	TipTotal  money.Money      `json:"tip_total"` // no forma parte de Total
+	Status    string           `json:"status"`    // open, paid
+	PaidAt    *time.Time       `json:"paid_at,omitempty"`
+	PaidBy    *uuid.UUID       `json:"paid_by,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Items     []*SaleCheckItem `json:"items,omitempty"`
+	Payments  []*SalePayment   `json:"payments,omitempty"`
+}
+
+// SaleCheckItem es la parte de una línea asignada a una subcuenta:
+// ShareNum/ShareDen de la línea, p. ej. 1/3 de una pizza o 2/3 de tres cervezas
+type SaleCheckItem struct {
+	ID         uuid.UUID   `json:"id"`
+	CheckID    uuid.UUID   `json:"check_id"`
+	SaleItemID uuid.UUID   `json:"sale_item_id"`
+	ShareNum   int         `json:"share_num"`
+	ShareDen   int         `json:"share_den"`
+	TaxAmount  money.Money `json:"tax_amount"`
+	Total      money.Money `json:"total"`
+}
+
+// Refund representa una devolución (nota de crédito) sobre una venta
+type Refund struct {
+	ID            uuid.UUID        `json:"id"`
+	RestaurantID  uuid.UUID        `json:"restaurant_id"`
+	SaleID        uuid.UUID        `json:"sale_id"`
+	UserID        uuid.UUID        `json:"user_id"`
+	CashSessionID *uuid.UUID       `json:"cash_session_id,omitempty"`
+	Total         money.Money      `json:"total"`
+	Reason        string           `json:"reason,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	Items         []*RefundItem    `json:"items,omitempty" db:"-"`
+	Payments      []*RefundPayment `json:"payments,omitempty" db:"-"`
+}
+
+// RefundItem representa una línea devuelta de un sale_item
+type RefundItem struct {
+	ID         uuid.UUID   `json:"id"`
+	RefundID   uuid.UUID   `json:"refund_id"`
+	SaleItemID uuid.UUID   `json:"sale_item_id"`
+	Quantity   int         `json:"quantity"`
+	Amount     money.Money `json:"amount"`
+}
+
+// RefundPayment representa el dinero devuelto por un método de pago
+type RefundPayment struct {
+	ID        uuid.UUID   `json:"id"`
+	RefundID  uuid.UUID   `json:"refund_id"`
+	Method    string      `json:"method"` // cash, card, transfer
+	Amount    money.Money `json:"amount"`
+	Tip       money.Money `json:"tip"` // propina cobrada además de Amount
+	Reference string      `json:"reference,omitempty"`
+}
+
+// Estados de un turno de caja
+const (
+	CashSessionOpen   = "open"
+	CashSessionClosed = "closed"
+)
+
+// CashSession representa un turno de caja de un cajero
+type CashSession struct {
+	ID           uuid.UUID    `json:"id"`
+	RestaurantID uuid.UUID    `json:"restaurant_id"`
+	UserID       uuid.UUID    `json:"user_id"`
+	Status       string       `json:"status"` // open, closed
+	OpeningFloat money.Money  `json:"opening_float"`
+	ExpectedCash *money.Money `json:"expected_cash,omitempty"`
+	CountedCash  *money.Money `json:"counted_cash,omitempty"`
+	Difference   *money.Money `json:"difference,omitempty"`
+	Notes        string       `json:"notes,omitempty"`
+	OpenedAt     time.Time    `json:"opened_at"`
+	ClosedAt     *time.Time   `json:"closed_at,omitempty"`
+	ClosedBy     *uuid.UUID   `json:"closed_by,omitempty"`
+}
+
+// PaymentMethodTotal agrupa montos por método de pago
+type PaymentMethodTotal struct {
+	Method string      `json:"method"`
+	Amount money.Money `json:"amount"`
+	Tips   money.Money `json:"tips"`
+	Count  int         `json:"count"`
+}
+
+// CashSessionSummary es el corte de caja de un turno
+type CashSessionSummary struct {
+	Session        *CashSession          `json:"session"`
+	SalesCount     int                   `json:"sales_count"`
+	SalesTotal     money.Money           `json:"sales_total"`
+	CancelledCount int                   `json:"cancelled_count"`
+	CancelledTotal money.Money           `json:"cancelled_total"`
+	Payments       []*PaymentMethodTotal `json:"payments"`
+	Refunds        []*PaymentMethodTotal `json:"refunds"`
+	TipsTotal      money.Money           `json:"tips_total"`
+	ExpectedCash   money.Money           `json:"expected_cash"`
+}
+
+// Reportes
+
+// ReportSummary resume las ventas completadas de un rango
+type ReportSummary struct {
+	SalesCount    int         `json:"sales_count"`
+	Revenue       money.Money `json:"revenue"`
+	AverageTicket money.Money `json:"average_ticket"`
+	Refunds       money.Money `json:"refunds"`
+	NetRevenue    money.Money `json:"net_revenue"`
+	Tips          money.Money `json:"tips"` // aparte de los ingresos
+}
+
+// RevenueByDay agrupa ventas por día local del restaurante
+type RevenueByDay struct {
+	Day        string      `json:"day"` // YYYY-MM-DD
+	SalesCount int         `json:"sales_count"`
+	Revenue    money.Money `json:"revenue"`
+}
+
+// RevenueByHour agrupa ventas por hora local (0-23)
+type RevenueByHour struct {
+	Hour       int         `json:"hour"`
+	SalesCount int         `json:"sales_count"`
+	Revenue    money.Money `json:"revenue"`
+}
+
+// ProductSales es la venta acumulada de un producto
+type ProductSales struct {
+	ProductID uuid.UUID   `json:"product_id"`
+	Name      string      `json:"name"`
+	Quantity  int         `json:"quantity"`
+	Revenue   money.Money `json:"revenue"`
+}
+
+// CategorySales es la venta acumulada de una categoría
+type CategorySales struct {
+	CategoryID *uuid.UUID  `json:"category_id,omitempty"`
+	Name       string      `json:"name"`
+	Quantity   int         `json:"quantity"`
+	Revenue    money.Money `json:"revenue"`
+}
+
+// CashierSales es la venta acumulada de un cajero
+type CashierSales struct {
+	UserID        uuid.UUID   `json:"user_id"`
+	Email         string      `json:"email"`
+	SalesCount    int         `json:"sales_count"`
+	Revenue       money.Money `json:"revenue"`
+	AverageTicket money.Money `json:"average_ticket"`
+}
+
+// CashierTips son las propinas de un cajero para liquidarlas
+type CashierTips struct {
+	UserID      uuid.UUID   `json:"user_id"`
+	Email       string      `json:"email"`
+	SalesCount  int         `json:"sales_count"` // ventas con propina
+	Tips        money.Money `json:"tips"`
+	CashTips    money.Money `json:"cash_tips"`     // ya están en el cajón
+	NonCashTips money.Money `json:"non_cash_tips"` // tarjeta/transferencia, se pagan desde caja
+}
+
+// Ingredient es un insumo con stock. Stock y umbral están en la unidad del insumo.
+type Ingredient struct {
+	ID                uuid.UUID    `json:"id"`
+	RestaurantID      uuid.UUID    `json:"restaurant_id"`
+	Name              string       `json:"name"`
+	Unit              string       `json:"unit"` // unit, g, kg, ml, l
+	Stock             quantity.Qty `json:"stock"`
+	LowStockThreshold quantity.Qty `json:"low_stock_threshold"` // 0 = sin alerta
+	Active            bool         `json:"active"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+}
+
+// RecipeItem es la cantidad de un insumo que consume una unidad de producto,
+// de variante o de opción de modificador
+type RecipeItem struct {
+	ID               uuid.UUID    `json:"id"`
+	RestaurantID     uuid.UUID    `json:"restaurant_id"`
+	ProductID        *uuid.UUID   `json:"product_id,omitempty"`
+	VariantID        *uuid.UUID   `json:"variant_id,omitempty"`
+	ModifierOptionID *uuid.UUID   `json:"modifier_option_id,omitempty"`
+	IngredientID     uuid.UUID    `json:"ingredient_id"`
+	IngredientName   string       `json:"ingredient_name"`
+	Unit             string       `json:"unit"`
+	Quantity         quantity.Qty `json:"quantity"`
+}
+
+// Tipos de movimiento de stock
+const (
+	StockMovementSale       = "sale"
+	StockMovementSaleCancel = "sale_cancel"
+	StockMovementAdjustment = "adjustment"
+	StockMovementPurchase   = "purchase"
+)
+
+// StockMovement es una entrada (positiva) o salida (negativa) de stock
+type StockMovement struct {
+	ID             uuid.UUID    `json:"id"`
+	RestaurantID   uuid.UUID    `json:"restaurant_id"`
+	IngredientID   uuid.UUID    `json:"ingredient_id"`
+	Quantity       quantity.Qty `json:"quantity"`
+	Type           string       `json:"type"` // sale, sale_cancel, adjustment, purchase
+	Reason         string       `json:"reason,omitempty"`
+	SaleID         *uuid.UUID   `json:"sale_id,omitempty"`
+	GoodsReceiptID *uuid.UUID   `json:"goods_receipt_id,omitempty"`
+	UserID         *uuid.UUID   `json:"user_id,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// TableArea agrupa mesas (ej. "Terraza", "Salón")
+type TableArea struct {
+	ID           uuid.UUID `json:"id"`
+	RestaurantID uuid.UUID `json:"restaurant_id"`
+	Name         string    `json:"name"`
+	SortOrder    int       `json:"sort_order"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Table es una mesa. OpenOrders y OpenTotal resumen sus cuentas abiertas.
+type Table struct {
+	ID           uuid.UUID   `json:"id"`
+	RestaurantID uuid.UUID   `json:"restaurant_id"`
+	AreaID       *uuid.UUID  `json:"area_id,omitempty"`
+	Name         string      `json:"name"`
+	Seats        int         `json:"seats"`
+	Active       bool        `json:"active"`
+	SortOrder    int         `json:"sort_order"`
+	OpenOrders   int         `json:"open_orders"`
+	OpenTotal    money.Money `json:"open_total"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// Supplier es un proveedor de insumos
+type Supplier struct {
+	ID           uuid.UUID `json:"id"`
+	RestaurantID uuid.UUID `json:"restaurant_id"`
+	Name         string    `json:"name"`
+	ContactName  string    `json:"contact_name,omitempty"`
+	Phone        string    `json:"phone,omitempty"`
+	Email        string    `json:"email,omitempty"`
+	Notes        string    `json:"notes,omitempty"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Estados de una orden de compra
+const (
+	PurchaseOrderOpen      = "open"
+	PurchaseOrderPartial   = "partial"
+	PurchaseOrderReceived  = "received"
+	PurchaseOrderCancelled = "cancelled"
+)
+
+// PurchaseOrder es un pedido a un proveedor; se recibe en una o varias entregas
+type PurchaseOrder struct {
+	ID           uuid.UUID            `json:"id"`
+	RestaurantID uuid.UUID            `json:"restaurant_id"`
+	SupplierID   uuid.UUID            `json:"supplier_id"`
+	SupplierName string               `json:"supplier_name"`
+	Status       string               `json:"status"` // open, partial, received, cancelled
+	Total        money.Money          `json:"total"`  // total pedido
+	Notes        string               `json:"notes,omitempty"`
+	ExpectedAt   *time.Time           `json:"expected_at,omitempty"`
+	CreatedBy    uuid.UUID            `json:"created_by"`
+	CancelledAt  *time.Time           `json:"cancelled_at,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	Items        []*PurchaseOrderItem `json:"items,omitempty" db:"-"`
+	Receipts     []*GoodsReceipt      `json:"receipts,omitempty" db:"-"`
+}
+
+// PurchaseOrderItem es un insumo pedido; UnitCost es por unidad del insumo
+type PurchaseOrderItem struct {
+	ID               uuid.UUID    `json:"id"`
+	PurchaseOrderID  uuid.UUID    `json:"purchase_order_id"`
+	IngredientID     uuid.UUID    `json:"ingredient_id"`
+	IngredientName   string       `json:"ingredient_name"`
+	Unit             string       `json:"unit"`
+	Quantity         quantity.Qty `json:"quantity"`
+	UnitCost         money.Money  `json:"unit_cost"`
+	ReceivedQuantity quantity.Qty `json:"received_quantity"`
+}
+
+// GoodsReceipt es una entrega recibida de una orden de compra
+type GoodsReceipt struct {
+	ID              uuid.UUID           `json:"id"`
+	RestaurantID    uuid.UUID           `json:"restaurant_id"`
+	PurchaseOrderID uuid.UUID           `json:"purchase_order_id"`
+	ReceivedBy      uuid.UUID           `json:"received_by"`
+	Total           money.Money         `json:"total"`
+	Notes           string              `json:"notes,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	Items           []*GoodsReceiptItem `json:"items,omitempty" db:"-"`
+}
+
+// GoodsReceiptItem es una línea del historial de costos: cuánto se recibió de
+// un insumo y a qué costo. Supplier* solo se llenan al consultar el historial.
+type GoodsReceiptItem struct {
+	ID                  uuid.UUID    `json:"id"`
+	RestaurantID        uuid.UUID    `json:"restaurant_id"`
+	GoodsReceiptID      uuid.UUID    `json:"goods_receipt_id"`
+	PurchaseOrderItemID uuid.UUID    `json:"purchase_order_item_id"`
+	IngredientID        uuid.UUID    `json:"ingredient_id"`
+	IngredientName      string       `json:"ingredient_name"`
+	Unit                string       `json:"unit"`
+	Quantity            quantity.Qty `json:"quantity"`
+	UnitCost            money.Money  `json:"unit_cost"`
+	TotalCost           money.Money  `json:"total_cost"`
+	PurchaseOrderID     *uuid.UUID   `json:"purchase_order_id,omitempty"`
+	SupplierID          *uuid.UUID   `json:"supplier_id,omitempty"`
+	SupplierName        string       `json:"supplier_name,omitempty"`
+	CreatedAt           time.Time    `json:"created_at"`
+}
+
+// ProductCost es el costo de receta de un producto (o de una de sus variantes)
+// frente a su precio sin impuesto. Los porcentajes van en puntos básicos.
+type ProductCost struct {
+	ProductID    uuid.UUID   `json:"product_id"`
+	VariantID    *uuid.UUID  `json:"variant_id,omitempty"`
+	Name         string      `json:"name"`
+	Type         string      `json:"type"`
+	Price        money.Money `json:"price"` // sin impuesto
+	Cost         money.Money `json:"cost"`
+	Margin       money.Money `json:"margin"`
+	FoodCostBps  int64       `json:"food_cost_bps"`
+	MarginBps    int64       `json:"margin_bps"`
+	HasRecipe    bool        `json:"has_recipe"`
+	MissingCosts []string    `json:"missing_costs,omitempty"` // insumos sin compras registradas
+}
+
+// KitchenStation es una estación de cocina (parrilla, barra, fríos) con su pantalla
+type KitchenStation struct {
+	ID           uuid.UUID `json:"id"`
+	RestaurantID uuid.UUID `json:"restaurant_id"`
+	Name         string    `json:"name"`
+	Active       bool      `json:"active"`
+	SortOrder    int       `json:"sort_order"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Estados de una comanda de cocina
+const (
+	KitchenTicketNew        = "new"
+	KitchenTicketInProgress = "in_progress"
+	KitchenTicketReady      = "ready"
+	KitchenTicketServed     = "served"
+	KitchenTicketVoided     = "voided" // la venta se anuló o se quitaron todas sus líneas
+)
+
+// KitchenTicket es la comanda que recibe una estación con las líneas que le
+// tocan de una venta. TableName y StationName se leen al consultar.
+type KitchenTicket struct {
+	ID           uuid.UUID            `json:"id"`
+	RestaurantID uuid.UUID            `json:"restaurant_id"`
+	StationID    uuid.UUID            `json:"station_id"`
+	StationName  string               `json:"station_name"`
+	SaleID       uuid.UUID            `json:"sale_id"`
+	TableName    string               `json:"table_name,omitempty"`
+	Status       string               `json:"status"` // new, in_progress, ready, served, voided
+	StartedAt    *time.Time           `json:"started_at,omitempty"`
+	ReadyAt      *time.Time           `json:"ready_at,omitempty"`
+	ServedAt     *time.Time           `json:"served_at,omitempty"`
+	VoidedAt     *time.Time           `json:"voided_at,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	Items        []*KitchenTicketItem `json:"items"`
+}
+
+// KitchenTicketItem es una línea de la comanda. Modifiers son los toppings ya
+// descritos ("Extra queso x2"); ComboName indica el combo del que es componente.
+type KitchenTicketItem struct {
+	ID         uuid.UUID  `json:"id"`
+	TicketID   uuid.UUID  `json:"ticket_id"`
+	SaleItemID *uuid.UUID `json:"sale_item_id,omitempty"`
+	Position   int        `json:"position"`
+	Name       string     `json:"name"`
+	Quantity   int        `json:"quantity"`
+	Notes      string     `json:"notes,omitempty"`
+	Modifiers  []string   `json:"modifiers"`
+	ComboName  string     `json:"combo_name,omitempty"`
 }
@@ -0,0 +1,220 @@
+// Package money representa importes monetarios exactos en centavos.
+//
+// Todas las columnas de dinero son DECIMAL(12, 2), así que un int64 de
+// centavos las representa sin pérdida. La única operación que redondea es
+// MulDiv (y sus derivadas), siempre a la mitad alejándose de cero.
+package money
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Money es un importe en centavos
+type Money int64
+
+// Zero es el importe nulo
+const Zero Money = 0
+
+// FromCents construye un importe a partir de centavos
+func FromCents(cents int64) Money {
+	return Money(cents)
+}
+
+// Cents devuelve el importe en centavos
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// Times multiplica el importe por una cantidad entera (exacto)
+func (m Money) Times(n int) Money {
+	return m * Money(n)
+}
+
+// MulDiv calcula m * num / den redondeando a la mitad alejándose de cero
+func (m Money) MulDiv(num, den int64) Money {
+	if den == 0 {
+		panic("money: división por cero")
+	}
+	p := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(num))
+	d := big.NewInt(den)
+	q, r := new(big.Int).QuoRem(p, d, new(big.Int))
+
+	// |2r| >= |d| ⇒ redondear alejándose de cero
+	r2 := new(big.Int).Abs(r)
+	r2.Lsh(r2, 1)
+	if r2.Cmp(new(big.Int).Abs(d)) >= 0 {
+		if (p.Sign() < 0) != (d.Sign() < 0) {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Money(q.Int64())
+}
+
+// Percent aplica un porcentaje expresado en puntos básicos (1600 = 16%)
+func (m Money) Percent(basisPoints int64) Money {
+	return m.MulDiv(basisPoints, 10000)
+}
+
+// Abs devuelve el valor absoluto
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// String formatea con dos decimales, ej. "-12.50"
+func (m Money) String() string {
+	sign := ""
+	c := int64(m)
+	if c < 0 {
+		sign = "-"
+		c = -c
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, c/100, c%100)
+}
+
+// Parse interpreta un decimal como "12", "12.5" o "-0.05". Si trae más de dos
+// decimales se redondea a la mitad alejándose de cero.
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("money: importe vacío")
+	}
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		// "-", "+" o "." sin dígitos
+		return 0, fmt.Errorf("money: importe inválido %q", s)
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	if !isDigits(intPart) || (fracPart != "" && !isDigits(fracPart)) {
+		return 0, fmt.Errorf("money: importe inválido %q", s)
+	}
+
+	roundUp := false
+	if len(fracPart) > 2 {
+		roundUp = fracPart[2] >= '5'
+		fracPart = fracPart[:2]
+	}
+	for len(fracPart) < 2 {
+		fracPart += "0"
+	}
+
+	cents, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("money: importe fuera de rango %q", s)
+	}
+	if roundUp {
+		cents++
+	}
+	if neg {
+		cents = -cents
+	}
+	return Money(cents), nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+// MarshalJSON serializa como número JSON con dos decimales
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON acepta un número JSON o un string con el decimal
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 1 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+		data = []byte(unquoted)
+	}
+	// Los números JSON pueden venir en notación exponencial (1e2)
+	if bytes.ContainsAny(data, "eE") {
+		r, ok := new(big.Rat).SetString(string(data))
+		if !ok {
+			return fmt.Errorf("money: importe inválido %s", data)
+		}
+		data = []byte(r.FloatString(3))
+	}
+	v, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// UnmarshalParam permite usar Money en parámetros de query (gin binding)
+func (m *Money) UnmarshalParam(param string) error {
+	v, err := Parse(param)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// ScanNumeric lee un NUMERIC de PostgreSQL (pgx)
+func (m *Money) ScanNumeric(n pgtype.Numeric) error {
+	if !n.Valid {
+		return fmt.Errorf("money: no se puede leer NULL, usa *money.Money")
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("money: valor numérico no finito")
+	}
+
+	// valor = Int * 10^Exp  ⇒  centavos = Int * 10^(Exp+2)
+	shift := int64(n.Exp) + 2
+	cents := new(big.Int).Set(n.Int)
+	if shift >= 0 {
+		cents.Mul(cents, new(big.Int).Exp(big.NewInt(10), big.NewInt(shift), nil))
+		if !cents.IsInt64() {
+			return fmt.Errorf("money: importe fuera de rango")
+		}
+		*m = Money(cents.Int64())
+		return nil
+	}
+
+	den := new(big.Int).Exp(big.NewInt(10), big.NewInt(-shift), nil)
+	if !cents.IsInt64() || !den.IsInt64() {
+		return fmt.Errorf("money: importe fuera de rango")
+	}
+	*m = Money(cents.Int64()).MulDiv(1, den.Int64())
+	return nil
+}
+
+// NumericValue escribe el importe como NUMERIC de PostgreSQL (pgx)
+func (m Money) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(m)), Exp: -2, Valid: true}, nil
+}
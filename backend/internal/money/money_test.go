@@ -0,0 +1,139 @@
+package money
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{"12", 1200},
+		{"12.5", 1250},
+		{"12.50", 1250},
+		{"0.05", 5},
+		{"-0.05", -5},
+		{"+3.1", 310},
+		{".75", 75},
+		{" 7.25 ", 725},
+		// Más de dos decimales: mitad alejándose de cero
+		{"1.005", 101},
+		{"1.004", 100},
+		{"0.995", 100},
+		{"-1.005", -101},
+		{"-0.004", 0},
+		{"99999999999.99", 9999999999999},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{"", "  ", "-", "+", ".", "abc", "1,50", "1.2.3", "--1", "1e2", "12a", "99999999999999999999"} {
+		if got, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %d, want error", in, got)
+		}
+	}
+}
+
+func TestMulDiv(t *testing.T) {
+	tests := []struct {
+		m        Money
+		num, den int64
+		want     Money
+	}{
+		{1000, 1, 3, 333},
+		{2000, 1, 3, 667},
+		{1, 1, 2, 1},   // 0.5 ⇒ 1
+		{-1, 1, 2, -1}, // -0.5 ⇒ -1
+		{1, -1, 2, -1}, // signo en el numerador
+		{1, 1, -2, -1}, // signo en el denominador
+		{-1, -1, 2, 1}, // dos signos negativos
+		{3, 1, 4, 1},   // 0.75 ⇒ 1
+		{1, 1, 4, 0},   // 0.25 ⇒ 0
+		{-5, 1, 4, -1}, // -1.25 ⇒ -1
+		{-7, 1, 4, -2}, // -1.75 ⇒ -2
+		{10000, 1600, 10000, 1600},
+		{9999999999999, 3, 3, 9999999999999}, // el producto intermedio no desborda
+	}
+	for _, tt := range tests {
+		if got := tt.m.MulDiv(tt.num, tt.den); got != tt.want {
+			t.Errorf("%d.MulDiv(%d, %d) = %d, want %d", tt.m, tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+func TestMulDivByZero(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MulDiv(1, 0) no entró en pánico")
+		}
+	}()
+	Money(100).MulDiv(1, 0)
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		m    Money
+		bps  int64
+		want Money
+	}{
+		{11600, 1600, 1856},
+		{999, 1600, 160}, // 159.84
+		{5, 1000, 1},     // 0.5 ⇒ 1
+		{0, 1600, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Percent(tt.bps); got != tt.want {
+			t.Errorf("%d.Percent(%d) = %d, want %d", tt.m, tt.bps, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-5, "-0.05"},
+		{1250, "12.50"},
+		{-123456, "-1234.56"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{`12.5`, 1250},
+		{`"12.5"`, 1250},
+		{`1e2`, 10000},
+		{`1.005e0`, 101},
+		{`null`, 0},
+	}
+	for _, tt := range tests {
+		var m Money
+		if err := m.UnmarshalJSON([]byte(tt.in)); err != nil {
+			t.Errorf("UnmarshalJSON(%s): %v", tt.in, err)
+			continue
+		}
+		if m != tt.want {
+			t.Errorf("UnmarshalJSON(%s) = %d, want %d", tt.in, m, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package permissions define lo que puede hacer cada usuario en la API.
+//
+// Cada ruta exige un permiso (recurso:acción). Un rol es un conjunto de
+// permisos: los roles predefinidos (admin, manager, cajero, mesero, cocina)
+// viven en el código y cada restaurante puede crear los suyos. Los permisos
+// del usuario se calculan al iniciar sesión y viajan en el JWT.
+package permissions
+
+import "sort"
+
+// Permisos
+const (
+	SettingsManage     = "settings:manage"     // datos del restaurante, impuestos, mesas y estaciones
+	UsersManage        = "users:manage"        // usuarios y roles
+	MenuView           = "menu:view"           // menú, mesas y configuración de solo lectura
+	ProductsWrite      = "products:write"      // categorías, productos, variantes, combos y modificadores
+	InventoryView      = "inventory:view"      // insumos, recetas y movimientos
+	InventoryWrite     = "inventory:write"     // altas, ajustes de stock y recetas
+	PurchasingManage   = "purchasing:manage"   // proveedores, órdenes de compra e historial de costos
+	SalesView          = "sales:view"          // ventas, tickets y devoluciones registradas
+	SalesCreate        = "sales:create"        // cobrar: ventas, cierre de cuentas y subcuentas
+	SalesCancel        = "sales:cancel"        // anular ventas
+	SalesRefund        = "sales:refund"        // registrar devoluciones
+	DiscountsApply     = "discounts:apply"     // descuentos hasta el tope del restaurante
+	DiscountsUnlimited = "discounts:unlimited" // descuentos sin tope
+	OrdersManage       = "orders:manage"       // cuentas abiertas: abrir, agregar, mover, dividir
+	KitchenView        = "kitchen:view"        // comandas de cocina
+	KitchenUpdate      = "kitchen:update"      // avanzar el estado de las comandas
+	CashOperate        = "cash:operate"        // abrir y cerrar el turno propio
+	CashManage         = "cash:manage"         // ver y cerrar turnos de otros
+	ReportsView        = "reports:view"        // reportes y costo de recetas
+)
+
+// All son todos los permisos en el orden en que se muestran
+var All = []string{
+	SettingsManage, UsersManage,
+	MenuView, ProductsWrite,
+	InventoryView, InventoryWrite, PurchasingManage,
+	SalesView, SalesCreate, SalesCancel, SalesRefund, DiscountsApply, DiscountsUnlimited,
+	OrdersManage, KitchenView, KitchenUpdate,
+	CashOperate, CashManage, ReportsView,
+}
+
+// Roles predefinidos
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleCajero  = "cajero"
+	RoleMesero  = "mesero"
+	RoleCocina  = "cocina"
+)
+
+// BuiltinRoles son los roles predefinidos en el orden en que se muestran
+var BuiltinRoles = []string{RoleAdmin, RoleManager, RoleCajero, RoleMesero, RoleCocina}
+
+var builtin = map[string][]string{
+	RoleAdmin: All,
+	RoleManager: {
+		MenuView, ProductsWrite,
+		InventoryView, InventoryWrite, PurchasingManage,
+		SalesView, SalesCreate, SalesCancel, SalesRefund, DiscountsApply, DiscountsUnlimited,
+		OrdersManage, KitchenView, KitchenUpdate,
+		CashOperate, CashManage, ReportsView,
+	},
+	RoleCajero: {
+		MenuView, InventoryView,
+		SalesView, SalesCreate, DiscountsApply,
+		OrdersManage, KitchenView, KitchenUpdate,
+		CashOperate,
+	},
+	RoleMesero: {MenuView, OrdersManage, KitchenView},
+	RoleCocina: {MenuView, KitchenView, KitchenUpdate},
+}
+
+// Builtin devuelve los permisos de un rol predefinido
+func Builtin(role string) ([]string, bool) {
+	perms, ok := builtin[role]
+	return perms, ok
+}
+
+func IsBuiltin(role string) bool {
+	_, ok := builtin[role]
+	return ok
+}
+
+var known = NewSet(All...)
+
+// Valid indica si perm es un permiso existente
+func Valid(perm string) bool {
+	return known.Has(perm)
+}
+
+// Set es un conjunto de permisos
+type Set map[string]struct{}
+
+func NewSet(perms ...string) Set {
+	s := make(Set, len(perms))
+	for _, p := range perms {
+		s[p] = struct{}{}
+	}
+	return s
+}
+
+func (s Set) Has(perm string) bool {
+	_, ok := s[perm]
+	return ok
+}
+
+// List devuelve los permisos ordenados
+func (s Set) List() []string {
+	list := make([]string, 0, len(s))
+	for p := range s {
+		list = append(list, p)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,177 @@
+// Package quantity representa cantidades de inventario exactas en milésimas.
+//
+// Las columnas de stock son DECIMAL(12, 3), así que un int64 de milésimas las
+// representa sin pérdida (1.5 kg = 1500, 250 g = 250000 si la unidad es g).
+package quantity
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Qty es una cantidad en milésimas de la unidad del insumo
+type Qty int64
+
+// Zero es la cantidad nula
+const Zero Qty = 0
+
+// scale son las milésimas por unidad
+const scale = 1000
+
+// Times multiplica la cantidad por un entero (exacto)
+func (q Qty) Times(n int) Qty {
+	return q * Qty(n)
+}
+
+// Milli devuelve la cantidad en milésimas
+func (q Qty) Milli() int64 {
+	return int64(q)
+}
+
+// String formatea con tres decimales, ej. "-1.250"
+func (q Qty) String() string {
+	sign := ""
+	m := int64(q)
+	if m < 0 {
+		sign = "-"
+		m = -m
+	}
+	return fmt.Sprintf("%s%d.%03d", sign, m/scale, m%scale)
+}
+
+// Parse interpreta un decimal como "2", "0.25" o "-1.5". Si trae más de tres
+// decimales se redondea a la mitad alejándose de cero.
+func Parse(s string) (Qty, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("quantity: cantidad vacía")
+	}
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" {
+		intPart = "0"
+	}
+	if !isDigits(intPart) || (fracPart != "" && !isDigits(fracPart)) {
+		return 0, fmt.Errorf("quantity: cantidad inválida %q", s)
+	}
+
+	roundUp := false
+	if len(fracPart) > 3 {
+		roundUp = fracPart[3] >= '5'
+		fracPart = fracPart[:3]
+	}
+	for len(fracPart) < 3 {
+		fracPart += "0"
+	}
+
+	milli, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("quantity: cantidad fuera de rango %q", s)
+	}
+	if roundUp {
+		milli++
+	}
+	if neg {
+		milli = -milli
+	}
+	return Qty(milli), nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+// MarshalJSON serializa como número JSON con tres decimales
+func (q Qty) MarshalJSON() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalJSON acepta un número JSON o un string con el decimal
+func (q *Qty) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 1 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+		data = []byte(unquoted)
+	}
+	if bytes.ContainsAny(data, "eE") {
+		r, ok := new(big.Rat).SetString(string(data))
+		if !ok {
+			return fmt.Errorf("quantity: cantidad inválida %s", data)
+		}
+		data = []byte(r.FloatString(4))
+	}
+	v, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+	*q = v
+	return nil
+}
+
+// ScanNumeric lee un NUMERIC de PostgreSQL (pgx)
+func (q *Qty) ScanNumeric(n pgtype.Numeric) error {
+	if !n.Valid {
+		return fmt.Errorf("quantity: no se puede leer NULL, usa *quantity.Qty")
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("quantity: valor numérico no finito")
+	}
+
+	// valor = Int * 10^Exp  ⇒  milésimas = Int * 10^(Exp+3)
+	shift := int64(n.Exp) + 3
+	milli := new(big.Int).Set(n.Int)
+	if shift >= 0 {
+		milli.Mul(milli, new(big.Int).Exp(big.NewInt(10), big.NewInt(shift), nil))
+		if !milli.IsInt64() {
+			return fmt.Errorf("quantity: cantidad fuera de rango")
+		}
+		*q = Qty(milli.Int64())
+		return nil
+	}
+
+	// Más de tres decimales: redondear a la mitad alejándose de cero
+	den := new(big.Int).Exp(big.NewInt(10), big.NewInt(-shift), nil)
+	quo, rem := new(big.Int).QuoRem(milli, den, new(big.Int))
+	rem.Abs(rem).Lsh(rem, 1)
+	if rem.Cmp(den) >= 0 {
+		if milli.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	if !quo.IsInt64() {
+		return fmt.Errorf("quantity: cantidad fuera de rango")
+	}
+	*q = Qty(quo.Int64())
+	return nil
+}
+
+// NumericValue escribe la cantidad como NUMERIC de PostgreSQL (pgx)
+func (q Qty) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(q)), Exp: -3, Valid: true}, nil
+}
@@ -2,28 +2,36 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pos-saas/restaurant-pos/internal/errors"
 	"github.com/pos-saas/restaurant-pos/internal/models"
 )
 
 type AuthRepository struct {
-	pool *pgxpool.Pool
+	db DBTX
 }
 
 func NewAuthRepository(pool *pgxpool.Pool) *AuthRepository {
-	return &AuthRepository{pool: pool}
+	return &AuthRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *AuthRepository) WithTx(tx pgx.Tx) *AuthRepository {
+	return &AuthRepository{db: tx}
 }
 
 func (r *AuthRepository) CreateRestaurant(ctx context.Context, rest *models.Restaurant) error {
 	query := `
-		INSERT INTO restaurants (id, name, email, phone, address, tax_id, logo_url)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO restaurants (id, name, slug, email, phone, address, tax_id, logo_url, timezone, prices_include_tax, max_discount_bps)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
-	_, err := r.pool.Exec(ctx, query,
-		rest.ID, rest.Name, rest.Email, rest.Phone, rest.Address, rest.TaxID, rest.LogoURL,
+	_, err := r.db.Exec(ctx, query,
+		rest.ID, rest.Name, rest.Slug, rest.Email, rest.Phone, rest.Address, rest.TaxID, rest.LogoURL, rest.Timezone,
+		rest.PricesIncludeTax, rest.MaxDiscountBps,
 	)
 	if err != nil {
 		if isUniqueViolation(err) {
@@ -39,7 +47,7 @@ func (r *AuthRepository) CreateUser(ctx context.Context, user *models.User) erro
 		INSERT INTO users (id, restaurant_id, email, password_hash, role, active)
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err := r.pool.Exec(ctx, query,
+	_, err := r.db.Exec(ctx, query,
 		user.ID, user.RestaurantID, user.Email, user.PasswordHash, user.Role, user.Active,
 	)
 	if err != nil {
@@ -53,14 +61,34 @@ func (r *AuthRepository) CreateUser(ctx context.Context, user *models.User) erro
 
 func (r *AuthRepository) GetUserByEmail(ctx context.Context, restaurantID uuid.UUID, email string) (*models.User, error) {
 	query := `
-		SELECT id, restaurant_id, email, password_hash, role, active, created_at, updated_at
+		SELECT id, restaurant_id, email, password_hash, role, active, pin_hash IS NOT NULL, created_at, updated_at
 		FROM users
 		WHERE restaurant_id = $1 AND LOWER(email) = LOWER($2) AND deleted_at IS NULL
 	`
 	var user models.User
-	err := r.pool.QueryRow(ctx, query, restaurantID, email).Scan(
+	err := r.db.QueryRow(ctx, query, restaurantID, email).Scan(
+		&user.ID, &user.RestaurantID, &user.Email, &user.PasswordHash,
+		&user.Role, &user.Active, &user.HasPIN, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *AuthRepository) GetUserByID(ctx context.Context, restaurantID, userID uuid.UUID) (*models.User, error) {
+	query := `
+		SELECT id, restaurant_id, email, password_hash, role, active, pin_hash IS NOT NULL, created_at, updated_at
+		FROM users
+		WHERE restaurant_id = $1 AND id = $2 AND deleted_at IS NULL
+	`
+	var user models.User
+	err := r.db.QueryRow(ctx, query, restaurantID, userID).Scan(
 		&user.ID, &user.RestaurantID, &user.Email, &user.PasswordHash,
-		&user.Role, &user.Active, &user.CreatedAt, &user.UpdatedAt,
+		&user.Role, &user.Active, &user.HasPIN, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		if isNoRows(err) {
@@ -71,16 +99,242 @@ func (r *AuthRepository) GetUserByEmail(ctx context.Context, restaurantID uuid.U
 	return &user, nil
 }
 
+// LoginCandidate es una cuenta que corresponde a un email de inicio de sesión,
+// con los datos de su restaurante
+type LoginCandidate struct {
+	User            models.User
+	RestaurantName  string
+	RestaurantSlug  string
+	RestaurantEmail string
+}
+
+// ListLoginCandidates busca el email en todos los restaurantes; slug no vacío
+// limita la búsqueda a ese restaurante
+func (r *AuthRepository) ListLoginCandidates(ctx context.Context, email, slug string) ([]*LoginCandidate, error) {
+	query := `
+		SELECT u.id, u.restaurant_id, u.email, u.password_hash, u.role, u.active, u.pin_hash IS NOT NULL, u.created_at, u.updated_at,
+		       r.name, r.slug, r.email
+		FROM users u
+		JOIN restaurants r ON r.id = u.restaurant_id
+		WHERE LOWER(u.email) = LOWER($1) AND u.deleted_at IS NULL AND r.deleted_at IS NULL
+		  AND ($2 = '' OR r.slug = $2)
+		ORDER BY r.name
+	`
+	rows, err := r.db.Query(ctx, query, email, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []*LoginCandidate
+	for rows.Next() {
+		var c LoginCandidate
+		if err := rows.Scan(
+			&c.User.ID, &c.User.RestaurantID, &c.User.Email, &c.User.PasswordHash,
+			&c.User.Role, &c.User.Active, &c.User.HasPIN, &c.User.CreatedAt, &c.User.UpdatedAt,
+			&c.RestaurantName, &c.RestaurantSlug, &c.RestaurantEmail,
+		); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, &c)
+	}
+	return candidates, rows.Err()
+}
+
+// ListUsers devuelve los usuarios no borrados del restaurante
+func (r *AuthRepository) ListUsers(ctx context.Context, restaurantID uuid.UUID) ([]*models.User, error) {
+	query := `
+		SELECT id, restaurant_id, email, password_hash, role, active, pin_hash IS NOT NULL, created_at, updated_at
+		FROM users
+		WHERE restaurant_id = $1 AND deleted_at IS NULL
+		ORDER BY LOWER(email)
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(
+			&user.ID, &user.RestaurantID, &user.Email, &user.PasswordHash,
+			&user.Role, &user.Active, &user.HasPIN, &user.CreatedAt, &user.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+// UpdateUser guarda el rol y si está activo
+func (r *AuthRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	query := `
+		UPDATE users SET role = $3, active = $4
+		WHERE id = $1 AND restaurant_id = $2 AND deleted_at IS NULL
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(ctx, query, user.ID, user.RestaurantID, user.Role, user.Active).Scan(&user.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return errors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *AuthRepository) UpdateUserPassword(ctx context.Context, restaurantID, userID uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $3 WHERE id = $1 AND restaurant_id = $2 AND deleted_at IS NULL`
+	result, err := r.db.Exec(ctx, query, userID, restaurantID, passwordHash)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// SetUserPIN guarda el hash del PIN (nil lo quita) y levanta el bloqueo
+func (r *AuthRepository) SetUserPIN(ctx context.Context, restaurantID, userID uuid.UUID, pinHash *string) error {
+	query := `
+		UPDATE users SET pin_hash = $3, pin_failed_attempts = 0, pin_locked_until = NULL
+		WHERE id = $1 AND restaurant_id = $2 AND deleted_at IS NULL
+	`
+	result, err := r.db.Exec(ctx, query, userID, restaurantID, pinHash)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// UserPIN es el estado del PIN de un usuario
+type UserPIN struct {
+	User           models.User
+	PINHash        string
+	FailedAttempts int
+	LockedUntil    *time.Time
+}
+
+// GetUserPINForUpdate bloquea al usuario para contar los intentos de PIN sin
+// que dos intentos simultáneos se pisen
+func (r *AuthRepository) GetUserPINForUpdate(ctx context.Context, restaurantID, userID uuid.UUID) (*UserPIN, error) {
+	query := `
+		SELECT id, restaurant_id, email, password_hash, role, active, pin_hash IS NOT NULL, created_at, updated_at,
+		       COALESCE(pin_hash, ''), pin_failed_attempts, pin_locked_until
+		FROM users
+		WHERE restaurant_id = $1 AND id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	var p UserPIN
+	err := r.db.QueryRow(ctx, query, restaurantID, userID).Scan(
+		&p.User.ID, &p.User.RestaurantID, &p.User.Email, &p.User.PasswordHash,
+		&p.User.Role, &p.User.Active, &p.User.HasPIN, &p.User.CreatedAt, &p.User.UpdatedAt,
+		&p.PINHash, &p.FailedAttempts, &p.LockedUntil,
+	)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpdatePINAttempts guarda los intentos fallidos y hasta cuándo queda bloqueado
+func (r *AuthRepository) UpdatePINAttempts(ctx context.Context, userID uuid.UUID, attempts int, lockedUntil *time.Time) error {
+	query := `UPDATE users SET pin_failed_attempts = $2, pin_locked_until = $3 WHERE id = $1`
+	_, err := r.db.Exec(ctx, query, userID, attempts, lockedUntil)
+	return err
+}
+
+// ListPINUsers devuelve los usuarios activos que pueden entrar con PIN
+func (r *AuthRepository) ListPINUsers(ctx context.Context, restaurantID uuid.UUID) ([]*models.User, error) {
+	query := `
+		SELECT id, restaurant_id, email, password_hash, role, active, true, created_at, updated_at
+		FROM users
+		WHERE restaurant_id = $1 AND active = true AND deleted_at IS NULL AND pin_hash IS NOT NULL
+		ORDER BY LOWER(email)
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(
+			&user.ID, &user.RestaurantID, &user.Email, &user.PasswordHash,
+			&user.Role, &user.Active, &user.HasPIN, &user.CreatedAt, &user.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+// DeleteUser da de baja al usuario; sus ventas y turnos lo siguen referenciando
+func (r *AuthRepository) DeleteUser(ctx context.Context, restaurantID, userID uuid.UUID) error {
+	query := `
+		UPDATE users SET active = false, deleted_at = NOW()
+		WHERE id = $1 AND restaurant_id = $2 AND deleted_at IS NULL
+	`
+	result, err := r.db.Exec(ctx, query, userID, restaurantID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// LockActiveAdmins bloquea los admin activos del restaurante y devuelve sus
+// IDs; dentro de la transacción nadie más puede quitar un admin a la vez
+func (r *AuthRepository) LockActiveAdmins(ctx context.Context, restaurantID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM users
+		WHERE restaurant_id = $1 AND role = 'admin' AND active = true AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 func (r *AuthRepository) GetRestaurantByEmail(ctx context.Context, email string) (*models.Restaurant, error) {
 	query := `
-		SELECT id, name, email, phone, address, tax_id, logo_url, created_at, updated_at
+		SELECT id, name, slug, email, phone, address, tax_id, logo_url, timezone,
+		       prices_include_tax, default_tax_rate_id, max_discount_bps, created_at, updated_at
 		FROM restaurants
 		WHERE LOWER(email) = LOWER($1) AND deleted_at IS NULL
 	`
 	var rest models.Restaurant
-	err := r.pool.QueryRow(ctx, query, email).Scan(
-		&rest.ID, &rest.Name, &rest.Email, &rest.Phone, &rest.Address,
-		&rest.TaxID, &rest.LogoURL, &rest.CreatedAt, &rest.UpdatedAt,
+	err := r.db.QueryRow(ctx, query, email).Scan(
+		&rest.ID, &rest.Name, &rest.Slug, &rest.Email, &rest.Phone, &rest.Address,
+		&rest.TaxID, &rest.LogoURL, &rest.Timezone,
+		&rest.PricesIncludeTax, &rest.DefaultTaxRateID, &rest.MaxDiscountBps, &rest.CreatedAt, &rest.UpdatedAt,
 	)
 	if err != nil {
 		if isNoRows(err) {
@@ -91,16 +345,24 @@ func (r *AuthRepository) GetRestaurantByEmail(ctx context.Context, email string)
 	return &rest, nil
 }
 
+func (r *AuthRepository) RestaurantSlugExists(ctx context.Context, slug string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM restaurants WHERE slug = $1)`, slug).Scan(&exists)
+	return exists, err
+}
+
 func (r *AuthRepository) GetRestaurantByID(ctx context.Context, id uuid.UUID) (*models.Restaurant, error) {
 	query := `
-		SELECT id, name, email, phone, address, tax_id, logo_url, created_at, updated_at
+		SELECT id, name, slug, email, phone, address, tax_id, logo_url, timezone,
+		       prices_include_tax, default_tax_rate_id, max_discount_bps, created_at, updated_at
 		FROM restaurants
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 	var rest models.Restaurant
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&rest.ID, &rest.Name, &rest.Email, &rest.Phone, &rest.Address,
-		&rest.TaxID, &rest.LogoURL, &rest.CreatedAt, &rest.UpdatedAt,
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&rest.ID, &rest.Name, &rest.Slug, &rest.Email, &rest.Phone, &rest.Address,
+		&rest.TaxID, &rest.LogoURL, &rest.Timezone,
+		&rest.PricesIncludeTax, &rest.DefaultTaxRateID, &rest.MaxDiscountBps, &rest.CreatedAt, &rest.UpdatedAt,
 	)
 	if err != nil {
 		if isNoRows(err) {
@@ -110,3 +372,26 @@ func (r *AuthRepository) GetRestaurantByID(ctx context.Context, id uuid.UUID) (*
 	}
 	return &rest, nil
 }
+
+func (r *AuthRepository) UpdateRestaurant(ctx context.Context, rest *models.Restaurant) error {
+	query := `
+		UPDATE restaurants
+		SET name = $2, phone = $3, address = $4, tax_id = $5, logo_url = $6, timezone = $7,
+		    prices_include_tax = $8, default_tax_rate_id = $9, max_discount_bps = $10, slug = $11
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	result, err := r.db.Exec(ctx, query,
+		rest.ID, rest.Name, rest.Phone, rest.Address, rest.TaxID, rest.LogoURL, rest.Timezone,
+		rest.PricesIncludeTax, rest.DefaultTaxRateID, rest.MaxDiscountBps, rest.Slug,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
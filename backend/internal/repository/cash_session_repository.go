@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+type CashSessionRepository struct {
+	db DBTX
+}
+
+func NewCashSessionRepository(pool *pgxpool.Pool) *CashSessionRepository {
+	return &CashSessionRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *CashSessionRepository) WithTx(tx pgx.Tx) *CashSessionRepository {
+	return &CashSessionRepository{db: tx}
+}
+
+const cashSessionColumns = `id, restaurant_id, user_id, status, opening_float, expected_cash, counted_cash,
+		difference, COALESCE(notes, ''), opened_at, closed_at, closed_by`
+
+func scanCashSession(row pgx.Row) (*models.CashSession, error) {
+	var cs models.CashSession
+	err := row.Scan(
+		&cs.ID, &cs.RestaurantID, &cs.UserID, &cs.Status, &cs.OpeningFloat, &cs.ExpectedCash,
+		&cs.CountedCash, &cs.Difference, &cs.Notes, &cs.OpenedAt, &cs.ClosedAt, &cs.ClosedBy,
+	)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &cs, nil
+}
+
+// Create abre un turno. Devuelve ErrConflict si el usuario ya tiene uno abierto.
+func (r *CashSessionRepository) Create(ctx context.Context, cs *models.CashSession) error {
+	query := `
+		INSERT INTO cash_sessions (id, restaurant_id, user_id, status, opening_float, notes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING opened_at
+	`
+	err := r.db.QueryRow(ctx, query,
+		cs.ID, cs.RestaurantID, cs.UserID, cs.Status, cs.OpeningFloat, cs.Notes,
+	).Scan(&cs.OpenedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *CashSessionRepository) GetByID(ctx context.Context, restaurantID, sessionID uuid.UUID) (*models.CashSession, error) {
+	query := `SELECT ` + cashSessionColumns + ` FROM cash_sessions WHERE id = $1 AND restaurant_id = $2`
+	return scanCashSession(r.db.QueryRow(ctx, query, sessionID, restaurantID))
+}
+
+// GetByIDForUpdate bloquea el turno hasta el fin de la transacción (para el cierre)
+func (r *CashSessionRepository) GetByIDForUpdate(ctx context.Context, restaurantID, sessionID uuid.UUID) (*models.CashSession, error) {
+	query := `SELECT ` + cashSessionColumns + ` FROM cash_sessions WHERE id = $1 AND restaurant_id = $2 FOR UPDATE`
+	return scanCashSession(r.db.QueryRow(ctx, query, sessionID, restaurantID))
+}
+
+// GetOpenByUser devuelve el turno abierto del usuario o ErrNotFound
+func (r *CashSessionRepository) GetOpenByUser(ctx context.Context, restaurantID, userID uuid.UUID) (*models.CashSession, error) {
+	query := `SELECT ` + cashSessionColumns + ` FROM cash_sessions WHERE restaurant_id = $1 AND user_id = $2 AND status = 'open'`
+	return scanCashSession(r.db.QueryRow(ctx, query, restaurantID, userID))
+}
+
+// GetOpenByUserForShare es como GetOpenByUser pero impide que el turno se cierre
+// mientras la transacción actual registra movimientos en él
+func (r *CashSessionRepository) GetOpenByUserForShare(ctx context.Context, restaurantID, userID uuid.UUID) (*models.CashSession, error) {
+	query := `SELECT ` + cashSessionColumns + ` FROM cash_sessions WHERE restaurant_id = $1 AND user_id = $2 AND status = 'open' FOR SHARE`
+	return scanCashSession(r.db.QueryRow(ctx, query, restaurantID, userID))
+}
+
+// HasClosedForSale indica si ya se cerró alguno de los turnos de la venta: el
+// que la completó o los de sus pagos. Los bloquea como GetOpenByUserForShare
+// para que no se cierren hasta el fin de la transacción.
+func (r *CashSessionRepository) HasClosedForSale(ctx context.Context, saleID uuid.UUID) (bool, error) {
+	query := `
+		SELECT status FROM cash_sessions
+		WHERE id IN (
+			SELECT cash_session_id FROM sales WHERE id = $1
+			UNION
+			SELECT cash_session_id FROM sale_payments WHERE sale_id = $1
+		)
+		FOR SHARE
+	`
+	rows, err := r.db.Query(ctx, query, saleID)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	closed := false
+	for rows.Next() {
+		var status string
+		if err := rows.Scan(&status); err != nil {
+			return false, err
+		}
+		if status != models.CashSessionOpen {
+			closed = true
+		}
+	}
+	return closed, rows.Err()
+}
+
+func (r *CashSessionRepository) List(ctx context.Context, restaurantID uuid.UUID, limit int) ([]*models.CashSession, error) {
+	query := `SELECT ` + cashSessionColumns + ` FROM cash_sessions WHERE restaurant_id = $1 ORDER BY opened_at DESC LIMIT $2`
+	rows, err := r.db.Query(ctx, query, restaurantID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*models.CashSession
+	for rows.Next() {
+		cs, err := scanCashSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, cs)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *CashSessionRepository) Close(ctx context.Context, cs *models.CashSession) error {
+	query := `
+		UPDATE cash_sessions
+		SET status = $3, expected_cash = $4, counted_cash = $5, difference = $6, notes = $7,
+		    closed_at = NOW(), closed_by = $8
+		WHERE id = $1 AND restaurant_id = $2
+		RETURNING closed_at
+	`
+	err := r.db.QueryRow(ctx, query,
+		cs.ID, cs.RestaurantID, models.CashSessionClosed, cs.ExpectedCash, cs.CountedCash,
+		cs.Difference, cs.Notes, cs.ClosedBy,
+	).Scan(&cs.ClosedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return errors.ErrNotFound
+		}
+		return err
+	}
+	cs.Status = models.CashSessionClosed
+	return nil
+}
+
+// Summary calcula el corte del turno: pagos cobrados en el turno por método
+// (sin ventas anuladas; incluye subcuentas de cuentas aún abiertas),
+// devoluciones por método y el efectivo esperado en caja
+func (r *CashSessionRepository) Summary(ctx context.Context, cs *models.CashSession) (*models.CashSessionSummary, error) {
+	summary := &models.CashSessionSummary{
+		Session:  cs,
+		Payments: []*models.PaymentMethodTotal{},
+		Refunds:  []*models.PaymentMethodTotal{},
+	}
+
+	statsQuery := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'completed'),
+			COALESCE(SUM(total) FILTER (WHERE status = 'completed'), 0),
+			COUNT(*) FILTER (WHERE status = 'cancelled'),
+			COALESCE(SUM(total) FILTER (WHERE status = 'cancelled'), 0)
+		FROM sales
+		WHERE cash_session_id = $1
+	`
+	err := r.db.QueryRow(ctx, statsQuery, cs.ID).Scan(
+		&summary.SalesCount, &summary.SalesTotal, &summary.CancelledCount, &summary.CancelledTotal,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentsQuery := `
+		SELECT sp.method, SUM(sp.amount), SUM(sp.tip), COUNT(*)
+		FROM sale_payments sp
+		JOIN sales s ON s.id = sp.sale_id
+		WHERE sp.cash_session_id = $1 AND s.status <> 'cancelled'
+		GROUP BY sp.method
+		ORDER BY sp.method
+	`
+	if summary.Payments, err = r.methodTotals(ctx, paymentsQuery, cs.ID); err != nil {
+		return nil, err
+	}
+
+	refundsQuery := `
+		SELECT rp.method, SUM(rp.amount), 0::DECIMAL(12, 2), COUNT(*)
+		FROM refund_payments rp
+		JOIN refunds rf ON rf.id = rp.refund_id
+		WHERE rf.cash_session_id = $1
+		GROUP BY rp.method
+		ORDER BY rp.method
+	`
+	if summary.Refunds, err = r.methodTotals(ctx, refundsQuery, cs.ID); err != nil {
+		return nil, err
+	}
+
+	// Las propinas en efectivo quedan en el cajón hasta que se liquidan
+	summary.ExpectedCash = cs.OpeningFloat
+	for _, p := range summary.Payments {
+		summary.TipsTotal += p.Tips
+		if p.Method == "cash" {
+			summary.ExpectedCash += p.Amount + p.Tips
+		}
+	}
+	for _, p := range summary.Refunds {
+		if p.Method == "cash" {
+			summary.ExpectedCash -= p.Amount
+		}
+	}
+	return summary, nil
+}
+
+func (r *CashSessionRepository) methodTotals(ctx context.Context, query string, sessionID uuid.UUID) ([]*models.PaymentMethodTotal, error) {
+	rows, err := r.db.Query(ctx, query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []*models.PaymentMethodTotal{}
+	for rows.Next() {
+		var t models.PaymentMethodTotal
+		if err := rows.Scan(&t.Method, &t.Amount, &t.Tips, &t.Count); err != nil {
+			return nil, err
+		}
+		totals = append(totals, &t)
+	}
+	return totals, rows.Err()
+}
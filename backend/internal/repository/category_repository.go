@@ -4,33 +4,39 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pos-saas/restaurant-pos/internal/errors"
 	"github.com/pos-saas/restaurant-pos/internal/models"
 )
 
 type CategoryRepository struct {
-	pool *pgxpool.Pool
+	db DBTX
 }
 
 func NewCategoryRepository(pool *pgxpool.Pool) *CategoryRepository {
-	return &CategoryRepository{pool: pool}
+	return &CategoryRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *CategoryRepository) WithTx(tx pgx.Tx) *CategoryRepository {
+	return &CategoryRepository{db: tx}
 }
 
 func (r *CategoryRepository) Create(ctx context.Context, c *models.Category) error {
-	query := `INSERT INTO categories (id, restaurant_id, name, description, sort_order) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.pool.Exec(ctx, query, c.ID, c.RestaurantID, c.Name, c.Description, c.SortOrder)
+	query := `INSERT INTO categories (id, restaurant_id, name, description, sort_order, tax_rate_id, station_id) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.Exec(ctx, query, c.ID, c.RestaurantID, c.Name, c.Description, c.SortOrder, c.TaxRateID, c.StationID)
 	return err
 }
 
 func (r *CategoryRepository) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.Category, error) {
 	query := `
-		SELECT id, restaurant_id, name, description, sort_order
+		SELECT id, restaurant_id, name, description, sort_order, tax_rate_id, station_id
 		FROM categories
 		WHERE restaurant_id = $1
 		ORDER BY sort_order, name
 	`
-	rows, err := r.pool.Query(ctx, query, restaurantID)
+	rows, err := r.db.Query(ctx, query, restaurantID)
 	if err != nil {
 		return nil, err
 	}
@@ -39,7 +45,7 @@ func (r *CategoryRepository) List(ctx context.Context, restaurantID uuid.UUID) (
 	var categories []*models.Category
 	for rows.Next() {
 		var cat models.Category
-		if err := rows.Scan(&cat.ID, &cat.RestaurantID, &cat.Name, &cat.Description, &cat.SortOrder); err != nil {
+		if err := rows.Scan(&cat.ID, &cat.RestaurantID, &cat.Name, &cat.Description, &cat.SortOrder, &cat.TaxRateID, &cat.StationID); err != nil {
 			return nil, err
 		}
 		categories = append(categories, &cat)
@@ -48,10 +54,10 @@ func (r *CategoryRepository) List(ctx context.Context, restaurantID uuid.UUID) (
 }
 
 func (r *CategoryRepository) GetByID(ctx context.Context, restaurantID, categoryID uuid.UUID) (*models.Category, error) {
-	query := `SELECT id, restaurant_id, name, description, sort_order FROM categories WHERE id = $1 AND restaurant_id = $2`
+	query := `SELECT id, restaurant_id, name, description, sort_order, tax_rate_id, station_id FROM categories WHERE id = $1 AND restaurant_id = $2`
 	var cat models.Category
-	err := r.pool.QueryRow(ctx, query, categoryID, restaurantID).Scan(
-		&cat.ID, &cat.RestaurantID, &cat.Name, &cat.Description, &cat.SortOrder,
+	err := r.db.QueryRow(ctx, query, categoryID, restaurantID).Scan(
+		&cat.ID, &cat.RestaurantID, &cat.Name, &cat.Description, &cat.SortOrder, &cat.TaxRateID, &cat.StationID,
 	)
 	if err != nil {
 		if isNoRows(err) {
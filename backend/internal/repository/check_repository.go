@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+// SaleCheckRepository guarda las subcuentas de las ventas divididas
+type SaleCheckRepository struct {
+	db DBTX
+}
+
+func NewSaleCheckRepository(pool *pgxpool.Pool) *SaleCheckRepository {
+	return &SaleCheckRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *SaleCheckRepository) WithTx(tx pgx.Tx) *SaleCheckRepository {
+	return &SaleCheckRepository{db: tx}
+}
+
+func (r *SaleCheckRepository) Create(ctx context.Context, c *models.SaleCheck) error {
+	query := `
+		INSERT INTO sale_checks (id, sale_id, number, tax_total, total, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at
+	`
+	return r.db.QueryRow(ctx, query, c.ID, c.SaleID, c.Number, c.TaxTotal, c.Total, c.Status).Scan(&c.CreatedAt, &c.UpdatedAt)
+}
+
+func (r *SaleCheckRepository) CreateItem(ctx context.Context, it *models.SaleCheckItem) error {
+	query := `
+		INSERT INTO sale_check_items (id, check_id, sale_item_id, share_num, share_den, tax_amount, total)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(ctx, query, it.ID, it.CheckID, it.SaleItemID, it.ShareNum, it.ShareDen, it.TaxAmount, it.Total)
+	return err
+}
+
+const checkColumns = `id, sale_id, number, tax_total, total, tip_total, status, paid_at, paid_by, created_at, updated_at`
+
+func scanCheck(row pgx.Row) (*models.SaleCheck, error) {
+	var c models.SaleCheck
+	err := row.Scan(&c.ID, &c.SaleID, &c.Number, &c.TaxTotal, &c.Total, &c.TipTotal, &c.Status,
+		&c.PaidAt, &c.PaidBy, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *SaleCheckRepository) GetByID(ctx context.Context, saleID, checkID uuid.UUID) (*models.SaleCheck, error) {
+	query := `SELECT ` + checkColumns + ` FROM sale_checks WHERE id = $1 AND sale_id = $2`
+	return scanCheck(r.db.QueryRow(ctx, query, checkID, saleID))
+}
+
+// GetByIDForUpdate bloquea la subcuenta hasta el fin de la transacción
+func (r *SaleCheckRepository) GetByIDForUpdate(ctx context.Context, saleID, checkID uuid.UUID) (*models.SaleCheck, error) {
+	query := `SELECT ` + checkColumns + ` FROM sale_checks WHERE id = $1 AND sale_id = $2 FOR UPDATE`
+	return scanCheck(r.db.QueryRow(ctx, query, checkID, saleID))
+}
+
+// ListBySale devuelve las subcuentas de la venta por número
+func (r *SaleCheckRepository) ListBySale(ctx context.Context, saleID uuid.UUID) ([]*models.SaleCheck, error) {
+	query := `SELECT ` + checkColumns + ` FROM sale_checks WHERE sale_id = $1 ORDER BY number`
+	rows, err := r.db.Query(ctx, query, saleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := []*models.SaleCheck{}
+	for rows.Next() {
+		c, err := scanCheck(rows)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, c)
+	}
+	return checks, rows.Err()
+}
+
+// ListItemsBySale devuelve las partes asignadas de todas las subcuentas de la venta
+func (r *SaleCheckRepository) ListItemsBySale(ctx context.Context, saleID uuid.UUID) ([]*models.SaleCheckItem, error) {
+	query := `
+		SELECT ci.id, ci.check_id, ci.sale_item_id, ci.share_num, ci.share_den, ci.tax_amount, ci.total
+		FROM sale_check_items ci
+		JOIN sale_checks c ON c.id = ci.check_id
+		WHERE c.sale_id = $1
+		ORDER BY c.number
+	`
+	rows, err := r.db.Query(ctx, query, saleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.SaleCheckItem
+	for rows.Next() {
+		var it models.SaleCheckItem
+		if err := rows.Scan(&it.ID, &it.CheckID, &it.SaleItemID, &it.ShareNum, &it.ShareDen, &it.TaxAmount, &it.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, &it)
+	}
+	return items, rows.Err()
+}
+
+// CountPaid devuelve cuántas subcuentas de la venta ya se cobraron y cuántas faltan
+func (r *SaleCheckRepository) CountPaid(ctx context.Context, saleID uuid.UUID) (paid, open int, err error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE status = 'paid'), COUNT(*) FILTER (WHERE status = 'open')
+		FROM sale_checks
+		WHERE sale_id = $1
+	`
+	err = r.db.QueryRow(ctx, query, saleID).Scan(&paid, &open)
+	return paid, open, err
+}
+
+// MarkPaid cierra la subcuenta; devuelve ErrConflict si ya estaba cobrada
+func (r *SaleCheckRepository) MarkPaid(ctx context.Context, c *models.SaleCheck) error {
+	query := `
+		UPDATE sale_checks SET status = 'paid', paid_at = NOW(), paid_by = $2, tip_total = $3
+		WHERE id = $1 AND status = 'open'
+		RETURNING status, paid_at, updated_at
+	`
+	err := r.db.QueryRow(ctx, query, c.ID, c.PaidBy, c.TipTotal).Scan(&c.Status, &c.PaidAt, &c.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteBySale descarta la división de la venta
+func (r *SaleCheckRepository) DeleteBySale(ctx context.Context, saleID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM sale_checks WHERE sale_id = $1`, saleID)
+	return err
+}
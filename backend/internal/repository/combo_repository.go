@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+type ComboRepository struct {
+	db DBTX
+}
+
+func NewComboRepository(pool *pgxpool.Pool) *ComboRepository {
+	return &ComboRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *ComboRepository) WithTx(tx pgx.Tx) *ComboRepository {
+	return &ComboRepository{db: tx}
+}
+
+// ListByCombos devuelve los espacios de varios combos con sus opciones,
+// agrupados por combo
+func (r *ComboRepository) ListByCombos(ctx context.Context, restaurantID uuid.UUID, comboIDs []uuid.UUID) (map[uuid.UUID][]*models.ComboSlot, error) {
+	result := make(map[uuid.UUID][]*models.ComboSlot)
+	if len(comboIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT id, restaurant_id, combo_id, name, quantity, required, sort_order, created_at
+		FROM combo_slots
+		WHERE restaurant_id = $1 AND combo_id = ANY($2)
+		ORDER BY sort_order, name
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID, comboIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slotIDs []uuid.UUID
+	byID := make(map[uuid.UUID]*models.ComboSlot)
+	for rows.Next() {
+		var sl models.ComboSlot
+		if err := rows.Scan(&sl.ID, &sl.RestaurantID, &sl.ComboID, &sl.Name, &sl.Quantity, &sl.Required, &sl.SortOrder, &sl.CreatedAt); err != nil {
+			return nil, err
+		}
+		sl.Options = []*models.ComboSlotOption{}
+		result[sl.ComboID] = append(result[sl.ComboID], &sl)
+		byID[sl.ID] = &sl
+		slotIDs = append(slotIDs, sl.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(slotIDs) == 0 {
+		return result, nil
+	}
+
+	optionsQuery := `
+		SELECT o.slot_id, o.product_id, p.name, o.upcharge, o.sort_order
+		FROM combo_slot_options o
+		JOIN products p ON p.id = o.product_id
+		WHERE o.slot_id = ANY($1)
+		ORDER BY o.sort_order, p.name
+	`
+	optRows, err := r.db.Query(ctx, optionsQuery, slotIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer optRows.Close()
+
+	for optRows.Next() {
+		var o models.ComboSlotOption
+		if err := optRows.Scan(&o.SlotID, &o.ProductID, &o.ProductName, &o.Upcharge, &o.SortOrder); err != nil {
+			return nil, err
+		}
+		byID[o.SlotID].Options = append(byID[o.SlotID].Options, &o)
+	}
+	return result, optRows.Err()
+}
+
+// SetSlots reemplaza los espacios del combo. Usar dentro de una transacción.
+func (r *ComboRepository) SetSlots(ctx context.Context, restaurantID, comboID uuid.UUID, slots []*models.ComboSlot) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM combo_slots WHERE combo_id = $1 AND restaurant_id = $2`, comboID, restaurantID); err != nil {
+		return err
+	}
+
+	slotQuery := `
+		INSERT INTO combo_slots (id, restaurant_id, combo_id, name, quantity, required, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	optionQuery := `INSERT INTO combo_slot_options (slot_id, product_id, upcharge, sort_order) VALUES ($1, $2, $3, $4)`
+	for _, sl := range slots {
+		if _, err := r.db.Exec(ctx, slotQuery, sl.ID, restaurantID, comboID, sl.Name, sl.Quantity, sl.Required, sl.SortOrder); err != nil {
+			return err
+		}
+		for _, o := range sl.Options {
+			if _, err := r.db.Exec(ctx, optionQuery, sl.ID, o.ProductID, o.Upcharge, o.SortOrder); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
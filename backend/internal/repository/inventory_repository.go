@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/quantity"
+)
+
+type InventoryRepository struct {
+	db DBTX
+}
+
+func NewInventoryRepository(pool *pgxpool.Pool) *InventoryRepository {
+	return &InventoryRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *InventoryRepository) WithTx(tx pgx.Tx) *InventoryRepository {
+	return &InventoryRepository{db: tx}
+}
+
+const ingredientColumns = `id, restaurant_id, name, unit, stock, low_stock_threshold, active, created_at, updated_at`
+
+func scanIngredient(row pgx.Row) (*models.Ingredient, error) {
+	var i models.Ingredient
+	err := row.Scan(&i.ID, &i.RestaurantID, &i.Name, &i.Unit, &i.Stock, &i.LowStockThreshold, &i.Active, &i.CreatedAt, &i.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *InventoryRepository) CreateIngredient(ctx context.Context, i *models.Ingredient) error {
+	query := `
+		INSERT INTO ingredients (id, restaurant_id, name, unit, stock, low_stock_threshold, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(ctx, query, i.ID, i.RestaurantID, i.Name, i.Unit, i.Stock, i.LowStockThreshold, i.Active)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *InventoryRepository) GetIngredient(ctx context.Context, restaurantID, ingredientID uuid.UUID) (*models.Ingredient, error) {
+	query := `SELECT ` + ingredientColumns + ` FROM ingredients WHERE id = $1 AND restaurant_id = $2`
+	return scanIngredient(r.db.QueryRow(ctx, query, ingredientID, restaurantID))
+}
+
+// GetIngredientForUpdate bloquea el insumo hasta el fin de la transacción.
+// Solo tiene sentido sobre un repositorio obtenido con WithTx.
+func (r *InventoryRepository) GetIngredientForUpdate(ctx context.Context, restaurantID, ingredientID uuid.UUID) (*models.Ingredient, error) {
+	query := `SELECT ` + ingredientColumns + ` FROM ingredients WHERE id = $1 AND restaurant_id = $2 FOR UPDATE`
+	return scanIngredient(r.db.QueryRow(ctx, query, ingredientID, restaurantID))
+}
+
+func (r *InventoryRepository) ListIngredients(ctx context.Context, restaurantID uuid.UUID) ([]*models.Ingredient, error) {
+	query := `SELECT ` + ingredientColumns + ` FROM ingredients WHERE restaurant_id = $1 ORDER BY name`
+	return r.queryIngredients(ctx, query, restaurantID)
+}
+
+// ListLowStock devuelve los insumos activos con umbral cuyo stock está en o
+// por debajo del umbral, los más faltantes primero
+func (r *InventoryRepository) ListLowStock(ctx context.Context, restaurantID uuid.UUID) ([]*models.Ingredient, error) {
+	query := `
+		SELECT ` + ingredientColumns + `
+		FROM ingredients
+		WHERE restaurant_id = $1 AND active = true AND low_stock_threshold > 0 AND stock <= low_stock_threshold
+		ORDER BY stock - low_stock_threshold, name
+	`
+	return r.queryIngredients(ctx, query, restaurantID)
+}
+
+func (r *InventoryRepository) queryIngredients(ctx context.Context, query string, args ...interface{}) ([]*models.Ingredient, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ingredients := []*models.Ingredient{}
+	for rows.Next() {
+		i, err := scanIngredient(rows)
+		if err != nil {
+			return nil, err
+		}
+		ingredients = append(ingredients, i)
+	}
+	return ingredients, rows.Err()
+}
+
+// UpdateIngredient no toca el stock; el stock solo cambia con movimientos
+func (r *InventoryRepository) UpdateIngredient(ctx context.Context, i *models.Ingredient) error {
+	query := `
+		UPDATE ingredients
+		SET name = $3, unit = $4, low_stock_threshold = $5, active = $6
+		WHERE id = $1 AND restaurant_id = $2
+	`
+	result, err := r.db.Exec(ctx, query, i.ID, i.RestaurantID, i.Name, i.Unit, i.LowStockThreshold, i.Active)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// IngredientInUse indica si el insumo está en recetas, tiene movimientos o
+// aparece en compras: todas esas cantidades están expresadas en su unidad
+func (r *InventoryRepository) IngredientInUse(ctx context.Context, ingredientID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM recipe_items WHERE ingredient_id = $1)
+		    OR EXISTS (SELECT 1 FROM stock_movements WHERE ingredient_id = $1)
+		    OR EXISTS (SELECT 1 FROM purchase_order_items WHERE ingredient_id = $1)
+		    OR EXISTS (SELECT 1 FROM goods_receipt_items WHERE ingredient_id = $1)
+	`
+	var inUse bool
+	err := r.db.QueryRow(ctx, query, ingredientID).Scan(&inUse)
+	return inUse, err
+}
+
+// DeleteIngredient devuelve ErrConflict si el insumo está en recetas o tiene movimientos
+func (r *InventoryRepository) DeleteIngredient(ctx context.Context, restaurantID, ingredientID uuid.UUID) error {
+	query := `DELETE FROM ingredients WHERE id = $1 AND restaurant_id = $2`
+	result, err := r.db.Exec(ctx, query, ingredientID, restaurantID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+const recipeColumns = `ri.id, ri.restaurant_id, ri.product_id, ri.variant_id, ri.modifier_option_id,
+		ri.ingredient_id, i.name, i.unit, ri.quantity`
+
+func (r *InventoryRepository) queryRecipe(ctx context.Context, query string, args ...interface{}) ([]*models.RecipeItem, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*models.RecipeItem{}
+	for rows.Next() {
+		var ri models.RecipeItem
+		err := rows.Scan(&ri.ID, &ri.RestaurantID, &ri.ProductID, &ri.VariantID, &ri.ModifierOptionID,
+			&ri.IngredientID, &ri.IngredientName, &ri.Unit, &ri.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, &ri)
+	}
+	return items, rows.Err()
+}
+
+// ListProductRecipe devuelve la receta del producto y la de sus variantes
+func (r *InventoryRepository) ListProductRecipe(ctx context.Context, restaurantID, productID uuid.UUID) ([]*models.RecipeItem, error) {
+	query := `
+		SELECT ` + recipeColumns + `
+		FROM recipe_items ri
+		JOIN ingredients i ON i.id = ri.ingredient_id
+		WHERE ri.restaurant_id = $1 AND ri.product_id = $2
+		ORDER BY ri.variant_id NULLS FIRST, i.name
+	`
+	return r.queryRecipe(ctx, query, restaurantID, productID)
+}
+
+func (r *InventoryRepository) ListOptionRecipe(ctx context.Context, restaurantID, optionID uuid.UUID) ([]*models.RecipeItem, error) {
+	query := `
+		SELECT ` + recipeColumns + `
+		FROM recipe_items ri
+		JOIN ingredients i ON i.id = ri.ingredient_id
+		WHERE ri.restaurant_id = $1 AND ri.modifier_option_id = $2
+		ORDER BY i.name
+	`
+	return r.queryRecipe(ctx, query, restaurantID, optionID)
+}
+
+// ListRecipesFor devuelve en una sola consulta las recetas de varios productos
+// (con sus variantes) y opciones de modificador
+func (r *InventoryRepository) ListRecipesFor(ctx context.Context, restaurantID uuid.UUID, productIDs, optionIDs []uuid.UUID) ([]*models.RecipeItem, error) {
+	if len(productIDs) == 0 && len(optionIDs) == 0 {
+		return []*models.RecipeItem{}, nil
+	}
+	query := `
+		SELECT ` + recipeColumns + `
+		FROM recipe_items ri
+		JOIN ingredients i ON i.id = ri.ingredient_id
+		WHERE ri.restaurant_id = $1 AND (ri.product_id = ANY($2) OR ri.modifier_option_id = ANY($3))
+	`
+	return r.queryRecipe(ctx, query, restaurantID, productIDs, optionIDs)
+}
+
+// SetProductRecipe reemplaza la receta del producto (variantID nil) o la de una
+// de sus variantes. Usar dentro de una transacción.
+func (r *InventoryRepository) SetProductRecipe(ctx context.Context, restaurantID, productID uuid.UUID, variantID *uuid.UUID, items []*models.RecipeItem) error {
+	query := `
+		DELETE FROM recipe_items
+		WHERE restaurant_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3
+	`
+	if _, err := r.db.Exec(ctx, query, restaurantID, productID, variantID); err != nil {
+		return err
+	}
+	return r.insertRecipe(ctx, items)
+}
+
+// SetOptionRecipe reemplaza la receta de una opción de modificador. Usar dentro
+// de una transacción.
+func (r *InventoryRepository) SetOptionRecipe(ctx context.Context, restaurantID, optionID uuid.UUID, items []*models.RecipeItem) error {
+	query := `DELETE FROM recipe_items WHERE restaurant_id = $1 AND modifier_option_id = $2`
+	if _, err := r.db.Exec(ctx, query, restaurantID, optionID); err != nil {
+		return err
+	}
+	return r.insertRecipe(ctx, items)
+}
+
+func (r *InventoryRepository) insertRecipe(ctx context.Context, items []*models.RecipeItem) error {
+	query := `
+		INSERT INTO recipe_items (id, restaurant_id, product_id, variant_id, modifier_option_id, ingredient_id, quantity)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	for _, ri := range items {
+		_, err := r.db.Exec(ctx, query, ri.ID, ri.RestaurantID, ri.ProductID, ri.VariantID, ri.ModifierOptionID, ri.IngredientID, ri.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyMovement registra el movimiento y actualiza el stock del insumo. Usar
+// dentro de una transacción; deja el insumo bloqueado hasta el commit.
+func (r *InventoryRepository) ApplyMovement(ctx context.Context, m *models.StockMovement) error {
+	query := `UPDATE ingredients SET stock = stock + $3 WHERE id = $1 AND restaurant_id = $2`
+	result, err := r.db.Exec(ctx, query, m.IngredientID, m.RestaurantID, m.Quantity)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+
+	insert := `
+		INSERT INTO stock_movements (id, restaurant_id, ingredient_id, quantity, type, reason, sale_id, goods_receipt_id, user_id)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9)
+	`
+	_, err = r.db.Exec(ctx, insert, m.ID, m.RestaurantID, m.IngredientID, m.Quantity, m.Type, m.Reason, m.SaleID, m.GoodsReceiptID, m.UserID)
+	return err
+}
+
+// NetBySale devuelve lo que la venta movió de cada insumo, descontando lo ya revertido
+func (r *InventoryRepository) NetBySale(ctx context.Context, saleID uuid.UUID) (map[uuid.UUID]quantity.Qty, error) {
+	query := `
+		SELECT ingredient_id, SUM(quantity)
+		FROM stock_movements
+		WHERE sale_id = $1 AND type IN ($2, $3)
+		GROUP BY ingredient_id
+	`
+	rows, err := r.db.Query(ctx, query, saleID, models.StockMovementSale, models.StockMovementSaleCancel)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	net := make(map[uuid.UUID]quantity.Qty)
+	for rows.Next() {
+		var id uuid.UUID
+		var q quantity.Qty
+		if err := rows.Scan(&id, &q); err != nil {
+			return nil, err
+		}
+		net[id] = q
+	}
+	return net, rows.Err()
+}
+
+// ListMovements devuelve los últimos movimientos del insumo, más recientes primero
+func (r *InventoryRepository) ListMovements(ctx context.Context, restaurantID, ingredientID uuid.UUID, limit int) ([]*models.StockMovement, error) {
+	query := `
+		SELECT id, restaurant_id, ingredient_id, quantity, type, COALESCE(reason, ''), sale_id, goods_receipt_id, user_id, created_at
+		FROM stock_movements
+		WHERE restaurant_id = $1 AND ingredient_id = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID, ingredientID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := []*models.StockMovement{}
+	for rows.Next() {
+		var m models.StockMovement
+		err := rows.Scan(&m.ID, &m.RestaurantID, &m.IngredientID, &m.Quantity, &m.Type, &m.Reason, &m.SaleID, &m.GoodsReceiptID, &m.UserID, &m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, &m)
+	}
+	return movements, rows.Err()
+}
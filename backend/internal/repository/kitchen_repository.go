@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+type KitchenRepository struct {
+	db DBTX
+}
+
+func NewKitchenRepository(pool *pgxpool.Pool) *KitchenRepository {
+	return &KitchenRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *KitchenRepository) WithTx(tx pgx.Tx) *KitchenRepository {
+	return &KitchenRepository{db: tx}
+}
+
+func (r *KitchenRepository) CreateStation(ctx context.Context, st *models.KitchenStation) error {
+	query := `
+		INSERT INTO kitchen_stations (id, restaurant_id, name, active, sort_order)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(ctx, query, st.ID, st.RestaurantID, st.Name, st.Active, st.SortOrder).Scan(&st.CreatedAt, &st.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *KitchenRepository) GetStation(ctx context.Context, restaurantID, stationID uuid.UUID) (*models.KitchenStation, error) {
+	query := `SELECT id, restaurant_id, name, active, sort_order, created_at, updated_at FROM kitchen_stations WHERE id = $1 AND restaurant_id = $2`
+	var st models.KitchenStation
+	err := r.db.QueryRow(ctx, query, stationID, restaurantID).Scan(&st.ID, &st.RestaurantID, &st.Name, &st.Active, &st.SortOrder, &st.CreatedAt, &st.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (r *KitchenRepository) ListStations(ctx context.Context, restaurantID uuid.UUID, activeOnly bool) ([]*models.KitchenStation, error) {
+	query := `
+		SELECT id, restaurant_id, name, active, sort_order, created_at, updated_at
+		FROM kitchen_stations
+		WHERE restaurant_id = $1`
+	if activeOnly {
+		query += ` AND active = true`
+	}
+	query += ` ORDER BY sort_order, name`
+
+	rows, err := r.db.Query(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stations := []*models.KitchenStation{}
+	for rows.Next() {
+		var st models.KitchenStation
+		if err := rows.Scan(&st.ID, &st.RestaurantID, &st.Name, &st.Active, &st.SortOrder, &st.CreatedAt, &st.UpdatedAt); err != nil {
+			return nil, err
+		}
+		stations = append(stations, &st)
+	}
+	return stations, rows.Err()
+}
+
+func (r *KitchenRepository) UpdateStation(ctx context.Context, st *models.KitchenStation) error {
+	query := `
+		UPDATE kitchen_stations SET name = $3, active = $4, sort_order = $5
+		WHERE id = $1 AND restaurant_id = $2
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(ctx, query, st.ID, st.RestaurantID, st.Name, st.Active, st.SortOrder).Scan(&st.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return errors.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteStation devuelve ErrConflict si la estación ya recibió comandas
+func (r *KitchenRepository) DeleteStation(ctx context.Context, restaurantID, stationID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM kitchen_stations WHERE id = $1 AND restaurant_id = $2`, stationID, restaurantID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// KitchenRoute es el nombre de un producto y la estación activa que prepara
+// sus líneas; StationID es nil si no va a cocina
+type KitchenRoute struct {
+	Name      string
+	StationID *uuid.UUID
+}
+
+// RoutesByProducts resuelve la estación de cada producto: la propia o la de
+// su categoría, siempre que esté activa
+func (r *KitchenRepository) RoutesByProducts(ctx context.Context, restaurantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]KitchenRoute, error) {
+	query := `
+		SELECT p.id, p.name, st.id
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id
+		LEFT JOIN kitchen_stations st ON st.id = COALESCE(p.station_id, c.station_id) AND st.active = true
+		WHERE p.restaurant_id = $1 AND p.id = ANY($2)
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	routes := make(map[uuid.UUID]KitchenRoute)
+	for rows.Next() {
+		var id uuid.UUID
+		var route KitchenRoute
+		if err := rows.Scan(&id, &route.Name, &route.StationID); err != nil {
+			return nil, err
+		}
+		routes[id] = route
+	}
+	return routes, rows.Err()
+}
+
+func (r *KitchenRepository) CreateTicket(ctx context.Context, t *models.KitchenTicket) error {
+	query := `
+		INSERT INTO kitchen_tickets (id, restaurant_id, station_id, sale_id, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`
+	return r.db.QueryRow(ctx, query, t.ID, t.RestaurantID, t.StationID, t.SaleID, t.Status).Scan(&t.CreatedAt, &t.UpdatedAt)
+}
+
+func (r *KitchenRepository) CreateTicketItem(ctx context.Context, it *models.KitchenTicketItem) error {
+	query := `
+		INSERT INTO kitchen_ticket_items (id, ticket_id, sale_item_id, position, name, quantity, notes, modifiers, combo_name)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, NULLIF($9, ''))
+	`
+	_, err := r.db.Exec(ctx, query, it.ID, it.TicketID, it.SaleItemID, it.Position, it.Name, it.Quantity, it.Notes, it.Modifiers, it.ComboName)
+	return err
+}
+
+// MoveTickets pasa las comandas de una cuenta a otra al unirlas
+func (r *KitchenRepository) MoveTickets(ctx context.Context, fromSaleID, toSaleID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE kitchen_tickets SET sale_id = $2 WHERE sale_id = $1`, fromSaleID, toSaleID)
+	return err
+}
+
+// RemoveSaleItemLines borra de las comandas la línea de venta y sus
+// componentes. Llamar antes de borrar la línea, que deja sale_item_id en NULL.
+// Devuelve las comandas que cambiaron.
+func (r *KitchenRepository) RemoveSaleItemLines(ctx context.Context, saleID, saleItemID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		DELETE FROM kitchen_ticket_items ki
+		USING sale_items si
+		WHERE ki.sale_item_id = si.id AND si.sale_id = $1 AND (si.id = $2 OR si.parent_item_id = $2)
+		RETURNING ki.ticket_id
+	`
+	rows, err := r.db.Query(ctx, query, saleID, saleItemID)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := collectIDs(rows)
+	if err != nil || len(ids) == 0 {
+		return ids, err
+	}
+	return ids, r.TouchTickets(ctx, ids)
+}
+
+// TouchTickets marca como modificadas las comandas cuyas líneas cambiaron; la
+// pantalla pide los cambios por updated_at
+func (r *KitchenRepository) TouchTickets(ctx context.Context, ticketIDs []uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE kitchen_tickets SET updated_at = NOW() WHERE id = ANY($1)`, ticketIDs)
+	return err
+}
+
+// SaleTicketIDs devuelve las comandas de la venta
+func (r *KitchenRepository) SaleTicketIDs(ctx context.Context, saleID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `SELECT id FROM kitchen_tickets WHERE sale_id = $1 ORDER BY created_at, id`, saleID)
+	if err != nil {
+		return nil, err
+	}
+	return collectIDs(rows)
+}
+
+// MoveTicket pasa una comanda entera a otra cuenta
+func (r *KitchenRepository) MoveTicket(ctx context.Context, ticketID, toSaleID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE kitchen_tickets SET sale_id = $2 WHERE id = $1`, ticketID, toSaleID)
+	return err
+}
+
+// CopyTicket crea en otra cuenta una comanda sin líneas con la estación, el
+// estado y las horas de la original, para repartir sus líneas entre las dos
+func (r *KitchenRepository) CopyTicket(ctx context.Context, ticketID, newID, toSaleID uuid.UUID) error {
+	query := `
+		INSERT INTO kitchen_tickets (id, restaurant_id, station_id, sale_id, status, started_at, ready_at, served_at, voided_at, created_at)
+		SELECT $2, restaurant_id, station_id, $3, status, started_at, ready_at, served_at, voided_at, created_at
+		FROM kitchen_tickets WHERE id = $1
+	`
+	result, err := r.db.Exec(ctx, query, ticketID, newID, toSaleID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// MoveTicketItem pasa una línea de comanda a otra comanda y otra línea de venta
+func (r *KitchenRepository) MoveTicketItem(ctx context.Context, itemID, ticketID, saleItemID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE kitchen_ticket_items SET ticket_id = $2, sale_item_id = $3 WHERE id = $1`, itemID, ticketID, saleItemID)
+	return err
+}
+
+func (r *KitchenRepository) UpdateTicketItemQuantity(ctx context.Context, itemID uuid.UUID, quantity int) error {
+	_, err := r.db.Exec(ctx, `UPDATE kitchen_ticket_items SET quantity = $2 WHERE id = $1`, itemID, quantity)
+	return err
+}
+
+// VoidEmptyTickets anula las comandas indicadas que se quedaron sin líneas y
+// aún no se sirvieron
+func (r *KitchenRepository) VoidEmptyTickets(ctx context.Context, ticketIDs []uuid.UUID) error {
+	query := `
+		UPDATE kitchen_tickets t SET status = 'voided', voided_at = NOW()
+		WHERE t.id = ANY($1) AND t.status <> 'served'
+		  AND NOT EXISTS (SELECT 1 FROM kitchen_ticket_items ki WHERE ki.ticket_id = t.id)
+	`
+	_, err := r.db.Exec(ctx, query, ticketIDs)
+	return err
+}
+
+// VoidSaleTickets anula las comandas de la venta que aún no se sirvieron y
+// devuelve sus IDs
+func (r *KitchenRepository) VoidSaleTickets(ctx context.Context, saleID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		UPDATE kitchen_tickets SET status = 'voided', voided_at = NOW()
+		WHERE sale_id = $1 AND status NOT IN ('served', 'voided')
+		RETURNING id
+	`
+	rows, err := r.db.Query(ctx, query, saleID)
+	if err != nil {
+		return nil, err
+	}
+	return collectIDs(rows)
+}
+
+// collectIDs lee una columna de UUID sin repetir
+func collectIDs(rows pgx.Rows) ([]uuid.UUID, error) {
+	defer rows.Close()
+	seen := make(map[uuid.UUID]bool)
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
+
+const ticketColumns = `t.id, t.restaurant_id, t.station_id, st.name, t.sale_id, COALESCE(rt.name, ''), t.status,
+		t.started_at, t.ready_at, t.served_at, t.voided_at, t.created_at, t.updated_at`
+
+const ticketFrom = `
+		FROM kitchen_tickets t
+		JOIN kitchen_stations st ON st.id = t.station_id
+		JOIN sales s ON s.id = t.sale_id
+		LEFT JOIN restaurant_tables rt ON rt.id = s.table_id`
+
+func scanTicket(row pgx.Row) (*models.KitchenTicket, error) {
+	var t models.KitchenTicket
+	err := row.Scan(&t.ID, &t.RestaurantID, &t.StationID, &t.StationName, &t.SaleID, &t.TableName, &t.Status,
+		&t.StartedAt, &t.ReadyAt, &t.ServedAt, &t.VoidedAt, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *KitchenRepository) GetTicket(ctx context.Context, restaurantID, ticketID uuid.UUID) (*models.KitchenTicket, error) {
+	query := `SELECT ` + ticketColumns + ticketFrom + ` WHERE t.id = $1 AND t.restaurant_id = $2`
+	return scanTicket(r.db.QueryRow(ctx, query, ticketID, restaurantID))
+}
+
+// GetTicketForUpdate bloquea la comanda hasta el fin de la transacción
+func (r *KitchenRepository) GetTicketForUpdate(ctx context.Context, restaurantID, ticketID uuid.UUID) (*models.KitchenTicket, error) {
+	query := `SELECT ` + ticketColumns + ticketFrom + ` WHERE t.id = $1 AND t.restaurant_id = $2 FOR UPDATE OF t`
+	return scanTicket(r.db.QueryRow(ctx, query, ticketID, restaurantID))
+}
+
+// KitchenTicketFilter agrupa los filtros de la pantalla de cocina. Sin Statuses
+// ni UpdatedSince se devuelven las comandas pendientes (ni servidas ni anuladas).
+type KitchenTicketFilter struct {
+	StationID    *uuid.UUID
+	Statuses     []string
+	UpdatedSince *time.Time
+	Limit        int
+}
+
+// ListTickets devuelve las comandas de la más antigua a la más nueva
+func (r *KitchenRepository) ListTickets(ctx context.Context, restaurantID uuid.UUID, f KitchenTicketFilter) ([]*models.KitchenTicket, error) {
+	query := `SELECT ` + ticketColumns + ticketFrom + ` WHERE t.restaurant_id = $1`
+	args := []interface{}{restaurantID}
+	argNum := 2
+
+	if f.StationID != nil {
+		query += fmt.Sprintf(" AND t.station_id = $%d", argNum)
+		args = append(args, *f.StationID)
+		argNum++
+	}
+	if len(f.Statuses) > 0 {
+		query += fmt.Sprintf(" AND t.status = ANY($%d)", argNum)
+		args = append(args, f.Statuses)
+		argNum++
+	}
+	if f.UpdatedSince != nil {
+		query += fmt.Sprintf(" AND t.updated_at > $%d", argNum)
+		args = append(args, *f.UpdatedSince)
+		argNum++
+	} else if len(f.Statuses) == 0 {
+		query += " AND t.status NOT IN ('served', 'voided')"
+	}
+	query += fmt.Sprintf(" ORDER BY t.created_at, t.id LIMIT $%d", argNum)
+	args = append(args, f.Limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tickets := []*models.KitchenTicket{}
+	for rows.Next() {
+		t, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, t)
+	}
+	return tickets, rows.Err()
+}
+
+// ListTicketItems devuelve las líneas de las comandas agrupadas por comanda,
+// en el orden en que se pidieron
+func (r *KitchenRepository) ListTicketItems(ctx context.Context, ticketIDs []uuid.UUID) (map[uuid.UUID][]*models.KitchenTicketItem, error) {
+	query := `
+		SELECT id, ticket_id, sale_item_id, position, name, quantity, COALESCE(notes, ''), modifiers, COALESCE(combo_name, '')
+		FROM kitchen_ticket_items
+		WHERE ticket_id = ANY($1)
+		ORDER BY ticket_id, position
+	`
+	rows, err := r.db.Query(ctx, query, ticketIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make(map[uuid.UUID][]*models.KitchenTicketItem)
+	for rows.Next() {
+		var it models.KitchenTicketItem
+		if err := rows.Scan(&it.ID, &it.TicketID, &it.SaleItemID, &it.Position, &it.Name, &it.Quantity, &it.Notes, &it.Modifiers, &it.ComboName); err != nil {
+			return nil, err
+		}
+		items[it.TicketID] = append(items[it.TicketID], &it)
+	}
+	return items, rows.Err()
+}
+
+// UpdateTicketStatus guarda el estado y la hora en que se alcanzó
+func (r *KitchenRepository) UpdateTicketStatus(ctx context.Context, t *models.KitchenTicket) error {
+	query := `
+		UPDATE kitchen_tickets SET status = $3, started_at = $4, ready_at = $5, served_at = $6
+		WHERE id = $1 AND restaurant_id = $2
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(ctx, query, t.ID, t.RestaurantID, t.Status, t.StartedAt, t.ReadyAt, t.ServedAt).Scan(&t.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return errors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
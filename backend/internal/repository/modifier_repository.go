@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+type ModifierRepository struct {
+	db DBTX
+}
+
+func NewModifierRepository(pool *pgxpool.Pool) *ModifierRepository {
+	return &ModifierRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *ModifierRepository) WithTx(tx pgx.Tx) *ModifierRepository {
+	return &ModifierRepository{db: tx}
+}
+
+func (r *ModifierRepository) CreateGroup(ctx context.Context, g *models.ModifierGroup) error {
+	query := `
+		INSERT INTO modifier_groups (id, restaurant_id, name, min_select, max_select, required, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(ctx, query, g.ID, g.RestaurantID, g.Name, g.MinSelect, g.MaxSelect, g.Required, g.SortOrder)
+	return err
+}
+
+func (r *ModifierRepository) UpdateGroup(ctx context.Context, g *models.ModifierGroup) error {
+	query := `
+		UPDATE modifier_groups
+		SET name = $3, min_select = $4, max_select = $5, required = $6, sort_order = $7
+		WHERE id = $1 AND restaurant_id = $2
+	`
+	result, err := r.db.Exec(ctx, query, g.ID, g.RestaurantID, g.Name, g.MinSelect, g.MaxSelect, g.Required, g.SortOrder)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteGroup borra el grupo con sus opciones; las ventas conservan su copia
+func (r *ModifierRepository) DeleteGroup(ctx context.Context, restaurantID, groupID uuid.UUID) error {
+	query := `DELETE FROM modifier_groups WHERE id = $1 AND restaurant_id = $2`
+	result, err := r.db.Exec(ctx, query, groupID, restaurantID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ModifierRepository) GetGroup(ctx context.Context, restaurantID, groupID uuid.UUID) (*models.ModifierGroup, error) {
+	query := `
+		SELECT id, restaurant_id, name, min_select, max_select, required, sort_order, created_at, updated_at
+		FROM modifier_groups
+		WHERE id = $1 AND restaurant_id = $2
+	`
+	var g models.ModifierGroup
+	err := r.db.QueryRow(ctx, query, groupID, restaurantID).Scan(
+		&g.ID, &g.RestaurantID, &g.Name, &g.MinSelect, &g.MaxSelect, &g.Required, &g.SortOrder, &g.CreatedAt, &g.UpdatedAt,
+	)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	if err := r.loadOptions(ctx, []*models.ModifierGroup{&g}); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// ListGroups devuelve los grupos del restaurante con todas sus opciones
+func (r *ModifierRepository) ListGroups(ctx context.Context, restaurantID uuid.UUID) ([]*models.ModifierGroup, error) {
+	query := `
+		SELECT id, restaurant_id, name, min_select, max_select, required, sort_order, created_at, updated_at
+		FROM modifier_groups
+		WHERE restaurant_id = $1
+		ORDER BY sort_order, name
+	`
+	return r.queryGroups(ctx, query, restaurantID)
+}
+
+// ListByProduct devuelve los grupos asignados al producto en su orden
+func (r *ModifierRepository) ListByProduct(ctx context.Context, restaurantID, productID uuid.UUID) ([]*models.ModifierGroup, error) {
+	query := `
+		SELECT g.id, g.restaurant_id, g.name, g.min_select, g.max_select, g.required, g.sort_order, g.created_at, g.updated_at
+		FROM product_modifier_groups pmg
+		JOIN modifier_groups g ON g.id = pmg.group_id
+		WHERE g.restaurant_id = $1 AND pmg.product_id = $2
+		ORDER BY pmg.sort_order, g.name
+	`
+	return r.queryGroups(ctx, query, restaurantID, productID)
+}
+
+func (r *ModifierRepository) queryGroups(ctx context.Context, query string, args ...interface{}) ([]*models.ModifierGroup, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []*models.ModifierGroup{}
+	for rows.Next() {
+		var g models.ModifierGroup
+		if err := rows.Scan(&g.ID, &g.RestaurantID, &g.Name, &g.MinSelect, &g.MaxSelect, &g.Required, &g.SortOrder, &g.CreatedAt, &g.UpdatedAt); err != nil {
+			return nil, err
+		}
+		groups = append(groups, &g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.loadOptions(ctx, groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// loadOptions carga las opciones de varios grupos en una sola consulta
+func (r *ModifierRepository) loadOptions(ctx context.Context, groups []*models.ModifierGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(groups))
+	byID := make(map[uuid.UUID]*models.ModifierGroup, len(groups))
+	for i, g := range groups {
+		ids[i] = g.ID
+		g.Options = []*models.ModifierOption{}
+		byID[g.ID] = g
+	}
+
+	query := `
+		SELECT id, group_id, name, price, active, sort_order
+		FROM modifier_options
+		WHERE group_id = ANY($1)
+		ORDER BY sort_order, name
+	`
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o models.ModifierOption
+		if err := rows.Scan(&o.ID, &o.GroupID, &o.Name, &o.Price, &o.Active, &o.SortOrder); err != nil {
+			return err
+		}
+		byID[o.GroupID].Options = append(byID[o.GroupID].Options, &o)
+	}
+	return rows.Err()
+}
+
+func (r *ModifierRepository) CreateOption(ctx context.Context, o *models.ModifierOption) error {
+	query := `INSERT INTO modifier_options (id, group_id, name, price, active, sort_order) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.Exec(ctx, query, o.ID, o.GroupID, o.Name, o.Price, o.Active, o.SortOrder)
+	return err
+}
+
+func (r *ModifierRepository) UpdateOption(ctx context.Context, o *models.ModifierOption) error {
+	query := `UPDATE modifier_options SET name = $3, price = $4, active = $5, sort_order = $6 WHERE id = $1 AND group_id = $2`
+	result, err := r.db.Exec(ctx, query, o.ID, o.GroupID, o.Name, o.Price, o.Active, o.SortOrder)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ModifierRepository) DeleteOption(ctx context.Context, groupID, optionID uuid.UUID) error {
+	query := `DELETE FROM modifier_options WHERE id = $1 AND group_id = $2`
+	result, err := r.db.Exec(ctx, query, optionID, groupID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// SetProductGroups reemplaza los grupos asignados al producto. Usar dentro de
+// una transacción.
+func (r *ModifierRepository) SetProductGroups(ctx context.Context, productID uuid.UUID, groupIDs []uuid.UUID) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM product_modifier_groups WHERE product_id = $1`, productID); err != nil {
+		return err
+	}
+	query := `INSERT INTO product_modifier_groups (product_id, group_id, sort_order) VALUES ($1, $2, $3)`
+	for i, groupID := range groupIDs {
+		if _, err := r.db.Exec(ctx, query, productID, groupID, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
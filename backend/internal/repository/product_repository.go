@@ -5,41 +5,47 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pos-saas/restaurant-pos/internal/errors"
 	"github.com/pos-saas/restaurant-pos/internal/models"
 )
 
 type ProductRepository struct {
-	pool *pgxpool.Pool
+	db DBTX
 }
 
 func NewProductRepository(pool *pgxpool.Pool) *ProductRepository {
-	return &ProductRepository{pool: pool}
+	return &ProductRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *ProductRepository) WithTx(tx pgx.Tx) *ProductRepository {
+	return &ProductRepository{db: tx}
 }
 
 func (r *ProductRepository) Create(ctx context.Context, p *models.Product) error {
 	query := `
-		INSERT INTO products (id, restaurant_id, category_id, name, description, price, image_url, active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO products (id, restaurant_id, category_id, name, description, price, image_url, active, tax_rate_id, product_type, station_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
-	_, err := r.pool.Exec(ctx, query,
+	_, err := r.db.Exec(ctx, query,
 		p.ID, p.RestaurantID, p.CategoryID, p.Name, p.Description,
-		p.Price, p.ImageURL, p.Active,
+		p.Price, p.ImageURL, p.Active, p.TaxRateID, p.Type, p.StationID,
 	)
 	return err
 }
 
 func (r *ProductRepository) GetByID(ctx context.Context, restaurantID, productID uuid.UUID) (*models.Product, error) {
 	query := `
-		SELECT id, restaurant_id, category_id, name, description, price, image_url, active, created_at, updated_at
+		SELECT id, restaurant_id, category_id, name, description, price, image_url, active, tax_rate_id, product_type, station_id, created_at, updated_at
 		FROM products
 		WHERE id = $1 AND restaurant_id = $2
 	`
 	var p models.Product
-	err := r.pool.QueryRow(ctx, query, productID, restaurantID).Scan(
+	err := r.db.QueryRow(ctx, query, productID, restaurantID).Scan(
 		&p.ID, &p.RestaurantID, &p.CategoryID, &p.Name, &p.Description,
-		&p.Price, &p.ImageURL, &p.Active, &p.CreatedAt, &p.UpdatedAt,
+		&p.Price, &p.ImageURL, &p.Active, &p.TaxRateID, &p.Type, &p.StationID, &p.CreatedAt, &p.UpdatedAt,
 	)
 	if err != nil {
 		if isNoRows(err) {
@@ -52,7 +58,7 @@ func (r *ProductRepository) GetByID(ctx context.Context, restaurantID, productID
 
 func (r *ProductRepository) List(ctx context.Context, restaurantID uuid.UUID, categoryID *uuid.UUID, activeOnly bool) ([]*models.Product, error) {
 	query := `
-		SELECT id, restaurant_id, category_id, name, description, price, image_url, active, created_at, updated_at
+		SELECT id, restaurant_id, category_id, name, description, price, image_url, active, tax_rate_id, product_type, station_id, created_at, updated_at
 		FROM products
 		WHERE restaurant_id = $1
 	`
@@ -69,7 +75,7 @@ func (r *ProductRepository) List(ctx context.Context, restaurantID uuid.UUID, ca
 	}
 	query += " ORDER BY name"
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +85,7 @@ func (r *ProductRepository) List(ctx context.Context, restaurantID uuid.UUID, ca
 	for rows.Next() {
 		var p models.Product
 		err := rows.Scan(&p.ID, &p.RestaurantID, &p.CategoryID, &p.Name, &p.Description,
-			&p.Price, &p.ImageURL, &p.Active, &p.CreatedAt, &p.UpdatedAt)
+			&p.Price, &p.ImageURL, &p.Active, &p.TaxRateID, &p.Type, &p.StationID, &p.CreatedAt, &p.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -91,11 +97,11 @@ func (r *ProductRepository) List(ctx context.Context, restaurantID uuid.UUID, ca
 func (r *ProductRepository) Update(ctx context.Context, p *models.Product) error {
 	query := `
 		UPDATE products
-		SET category_id = $2, name = $3, description = $4, price = $5, image_url = $6, active = $7
+		SET category_id = $2, name = $3, description = $4, price = $5, image_url = $6, active = $7, tax_rate_id = $9, product_type = $10, station_id = $11
 		WHERE id = $1 AND restaurant_id = $8
 	`
-	result, err := r.pool.Exec(ctx, query,
-		p.ID, p.CategoryID, p.Name, p.Description, p.Price, p.ImageURL, p.Active, p.RestaurantID,
+	result, err := r.db.Exec(ctx, query,
+		p.ID, p.CategoryID, p.Name, p.Description, p.Price, p.ImageURL, p.Active, p.RestaurantID, p.TaxRateID, p.Type, p.StationID,
 	)
 	if err != nil {
 		return err
@@ -108,7 +114,7 @@ func (r *ProductRepository) Update(ctx context.Context, p *models.Product) error
 
 func (r *ProductRepository) Delete(ctx context.Context, restaurantID, productID uuid.UUID) error {
 	query := `DELETE FROM products WHERE id = $1 AND restaurant_id = $2`
-	result, err := r.pool.Exec(ctx, query, productID, restaurantID)
+	result, err := r.db.Exec(ctx, query, productID, restaurantID)
 	if err != nil {
 		return err
 	}
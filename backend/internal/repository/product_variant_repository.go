@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+type ProductVariantRepository struct {
+	db DBTX
+}
+
+func NewProductVariantRepository(pool *pgxpool.Pool) *ProductVariantRepository {
+	return &ProductVariantRepository{db: pool}
+}
+
+const variantColumns = `id, restaurant_id, product_id, name, COALESCE(sku, ''), price, active, sort_order, created_at, updated_at`
+
+func (r *ProductVariantRepository) Create(ctx context.Context, v *models.ProductVariant) error {
+	query := `
+		INSERT INTO product_variants (id, restaurant_id, product_id, name, sku, price, active, sort_order)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)
+	`
+	_, err := r.db.Exec(ctx, query, v.ID, v.RestaurantID, v.ProductID, v.Name, v.SKU, v.Price, v.Active, v.SortOrder)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *ProductVariantRepository) GetByID(ctx context.Context, restaurantID, variantID uuid.UUID) (*models.ProductVariant, error) {
+	query := `SELECT ` + variantColumns + ` FROM product_variants WHERE id = $1 AND restaurant_id = $2`
+	var v models.ProductVariant
+	err := r.db.QueryRow(ctx, query, variantID, restaurantID).Scan(
+		&v.ID, &v.RestaurantID, &v.ProductID, &v.Name, &v.SKU, &v.Price, &v.Active, &v.SortOrder, &v.CreatedAt, &v.UpdatedAt,
+	)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ListByProducts devuelve las variantes de varios productos agrupadas por producto
+func (r *ProductVariantRepository) ListByProducts(ctx context.Context, restaurantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID][]*models.ProductVariant, error) {
+	result := make(map[uuid.UUID][]*models.ProductVariant)
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT ` + variantColumns + `
+		FROM product_variants
+		WHERE restaurant_id = $1 AND product_id = ANY($2)
+		ORDER BY sort_order, price, name
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v models.ProductVariant
+		err := rows.Scan(&v.ID, &v.RestaurantID, &v.ProductID, &v.Name, &v.SKU, &v.Price, &v.Active, &v.SortOrder, &v.CreatedAt, &v.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		result[v.ProductID] = append(result[v.ProductID], &v)
+	}
+	return result, rows.Err()
+}
+
+func (r *ProductVariantRepository) Update(ctx context.Context, v *models.ProductVariant) error {
+	query := `
+		UPDATE product_variants
+		SET name = $3, sku = NULLIF($4, ''), price = $5, active = $6, sort_order = $7
+		WHERE id = $1 AND restaurant_id = $2
+	`
+	result, err := r.db.Exec(ctx, query, v.ID, v.RestaurantID, v.Name, v.SKU, v.Price, v.Active, v.SortOrder)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ProductVariantRepository) Delete(ctx context.Context, restaurantID, variantID uuid.UUID) error {
+	query := `DELETE FROM product_variants WHERE id = $1 AND restaurant_id = $2`
+	result, err := r.db.Exec(ctx, query, variantID, restaurantID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,491 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+	"github.com/pos-saas/restaurant-pos/internal/quantity"
+)
+
+type PurchasingRepository struct {
+	db DBTX
+}
+
+func NewPurchasingRepository(pool *pgxpool.Pool) *PurchasingRepository {
+	return &PurchasingRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *PurchasingRepository) WithTx(tx pgx.Tx) *PurchasingRepository {
+	return &PurchasingRepository{db: tx}
+}
+
+const supplierColumns = `id, restaurant_id, name, COALESCE(contact_name, ''), COALESCE(phone, ''),
+		COALESCE(email, ''), COALESCE(notes, ''), active, created_at, updated_at`
+
+func scanSupplier(row pgx.Row) (*models.Supplier, error) {
+	var s models.Supplier
+	err := row.Scan(&s.ID, &s.RestaurantID, &s.Name, &s.ContactName, &s.Phone, &s.Email, &s.Notes, &s.Active, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *PurchasingRepository) CreateSupplier(ctx context.Context, s *models.Supplier) error {
+	query := `
+		INSERT INTO suppliers (id, restaurant_id, name, contact_name, phone, email, notes, active)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8)
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(ctx, query,
+		s.ID, s.RestaurantID, s.Name, s.ContactName, s.Phone, s.Email, s.Notes, s.Active,
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PurchasingRepository) GetSupplier(ctx context.Context, restaurantID, supplierID uuid.UUID) (*models.Supplier, error) {
+	query := `SELECT ` + supplierColumns + ` FROM suppliers WHERE id = $1 AND restaurant_id = $2`
+	return scanSupplier(r.db.QueryRow(ctx, query, supplierID, restaurantID))
+}
+
+func (r *PurchasingRepository) ListSuppliers(ctx context.Context, restaurantID uuid.UUID, activeOnly bool) ([]*models.Supplier, error) {
+	query := `SELECT ` + supplierColumns + ` FROM suppliers WHERE restaurant_id = $1`
+	if activeOnly {
+		query += ` AND active = true`
+	}
+	query += ` ORDER BY name`
+
+	rows, err := r.db.Query(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := []*models.Supplier{}
+	for rows.Next() {
+		s, err := scanSupplier(rows)
+		if err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, s)
+	}
+	return suppliers, rows.Err()
+}
+
+func (r *PurchasingRepository) UpdateSupplier(ctx context.Context, s *models.Supplier) error {
+	query := `
+		UPDATE suppliers
+		SET name = $3, contact_name = NULLIF($4, ''), phone = NULLIF($5, ''), email = NULLIF($6, ''),
+			notes = NULLIF($7, ''), active = $8
+		WHERE id = $1 AND restaurant_id = $2
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(ctx, query,
+		s.ID, s.RestaurantID, s.Name, s.ContactName, s.Phone, s.Email, s.Notes, s.Active,
+	).Scan(&s.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return errors.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteSupplier devuelve ErrConflict si el proveedor tiene órdenes de compra
+func (r *PurchasingRepository) DeleteSupplier(ctx context.Context, restaurantID, supplierID uuid.UUID) error {
+	query := `DELETE FROM suppliers WHERE id = $1 AND restaurant_id = $2`
+	result, err := r.db.Exec(ctx, query, supplierID, restaurantID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+const purchaseOrderColumns = `po.id, po.restaurant_id, po.supplier_id, s.name, po.status, po.total,
+		COALESCE(po.notes, ''), po.expected_at, po.created_by, po.cancelled_at, po.created_at, po.updated_at`
+
+func scanPurchaseOrder(row pgx.Row) (*models.PurchaseOrder, error) {
+	var po models.PurchaseOrder
+	err := row.Scan(
+		&po.ID, &po.RestaurantID, &po.SupplierID, &po.SupplierName, &po.Status, &po.Total,
+		&po.Notes, &po.ExpectedAt, &po.CreatedBy, &po.CancelledAt, &po.CreatedAt, &po.UpdatedAt,
+	)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &po, nil
+}
+
+// CreateOrder inserta la orden con sus líneas. Usar dentro de una transacción.
+func (r *PurchasingRepository) CreateOrder(ctx context.Context, po *models.PurchaseOrder) error {
+	query := `
+		INSERT INTO purchase_orders (id, restaurant_id, supplier_id, status, total, notes, expected_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(ctx, query,
+		po.ID, po.RestaurantID, po.SupplierID, po.Status, po.Total, po.Notes, po.ExpectedAt, po.CreatedBy,
+	).Scan(&po.CreatedAt, &po.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	itemQuery := `
+		INSERT INTO purchase_order_items (id, purchase_order_id, ingredient_id, quantity, unit_cost)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, item := range po.Items {
+		_, err := r.db.Exec(ctx, itemQuery, item.ID, po.ID, item.IngredientID, item.Quantity, item.UnitCost)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PurchasingRepository) GetOrder(ctx context.Context, restaurantID, orderID uuid.UUID) (*models.PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders po
+		JOIN suppliers s ON s.id = po.supplier_id
+		WHERE po.id = $1 AND po.restaurant_id = $2
+	`
+	return scanPurchaseOrder(r.db.QueryRow(ctx, query, orderID, restaurantID))
+}
+
+// GetOrderForUpdate bloquea la orden hasta el fin de la transacción para que dos
+// recepciones simultáneas no reciban de más
+func (r *PurchasingRepository) GetOrderForUpdate(ctx context.Context, restaurantID, orderID uuid.UUID) (*models.PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders po
+		JOIN suppliers s ON s.id = po.supplier_id
+		WHERE po.id = $1 AND po.restaurant_id = $2
+		FOR UPDATE OF po
+	`
+	return scanPurchaseOrder(r.db.QueryRow(ctx, query, orderID, restaurantID))
+}
+
+// PurchaseOrderListFilter agrupa los filtros del listado de órdenes. Los campos
+// vacíos no filtran.
+type PurchaseOrderListFilter struct {
+	Status     string
+	SupplierID *uuid.UUID
+	Limit      int
+}
+
+// ListOrders devuelve las órdenes sin líneas, más recientes primero
+func (r *PurchasingRepository) ListOrders(ctx context.Context, restaurantID uuid.UUID, f PurchaseOrderListFilter) ([]*models.PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders po
+		JOIN suppliers s ON s.id = po.supplier_id
+		WHERE po.restaurant_id = $1`
+	args := []interface{}{restaurantID}
+	argNum := 2
+
+	if f.Status != "" {
+		query += fmt.Sprintf(" AND po.status = $%d", argNum)
+		args = append(args, f.Status)
+		argNum++
+	}
+	if f.SupplierID != nil {
+		query += fmt.Sprintf(" AND po.supplier_id = $%d", argNum)
+		args = append(args, *f.SupplierID)
+		argNum++
+	}
+	query += fmt.Sprintf(" ORDER BY po.created_at DESC, po.id DESC LIMIT $%d", argNum)
+	args = append(args, f.Limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []*models.PurchaseOrder{}
+	for rows.Next() {
+		po, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, po)
+	}
+	return orders, rows.Err()
+}
+
+func (r *PurchasingRepository) ListOrderItems(ctx context.Context, orderID uuid.UUID) ([]*models.PurchaseOrderItem, error) {
+	query := `
+		SELECT poi.id, poi.purchase_order_id, poi.ingredient_id, i.name, i.unit, poi.quantity,
+			poi.unit_cost, poi.received_quantity
+		FROM purchase_order_items poi
+		JOIN ingredients i ON i.id = poi.ingredient_id
+		WHERE poi.purchase_order_id = $1
+		ORDER BY i.name
+	`
+	rows, err := r.db.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*models.PurchaseOrderItem{}
+	for rows.Next() {
+		var item models.PurchaseOrderItem
+		err := rows.Scan(&item.ID, &item.PurchaseOrderID, &item.IngredientID, &item.IngredientName, &item.Unit,
+			&item.Quantity, &item.UnitCost, &item.ReceivedQuantity)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
+// AddReceived suma lo recibido a la línea de la orden
+func (r *PurchasingRepository) AddReceived(ctx context.Context, itemID uuid.UUID, qty quantity.Qty) error {
+	query := `UPDATE purchase_order_items SET received_quantity = received_quantity + $2 WHERE id = $1`
+	result, err := r.db.Exec(ctx, query, itemID, qty)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PurchasingRepository) UpdateOrderStatus(ctx context.Context, restaurantID, orderID uuid.UUID, status string) error {
+	query := `
+		UPDATE purchase_orders
+		SET status = $3, cancelled_at = CASE WHEN $3 = 'cancelled' THEN NOW() ELSE cancelled_at END
+		WHERE id = $1 AND restaurant_id = $2
+	`
+	result, err := r.db.Exec(ctx, query, orderID, restaurantID, status)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// CreateReceipt registra la entrega con sus líneas del historial de costos.
+// Usar dentro de una transacción.
+func (r *PurchasingRepository) CreateReceipt(ctx context.Context, gr *models.GoodsReceipt) error {
+	query := `
+		INSERT INTO goods_receipts (id, restaurant_id, purchase_order_id, received_by, total, notes)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+		RETURNING created_at
+	`
+	err := r.db.QueryRow(ctx, query,
+		gr.ID, gr.RestaurantID, gr.PurchaseOrderID, gr.ReceivedBy, gr.Total, gr.Notes,
+	).Scan(&gr.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	itemQuery := `
+		INSERT INTO goods_receipt_items (id, restaurant_id, goods_receipt_id, purchase_order_item_id,
+			ingredient_id, quantity, unit_cost, total_cost, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	for _, item := range gr.Items {
+		item.CreatedAt = gr.CreatedAt
+		_, err := r.db.Exec(ctx, itemQuery,
+			item.ID, gr.RestaurantID, gr.ID, item.PurchaseOrderItemID,
+			item.IngredientID, item.Quantity, item.UnitCost, item.TotalCost, item.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListReceipts devuelve las entregas de una orden con sus líneas, en orden de llegada
+func (r *PurchasingRepository) ListReceipts(ctx context.Context, orderID uuid.UUID) ([]*models.GoodsReceipt, error) {
+	query := `
+		SELECT id, restaurant_id, purchase_order_id, received_by, total, COALESCE(notes, ''), created_at
+		FROM goods_receipts
+		WHERE purchase_order_id = $1
+		ORDER BY created_at, id
+	`
+	rows, err := r.db.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := []*models.GoodsReceipt{}
+	byID := make(map[uuid.UUID]*models.GoodsReceipt)
+	for rows.Next() {
+		var gr models.GoodsReceipt
+		err := rows.Scan(&gr.ID, &gr.RestaurantID, &gr.PurchaseOrderID, &gr.ReceivedBy, &gr.Total, &gr.Notes, &gr.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		gr.Items = []*models.GoodsReceiptItem{}
+		receipts = append(receipts, &gr)
+		byID[gr.ID] = &gr
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(receipts) == 0 {
+		return receipts, nil
+	}
+
+	itemQuery := `
+		SELECT ` + receiptItemColumns + `
+		FROM goods_receipt_items gri
+		JOIN ingredients i ON i.id = gri.ingredient_id
+		WHERE gri.goods_receipt_id IN (SELECT id FROM goods_receipts WHERE purchase_order_id = $1)
+		ORDER BY i.name
+	`
+	items, err := r.queryReceiptItems(ctx, itemQuery, false, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if gr, ok := byID[item.GoodsReceiptID]; ok {
+			gr.Items = append(gr.Items, item)
+		}
+	}
+	return receipts, nil
+}
+
+const receiptItemColumns = `gri.id, gri.restaurant_id, gri.goods_receipt_id, gri.purchase_order_item_id,
+		gri.ingredient_id, i.name, i.unit, gri.quantity, gri.unit_cost, gri.total_cost, gri.created_at`
+
+func (r *PurchasingRepository) queryReceiptItems(ctx context.Context, query string, withSupplier bool, args ...interface{}) ([]*models.GoodsReceiptItem, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*models.GoodsReceiptItem{}
+	for rows.Next() {
+		var item models.GoodsReceiptItem
+		dest := []interface{}{
+			&item.ID, &item.RestaurantID, &item.GoodsReceiptID, &item.PurchaseOrderItemID,
+			&item.IngredientID, &item.IngredientName, &item.Unit, &item.Quantity, &item.UnitCost,
+			&item.TotalCost, &item.CreatedAt,
+		}
+		if withSupplier {
+			dest = append(dest, &item.PurchaseOrderID, &item.SupplierID, &item.SupplierName)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
+// PurchaseLedgerFilter agrupa los filtros del historial de costos. Los campos
+// nil no filtran; el rango es [From, To).
+type PurchaseLedgerFilter struct {
+	IngredientID *uuid.UUID
+	SupplierID   *uuid.UUID
+	From         *time.Time
+	To           *time.Time
+	Limit        int
+}
+
+// ListLedger devuelve el historial de lo recibido por insumo, más reciente primero
+func (r *PurchasingRepository) ListLedger(ctx context.Context, restaurantID uuid.UUID, f PurchaseLedgerFilter) ([]*models.GoodsReceiptItem, error) {
+	query := `
+		SELECT ` + receiptItemColumns + `, gr.purchase_order_id, po.supplier_id, s.name
+		FROM goods_receipt_items gri
+		JOIN ingredients i ON i.id = gri.ingredient_id
+		JOIN goods_receipts gr ON gr.id = gri.goods_receipt_id
+		JOIN purchase_orders po ON po.id = gr.purchase_order_id
+		JOIN suppliers s ON s.id = po.supplier_id
+		WHERE gri.restaurant_id = $1`
+	args := []interface{}{restaurantID}
+	argNum := 2
+
+	if f.IngredientID != nil {
+		query += fmt.Sprintf(" AND gri.ingredient_id = $%d", argNum)
+		args = append(args, *f.IngredientID)
+		argNum++
+	}
+	if f.SupplierID != nil {
+		query += fmt.Sprintf(" AND po.supplier_id = $%d", argNum)
+		args = append(args, *f.SupplierID)
+		argNum++
+	}
+	if f.From != nil {
+		query += fmt.Sprintf(" AND gri.created_at >= $%d", argNum)
+		args = append(args, *f.From)
+		argNum++
+	}
+	if f.To != nil {
+		query += fmt.Sprintf(" AND gri.created_at < $%d", argNum)
+		args = append(args, *f.To)
+		argNum++
+	}
+	query += fmt.Sprintf(" ORDER BY gri.created_at DESC, gri.id DESC LIMIT $%d", argNum)
+	args = append(args, f.Limit)
+
+	return r.queryReceiptItems(ctx, query, true, args...)
+}
+
+// LatestUnitCosts devuelve el último costo unitario recibido de cada insumo
+func (r *PurchasingRepository) LatestUnitCosts(ctx context.Context, restaurantID uuid.UUID) (map[uuid.UUID]money.Money, error) {
+	query := `
+		SELECT DISTINCT ON (ingredient_id) ingredient_id, unit_cost
+		FROM goods_receipt_items
+		WHERE restaurant_id = $1
+		ORDER BY ingredient_id, created_at DESC, id DESC
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	costs := make(map[uuid.UUID]money.Money)
+	for rows.Next() {
+		var id uuid.UUID
+		var cost money.Money
+		if err := rows.Scan(&id, &cost); err != nil {
+			return nil, err
+		}
+		costs[id] = cost
+	}
+	return costs, rows.Err()
+}
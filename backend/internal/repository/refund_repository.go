@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+)
+
+type RefundRepository struct {
+	db DBTX
+}
+
+func NewRefundRepository(pool *pgxpool.Pool) *RefundRepository {
+	return &RefundRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *RefundRepository) WithTx(tx pgx.Tx) *RefundRepository {
+	return &RefundRepository{db: tx}
+}
+
+func (r *RefundRepository) Create(ctx context.Context, refund *models.Refund) error {
+	query := `
+		INSERT INTO refunds (id, restaurant_id, sale_id, user_id, cash_session_id, total, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(ctx, query,
+		refund.ID, refund.RestaurantID, refund.SaleID, refund.UserID, refund.CashSessionID, refund.Total, refund.Reason,
+	)
+	return err
+}
+
+func (r *RefundRepository) CreateItem(ctx context.Context, item *models.RefundItem) error {
+	query := `INSERT INTO refund_items (id, refund_id, sale_item_id, quantity, amount) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.Exec(ctx, query, item.ID, item.RefundID, item.SaleItemID, item.Quantity, item.Amount)
+	return err
+}
+
+func (r *RefundRepository) CreatePayment(ctx context.Context, payment *models.RefundPayment) error {
+	query := `INSERT INTO refund_payments (id, refund_id, method, amount, reference) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.Exec(ctx, query, payment.ID, payment.RefundID, payment.Method, payment.Amount, payment.Reference)
+	return err
+}
+
+func (r *RefundRepository) GetByID(ctx context.Context, restaurantID, refundID uuid.UUID) (*models.Refund, error) {
+	query := `
+		SELECT id, restaurant_id, sale_id, user_id, cash_session_id, total, COALESCE(reason, ''), created_at
+		FROM refunds
+		WHERE id = $1 AND restaurant_id = $2
+	`
+	var rf models.Refund
+	err := r.db.QueryRow(ctx, query, refundID, restaurantID).Scan(
+		&rf.ID, &rf.RestaurantID, &rf.SaleID, &rf.UserID, &rf.CashSessionID, &rf.Total, &rf.Reason, &rf.CreatedAt,
+	)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &rf, nil
+}
+
+func (r *RefundRepository) ListBySale(ctx context.Context, restaurantID, saleID uuid.UUID) ([]*models.Refund, error) {
+	query := `
+		SELECT id, restaurant_id, sale_id, user_id, cash_session_id, total, COALESCE(reason, ''), created_at
+		FROM refunds
+		WHERE sale_id = $1 AND restaurant_id = $2
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(ctx, query, saleID, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []*models.Refund
+	for rows.Next() {
+		var rf models.Refund
+		if err := rows.Scan(&rf.ID, &rf.RestaurantID, &rf.SaleID, &rf.UserID, &rf.CashSessionID, &rf.Total, &rf.Reason, &rf.CreatedAt); err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, &rf)
+	}
+	return refunds, rows.Err()
+}
+
+func (r *RefundRepository) GetItems(ctx context.Context, refundID uuid.UUID) ([]*models.RefundItem, error) {
+	query := `SELECT id, refund_id, sale_item_id, quantity, amount FROM refund_items WHERE refund_id = $1`
+	rows, err := r.db.Query(ctx, query, refundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.RefundItem
+	for rows.Next() {
+		var it models.RefundItem
+		if err := rows.Scan(&it.ID, &it.RefundID, &it.SaleItemID, &it.Quantity, &it.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, &it)
+	}
+	return items, rows.Err()
+}
+
+func (r *RefundRepository) GetPayments(ctx context.Context, refundID uuid.UUID) ([]*models.RefundPayment, error) {
+	query := `SELECT id, refund_id, method, amount, reference FROM refund_payments WHERE refund_id = $1`
+	rows, err := r.db.Query(ctx, query, refundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*models.RefundPayment
+	for rows.Next() {
+		var p models.RefundPayment
+		if err := rows.Scan(&p.ID, &p.RefundID, &p.Method, &p.Amount, &p.Reference); err != nil {
+			return nil, err
+		}
+		payments = append(payments, &p)
+	}
+	return payments, rows.Err()
+}
+
+// RefundedBySaleItem devuelve, por sale_item, la cantidad y el monto ya devueltos
+func (r *RefundRepository) RefundedBySaleItem(ctx context.Context, saleID uuid.UUID) (map[uuid.UUID]int, map[uuid.UUID]money.Money, error) {
+	query := `
+		SELECT ri.sale_item_id, SUM(ri.quantity), SUM(ri.amount)
+		FROM refund_items ri
+		JOIN refunds rf ON rf.id = ri.refund_id
+		WHERE rf.sale_id = $1
+		GROUP BY ri.sale_item_id
+	`
+	rows, err := r.db.Query(ctx, query, saleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	quantities := make(map[uuid.UUID]int)
+	amounts := make(map[uuid.UUID]money.Money)
+	for rows.Next() {
+		var id uuid.UUID
+		var qty int
+		var amount money.Money
+		if err := rows.Scan(&id, &qty, &amount); err != nil {
+			return nil, nil, err
+		}
+		quantities[id] = qty
+		amounts[id] = amount
+	}
+	return quantities, amounts, rows.Err()
+}
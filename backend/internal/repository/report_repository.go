@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+// ReportRepository agrega ventas completadas (las anuladas y pendientes no cuentan).
+// Todos los métodos reciben el rango [from, to) y la zona horaria del restaurante.
+type ReportRepository struct {
+	db DBTX
+}
+
+func NewReportRepository(pool *pgxpool.Pool) *ReportRepository {
+	return &ReportRepository{db: pool}
+}
+
+func (r *ReportRepository) Summary(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) (*models.ReportSummary, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(total), 0)
+		FROM sales
+		WHERE restaurant_id = $1 AND status = 'completed' AND created_at >= $2 AND created_at < $3
+	`
+	var s models.ReportSummary
+	if err := r.db.QueryRow(ctx, query, restaurantID, from, to).Scan(&s.SalesCount, &s.Revenue); err != nil {
+		return nil, err
+	}
+
+	refundsQuery := `
+		SELECT COALESCE(SUM(total), 0)
+		FROM refunds
+		WHERE restaurant_id = $1 AND created_at >= $2 AND created_at < $3
+	`
+	if err := r.db.QueryRow(ctx, refundsQuery, restaurantID, from, to).Scan(&s.Refunds); err != nil {
+		return nil, err
+	}
+
+	tipsQuery := `
+		SELECT COALESCE(SUM(tip_total), 0)
+		FROM sales
+		WHERE restaurant_id = $1 AND status = 'completed' AND created_at >= $2 AND created_at < $3
+	`
+	if err := r.db.QueryRow(ctx, tipsQuery, restaurantID, from, to).Scan(&s.Tips); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *ReportRepository) RevenueByDay(ctx context.Context, restaurantID uuid.UUID, from, to time.Time, tz string) ([]*models.RevenueByDay, error) {
+	query := `
+		SELECT to_char(date_trunc('day', created_at AT TIME ZONE $4), 'YYYY-MM-DD') AS day,
+		       COUNT(*), COALESCE(SUM(total), 0)
+		FROM sales
+		WHERE restaurant_id = $1 AND status = 'completed' AND created_at >= $2 AND created_at < $3
+		GROUP BY day
+		ORDER BY day
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID, from, to, tz)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*models.RevenueByDay{}
+	for rows.Next() {
+		var d models.RevenueByDay
+		if err := rows.Scan(&d.Day, &d.SalesCount, &d.Revenue); err != nil {
+			return nil, err
+		}
+		result = append(result, &d)
+	}
+	return result, rows.Err()
+}
+
+func (r *ReportRepository) RevenueByHour(ctx context.Context, restaurantID uuid.UUID, from, to time.Time, tz string) ([]*models.RevenueByHour, error) {
+	query := `
+		SELECT EXTRACT(HOUR FROM created_at AT TIME ZONE $4)::int AS hour,
+		       COUNT(*), COALESCE(SUM(total), 0)
+		FROM sales
+		WHERE restaurant_id = $1 AND status = 'completed' AND created_at >= $2 AND created_at < $3
+		GROUP BY hour
+		ORDER BY hour
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID, from, to, tz)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*models.RevenueByHour{}
+	for rows.Next() {
+		var h models.RevenueByHour
+		if err := rows.Scan(&h.Hour, &h.SalesCount, &h.Revenue); err != nil {
+			return nil, err
+		}
+		result = append(result, &h)
+	}
+	return result, rows.Err()
+}
+
+// TopProducts ordena por ingresos; el total de cada línea incluye toppings e impuestos
+func (r *ReportRepository) TopProducts(ctx context.Context, restaurantID uuid.UUID, from, to time.Time, limit int) ([]*models.ProductSales, error) {
+	query := `
+		SELECT p.id, p.name, SUM(si.quantity), SUM(si.total) AS revenue
+		FROM sale_items si
+		JOIN sales s ON s.id = si.sale_id
+		JOIN products p ON p.id = si.product_id
+		WHERE s.restaurant_id = $1 AND s.status = 'completed' AND s.created_at >= $2 AND s.created_at < $3
+		GROUP BY p.id, p.name
+		ORDER BY revenue DESC, p.name
+		LIMIT $4
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*models.ProductSales{}
+	for rows.Next() {
+		var p models.ProductSales
+		if err := rows.Scan(&p.ProductID, &p.Name, &p.Quantity, &p.Revenue); err != nil {
+			return nil, err
+		}
+		result = append(result, &p)
+	}
+	return result, rows.Err()
+}
+
+func (r *ReportRepository) RevenueByCategory(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*models.CategorySales, error) {
+	query := `
+		SELECT c.id, COALESCE(c.name, 'Sin categoría'), SUM(si.quantity), SUM(si.total) AS revenue
+		FROM sale_items si
+		JOIN sales s ON s.id = si.sale_id
+		JOIN products p ON p.id = si.product_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE s.restaurant_id = $1 AND s.status = 'completed' AND s.created_at >= $2 AND s.created_at < $3
+		GROUP BY c.id, c.name
+		ORDER BY revenue DESC
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*models.CategorySales{}
+	for rows.Next() {
+		var c models.CategorySales
+		if err := rows.Scan(&c.CategoryID, &c.Name, &c.Quantity, &c.Revenue); err != nil {
+			return nil, err
+		}
+		result = append(result, &c)
+	}
+	return result, rows.Err()
+}
+
+func (r *ReportRepository) SalesByCashier(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*models.CashierSales, error) {
+	query := `
+		SELECT u.id, u.email, COUNT(*), COALESCE(SUM(s.total), 0) AS revenue
+		FROM sales s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.restaurant_id = $1 AND s.status = 'completed' AND s.created_at >= $2 AND s.created_at < $3
+		GROUP BY u.id, u.email
+		ORDER BY revenue DESC
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*models.CashierSales{}
+	for rows.Next() {
+		var c models.CashierSales
+		if err := rows.Scan(&c.UserID, &c.Email, &c.SalesCount, &c.Revenue); err != nil {
+			return nil, err
+		}
+		result = append(result, &c)
+	}
+	return result, rows.Err()
+}
+
+// TipsByCashier suma las propinas de cada cajero separando las de efectivo
+func (r *ReportRepository) TipsByCashier(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*models.CashierTips, error) {
+	query := `
+		SELECT u.id, u.email, COUNT(DISTINCT s.id), SUM(sp.tip) AS tips,
+		       COALESCE(SUM(sp.tip) FILTER (WHERE sp.method = 'cash'), 0),
+		       COALESCE(SUM(sp.tip) FILTER (WHERE sp.method <> 'cash'), 0)
+		FROM sales s
+		JOIN sale_payments sp ON sp.sale_id = s.id
+		JOIN users u ON u.id = s.user_id
+		WHERE s.restaurant_id = $1 AND s.status = 'completed' AND s.created_at >= $2 AND s.created_at < $3
+		  AND sp.tip > 0
+		GROUP BY u.id, u.email
+		ORDER BY tips DESC
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*models.CashierTips{}
+	for rows.Next() {
+		var c models.CashierTips
+		if err := rows.Scan(&c.UserID, &c.Email, &c.SalesCount, &c.Tips, &c.CashTips, &c.NonCashTips); err != nil {
+			return nil, err
+		}
+		result = append(result, &c)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+type RoleRepository struct {
+	db DBTX
+}
+
+func NewRoleRepository(pool *pgxpool.Pool) *RoleRepository {
+	return &RoleRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *RoleRepository) WithTx(tx pgx.Tx) *RoleRepository {
+	return &RoleRepository{db: tx}
+}
+
+const roleColumns = `id, restaurant_id, name, description, permissions, created_at, updated_at`
+
+func scanRole(row pgx.Row) (*models.Role, error) {
+	var role models.Role
+	err := row.Scan(&role.ID, &role.RestaurantID, &role.Name, &role.Description, &role.Permissions, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *RoleRepository) Create(ctx context.Context, role *models.Role) error {
+	query := `
+		INSERT INTO roles (id, restaurant_id, name, description, permissions)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(ctx, query, role.ID, role.RestaurantID, role.Name, role.Description, role.Permissions).
+		Scan(&role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *RoleRepository) Get(ctx context.Context, restaurantID, roleID uuid.UUID) (*models.Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles WHERE id = $1 AND restaurant_id = $2`
+	return scanRole(r.db.QueryRow(ctx, query, roleID, restaurantID))
+}
+
+func (r *RoleRepository) GetByName(ctx context.Context, restaurantID uuid.UUID, name string) (*models.Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles WHERE restaurant_id = $1 AND name = $2`
+	return scanRole(r.db.QueryRow(ctx, query, restaurantID, name))
+}
+
+func (r *RoleRepository) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles WHERE restaurant_id = $1 ORDER BY name`
+	rows, err := r.db.Query(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []*models.Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+func (r *RoleRepository) Update(ctx context.Context, role *models.Role) error {
+	query := `
+		UPDATE roles SET name = $3, description = $4, permissions = $5
+		WHERE id = $1 AND restaurant_id = $2
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(ctx, query, role.ID, role.RestaurantID, role.Name, role.Description, role.Permissions).
+		Scan(&role.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return errors.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *RoleRepository) Delete(ctx context.Context, restaurantID, roleID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM roles WHERE id = $1 AND restaurant_id = $2`, roleID, restaurantID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// CountUsers cuenta los usuarios no borrados que tienen el rol
+func (r *RoleRepository) CountUsers(ctx context.Context, restaurantID uuid.UUID, name string) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM users WHERE restaurant_id = $1 AND role = $2 AND deleted_at IS NULL`,
+		restaurantID, name,
+	).Scan(&n)
+	return n, err
+}
+
+// RenameUsers pasa a los usuarios del rol from al rol to
+func (r *RoleRepository) RenameUsers(ctx context.Context, restaurantID uuid.UUID, from, to string) error {
+	_, err := r.db.Exec(ctx, `UPDATE users SET role = $3 WHERE restaurant_id = $1 AND role = $2`, restaurantID, from, to)
+	return err
+}
@@ -2,59 +2,97 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pos-saas/restaurant-pos/internal/errors"
 	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
 )
 
 type SaleRepository struct {
-	pool *pgxpool.Pool
+	db DBTX
 }
 
 func NewSaleRepository(pool *pgxpool.Pool) *SaleRepository {
-	return &SaleRepository{pool: pool}
+	return &SaleRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *SaleRepository) WithTx(tx pgx.Tx) *SaleRepository {
+	return &SaleRepository{db: tx}
 }
 
 func (r *SaleRepository) Create(ctx context.Context, sale *models.Sale) error {
 	query := `
-		INSERT INTO sales (id, restaurant_id, user_id, total, status)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO sales (id, restaurant_id, user_id, subtotal, tax_total, total, status, cash_session_id,
+		                   discount_type, discount_value, discount_amount, discount_reason, discount_total, tip_total,
+		                   table_id, opened_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11, NULLIF($12, ''), $13, $14, $15, $16)
+		RETURNING created_at, updated_at
 	`
-	_, err := r.pool.Exec(ctx, query,
-		sale.ID, sale.RestaurantID, sale.UserID, sale.Total, sale.Status,
-	)
-	return err
+	return r.db.QueryRow(ctx, query,
+		sale.ID, sale.RestaurantID, sale.UserID, sale.Subtotal, sale.TaxTotal, sale.Total, sale.Status, sale.CashSessionID,
+		sale.DiscountType, sale.DiscountValue, sale.DiscountAmount, sale.DiscountReason, sale.DiscountTotal, sale.TipTotal,
+		sale.TableID, sale.OpenedAt,
+	).Scan(&sale.CreatedAt, &sale.UpdatedAt)
 }
 
 func (r *SaleRepository) CreateItem(ctx context.Context, item *models.SaleItem) error {
-	query := `INSERT INTO sale_items (id, sale_id, product_id, quantity, unit_price, subtotal, notes) VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	_, err := r.pool.Exec(ctx, query, item.ID, item.SaleID, item.ProductID, item.Quantity, item.UnitPrice, item.Subtotal, item.Notes)
+	query := `
+		INSERT INTO sale_items (id, sale_id, product_id, quantity, unit_price, subtotal, tax_rate_id, tax_rate_bps, tax_amount, total, notes,
+		                        discount_type, discount_value, discount_amount, discount_reason, ticket_discount,
+		                        variant_id, variant_name, parent_item_id, combo_slot_name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), $13, $14, NULLIF($15, ''), $16, $17, NULLIF($18, ''),
+		        $19, NULLIF($20, ''))
+	`
+	_, err := r.db.Exec(ctx, query,
+		item.ID, item.SaleID, item.ProductID, item.Quantity, item.UnitPrice, item.Subtotal,
+		item.TaxRateID, item.TaxRateBps, item.TaxAmount, item.Total, item.Notes,
+		item.DiscountType, item.DiscountValue, item.DiscountAmount, item.DiscountReason, item.TicketDiscount,
+		item.VariantID, item.VariantName, item.ParentItemID, item.ComboSlotName,
+	)
 	return err
 }
 
 func (r *SaleRepository) CreateItemTopping(ctx context.Context, topping *models.Topping) error {
-	query := `INSERT INTO sale_item_toppings (id, sale_item_id, name, price, quantity) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.pool.Exec(ctx, query, topping.ID, topping.SaleItemID, topping.Name, topping.Price, topping.Quantity)
+	query := `INSERT INTO sale_item_toppings (id, sale_item_id, modifier_option_id, name, price, quantity) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.Exec(ctx, query, topping.ID, topping.SaleItemID, topping.ModifierOptionID, topping.Name, topping.Price, topping.Quantity)
 	return err
 }
 
-func (r *SaleRepository) CreatePayment(ctx context.Context, payment *models.SalePayment) error {
-	query := `INSERT INTO sale_payments (id, sale_id, method, amount, reference) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.pool.Exec(ctx, query, payment.ID, payment.SaleID, payment.Method, payment.Amount, payment.Reference)
+func (r *SaleRepository) CreateTax(ctx context.Context, tax *models.SaleTax) error {
+	query := `INSERT INTO sale_taxes (id, sale_id, tax_rate_id, name, rate_bps, exempt, base, amount) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.db.Exec(ctx, query, tax.ID, tax.SaleID, tax.TaxRateID, tax.Name, tax.RateBps, tax.Exempt, tax.Base, tax.Amount)
 	return err
 }
 
-func (r *SaleRepository) GetByID(ctx context.Context, restaurantID, saleID uuid.UUID) (*models.Sale, error) {
+func (r *SaleRepository) CreatePayment(ctx context.Context, payment *models.SalePayment) error {
 	query := `
-		SELECT id, restaurant_id, user_id, total, status, created_at, updated_at
-		FROM sales
-		WHERE id = $1 AND restaurant_id = $2
+		INSERT INTO sale_payments (id, sale_id, method, amount, tip, reference, check_id, cash_session_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
+	_, err := r.db.Exec(ctx, query,
+		payment.ID, payment.SaleID, payment.Method, payment.Amount, payment.Tip, payment.Reference, payment.CheckID, payment.CashSessionID,
+	)
+	return err
+}
+
+const saleColumns = `id, restaurant_id, user_id, subtotal, tax_total, total, status,
+		cancelled_at, cancelled_by, COALESCE(cancel_reason, ''), cash_session_id,
+		COALESCE(discount_type, ''), discount_value, discount_amount, COALESCE(discount_reason, ''), discount_total,
+		tip_total, table_id, opened_at, created_at, updated_at`
+
+func scanSale(row pgx.Row) (*models.Sale, error) {
 	var s models.Sale
-	err := r.pool.QueryRow(ctx, query, saleID, restaurantID).Scan(
-		&s.ID, &s.RestaurantID, &s.UserID, &s.Total, &s.Status, &s.CreatedAt, &s.UpdatedAt,
+	err := row.Scan(
+		&s.ID, &s.RestaurantID, &s.UserID, &s.Subtotal, &s.TaxTotal, &s.Total, &s.Status,
+		&s.CancelledAt, &s.CancelledBy, &s.CancelReason, &s.CashSessionID,
+		&s.DiscountType, &s.DiscountValue, &s.DiscountAmount, &s.DiscountReason, &s.DiscountTotal,
+		&s.TipTotal, &s.TableID, &s.OpenedAt, &s.CreatedAt, &s.UpdatedAt,
 	)
 	if err != nil {
 		if isNoRows(err) {
@@ -65,13 +103,136 @@ func (r *SaleRepository) GetByID(ctx context.Context, restaurantID, saleID uuid.
 	return &s, nil
 }
 
+func (r *SaleRepository) GetByID(ctx context.Context, restaurantID, saleID uuid.UUID) (*models.Sale, error) {
+	query := `SELECT ` + saleColumns + ` FROM sales WHERE id = $1 AND restaurant_id = $2`
+	return scanSale(r.db.QueryRow(ctx, query, saleID, restaurantID))
+}
+
+// GetByIDForUpdate bloquea la fila de la venta hasta el fin de la transacción.
+// Solo tiene sentido sobre un repositorio obtenido con WithTx.
+func (r *SaleRepository) GetByIDForUpdate(ctx context.Context, restaurantID, saleID uuid.UUID) (*models.Sale, error) {
+	query := `SELECT ` + saleColumns + ` FROM sales WHERE id = $1 AND restaurant_id = $2 FOR UPDATE`
+	return scanSale(r.db.QueryRow(ctx, query, saleID, restaurantID))
+}
+
+// SaleListFilter agrupa los filtros del listado de ventas. Los campos nil no filtran.
+// After/AfterID forman el cursor de paginación (created_at, id) en orden descendente.
+type SaleListFilter struct {
+	From          *time.Time
+	To            *time.Time
+	Status        string
+	UserID        *uuid.UUID
+	PaymentMethod string
+	MinTotal      *money.Money
+	MaxTotal      *money.Money
+	After         *time.Time
+	AfterID       *uuid.UUID
+	Limit         int
+}
+
+func (r *SaleRepository) List(ctx context.Context, restaurantID uuid.UUID, f SaleListFilter) ([]*models.Sale, error) {
+	query := `SELECT ` + saleColumns + ` FROM sales WHERE restaurant_id = $1`
+	args := []interface{}{restaurantID}
+	argNum := 2
+
+	if f.From != nil {
+		query += fmt.Sprintf(" AND created_at >= $%d", argNum)
+		args = append(args, *f.From)
+		argNum++
+	}
+	if f.To != nil {
+		query += fmt.Sprintf(" AND created_at < $%d", argNum)
+		args = append(args, *f.To)
+		argNum++
+	}
+	if f.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argNum)
+		args = append(args, f.Status)
+		argNum++
+	}
+	if f.UserID != nil {
+		query += fmt.Sprintf(" AND user_id = $%d", argNum)
+		args = append(args, *f.UserID)
+		argNum++
+	}
+	if f.PaymentMethod != "" {
+		query += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM sale_payments sp WHERE sp.sale_id = sales.id AND sp.method = $%d)", argNum)
+		args = append(args, f.PaymentMethod)
+		argNum++
+	}
+	if f.MinTotal != nil {
+		query += fmt.Sprintf(" AND total >= $%d", argNum)
+		args = append(args, *f.MinTotal)
+		argNum++
+	}
+	if f.MaxTotal != nil {
+		query += fmt.Sprintf(" AND total <= $%d", argNum)
+		args = append(args, *f.MaxTotal)
+		argNum++
+	}
+	if f.After != nil && f.AfterID != nil {
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", argNum, argNum+1)
+		args = append(args, *f.After, *f.AfterID)
+		argNum += 2
+	}
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", argNum)
+	args = append(args, f.Limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []*models.Sale
+	for rows.Next() {
+		s, err := scanSale(rows)
+		if err != nil {
+			return nil, err
+		}
+		sales = append(sales, s)
+	}
+	return sales, rows.Err()
+}
+
+// Cancel marca la venta como anulada registrando usuario, fecha y motivo.
+// Devuelve ErrConflict si la venta ya estaba anulada.
+func (r *SaleRepository) Cancel(ctx context.Context, restaurantID, saleID, userID uuid.UUID, reason string) error {
+	query := `
+		UPDATE sales
+		SET status = $4, cancelled_at = NOW(), cancelled_by = $3, cancel_reason = $5
+		WHERE id = $1 AND restaurant_id = $2 AND status <> $4
+	`
+	result, err := r.db.Exec(ctx, query, saleID, restaurantID, userID, models.SaleStatusCancelled, reason)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		if _, err := r.GetByID(ctx, restaurantID, saleID); err != nil {
+			return err
+		}
+		return errors.ErrConflict
+	}
+	return nil
+}
+
+// HasRefunds indica si la venta tiene devoluciones registradas
+func (r *SaleRepository) HasRefunds(ctx context.Context, saleID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM refunds WHERE sale_id = $1)`, saleID).Scan(&exists)
+	return exists, err
+}
+
 func (r *SaleRepository) GetItems(ctx context.Context, saleID uuid.UUID) ([]*models.SaleItem, error) {
 	query := `
-		SELECT si.id, si.sale_id, si.product_id, si.quantity, si.unit_price, si.subtotal, si.notes
+		SELECT si.id, si.sale_id, si.product_id, si.quantity, si.unit_price, si.subtotal,
+		       si.tax_rate_id, si.tax_rate_bps, si.tax_amount, si.total, COALESCE(si.notes, ''),
+		       COALESCE(si.discount_type, ''), si.discount_value, si.discount_amount, COALESCE(si.discount_reason, ''), si.ticket_discount,
+		       si.variant_id, COALESCE(si.variant_name, ''), si.parent_item_id, COALESCE(si.combo_slot_name, '')
 		FROM sale_items si
 		WHERE si.sale_id = $1
 	`
-	rows, err := r.pool.Query(ctx, query, saleID)
+	rows, err := r.db.Query(ctx, query, saleID)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +241,11 @@ func (r *SaleRepository) GetItems(ctx context.Context, saleID uuid.UUID) ([]*mod
 	var items []*models.SaleItem
 	for rows.Next() {
 		var item models.SaleItem
-		if err := rows.Scan(&item.ID, &item.SaleID, &item.ProductID, &item.Quantity, &item.UnitPrice, &item.Subtotal, &item.Notes); err != nil {
+		err := rows.Scan(&item.ID, &item.SaleID, &item.ProductID, &item.Quantity, &item.UnitPrice, &item.Subtotal,
+			&item.TaxRateID, &item.TaxRateBps, &item.TaxAmount, &item.Total, &item.Notes,
+			&item.DiscountType, &item.DiscountValue, &item.DiscountAmount, &item.DiscountReason, &item.TicketDiscount,
+			&item.VariantID, &item.VariantName, &item.ParentItemID, &item.ComboSlotName)
+		if err != nil {
 			return nil, err
 		}
 		items = append(items, &item)
@@ -89,8 +254,8 @@ func (r *SaleRepository) GetItems(ctx context.Context, saleID uuid.UUID) ([]*mod
 }
 
 func (r *SaleRepository) GetItemToppings(ctx context.Context, saleItemID uuid.UUID) ([]*models.Topping, error) {
-	query := `SELECT id, sale_item_id, name, price, quantity FROM sale_item_toppings WHERE sale_item_id = $1`
-	rows, err := r.pool.Query(ctx, query, saleItemID)
+	query := `SELECT id, sale_item_id, modifier_option_id, name, price, quantity FROM sale_item_toppings WHERE sale_item_id = $1`
+	rows, err := r.db.Query(ctx, query, saleItemID)
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +264,7 @@ func (r *SaleRepository) GetItemToppings(ctx context.Context, saleItemID uuid.UU
 	var toppings []*models.Topping
 	for rows.Next() {
 		var t models.Topping
-		if err := rows.Scan(&t.ID, &t.SaleItemID, &t.Name, &t.Price, &t.Quantity); err != nil {
+		if err := rows.Scan(&t.ID, &t.SaleItemID, &t.ModifierOptionID, &t.Name, &t.Price, &t.Quantity); err != nil {
 			return nil, err
 		}
 		toppings = append(toppings, &t)
@@ -107,9 +272,28 @@ func (r *SaleRepository) GetItemToppings(ctx context.Context, saleItemID uuid.UU
 	return toppings, rows.Err()
 }
 
+func (r *SaleRepository) GetTaxes(ctx context.Context, saleID uuid.UUID) ([]*models.SaleTax, error) {
+	query := `SELECT id, sale_id, tax_rate_id, name, rate_bps, exempt, base, amount FROM sale_taxes WHERE sale_id = $1 ORDER BY rate_bps DESC, name`
+	rows, err := r.db.Query(ctx, query, saleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var taxes []*models.SaleTax
+	for rows.Next() {
+		var t models.SaleTax
+		if err := rows.Scan(&t.ID, &t.SaleID, &t.TaxRateID, &t.Name, &t.RateBps, &t.Exempt, &t.Base, &t.Amount); err != nil {
+			return nil, err
+		}
+		taxes = append(taxes, &t)
+	}
+	return taxes, rows.Err()
+}
+
 func (r *SaleRepository) GetPayments(ctx context.Context, saleID uuid.UUID) ([]*models.SalePayment, error) {
-	query := `SELECT id, sale_id, method, amount, reference FROM sale_payments WHERE sale_id = $1`
-	rows, err := r.pool.Query(ctx, query, saleID)
+	query := `SELECT id, sale_id, method, amount, tip, reference, check_id, cash_session_id FROM sale_payments WHERE sale_id = $1`
+	rows, err := r.db.Query(ctx, query, saleID)
 	if err != nil {
 		return nil, err
 	}
@@ -118,10 +302,155 @@ func (r *SaleRepository) GetPayments(ctx context.Context, saleID uuid.UUID) ([]*
 	var payments []*models.SalePayment
 	for rows.Next() {
 		var p models.SalePayment
-		if err := rows.Scan(&p.ID, &p.SaleID, &p.Method, &p.Amount, &p.Reference); err != nil {
+		if err := rows.Scan(&p.ID, &p.SaleID, &p.Method, &p.Amount, &p.Tip, &p.Reference, &p.CheckID, &p.CashSessionID); err != nil {
 			return nil, err
 		}
 		payments = append(payments, &p)
 	}
 	return payments, rows.Err()
 }
+
+// ListOpen devuelve las cuentas abiertas (ventas pending), las más antiguas
+// primero; tableID nil no filtra por mesa
+func (r *SaleRepository) ListOpen(ctx context.Context, restaurantID uuid.UUID, tableID *uuid.UUID) ([]*models.Sale, error) {
+	query := `
+		SELECT ` + saleColumns + `
+		FROM sales
+		WHERE restaurant_id = $1 AND status = $2 AND ($3::uuid IS NULL OR table_id = $3)
+		ORDER BY opened_at, id
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID, models.SaleStatusPending, tableID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales := []*models.Sale{}
+	for rows.Next() {
+		s, err := scanSale(rows)
+		if err != nil {
+			return nil, err
+		}
+		sales = append(sales, s)
+	}
+	return sales, rows.Err()
+}
+
+// UpdateTotals guarda importes, descuento del ticket y propina de la venta
+func (r *SaleRepository) UpdateTotals(ctx context.Context, sale *models.Sale) error {
+	query := `
+		UPDATE sales
+		SET subtotal = $3, tax_total = $4, total = $5, discount_type = NULLIF($6, ''), discount_value = $7,
+		    discount_amount = $8, discount_reason = NULLIF($9, ''), discount_total = $10, tip_total = $11
+		WHERE id = $1 AND restaurant_id = $2
+	`
+	result, err := r.db.Exec(ctx, query,
+		sale.ID, sale.RestaurantID, sale.Subtotal, sale.TaxTotal, sale.Total, sale.DiscountType, sale.DiscountValue,
+		sale.DiscountAmount, sale.DiscountReason, sale.DiscountTotal, sale.TipTotal,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// SetTable mueve la venta a otra mesa
+func (r *SaleRepository) SetTable(ctx context.Context, restaurantID, saleID uuid.UUID, tableID *uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `UPDATE sales SET table_id = $3 WHERE id = $1 AND restaurant_id = $2`, saleID, restaurantID, tableID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Complete cobra una cuenta abierta: la asocia al turno y created_at pasa a ser
+// la fecha de cobro. Devuelve ErrConflict si la venta no está pendiente.
+func (r *SaleRepository) Complete(ctx context.Context, restaurantID, saleID, cashSessionID uuid.UUID) error {
+	query := `
+		UPDATE sales
+		SET status = $3, cash_session_id = $4, created_at = NOW()
+		WHERE id = $1 AND restaurant_id = $2 AND status = $5
+	`
+	result, err := r.db.Exec(ctx, query, saleID, restaurantID, models.SaleStatusCompleted, cashSessionID, models.SaleStatusPending)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrConflict
+	}
+	return nil
+}
+
+// DeletePending borra una cuenta abierta que ya no tiene líneas (al unirla a otra)
+func (r *SaleRepository) DeletePending(ctx context.Context, restaurantID, saleID uuid.UUID) error {
+	query := `DELETE FROM sales WHERE id = $1 AND restaurant_id = $2 AND status = $3`
+	result, err := r.db.Exec(ctx, query, saleID, restaurantID, models.SaleStatusPending)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrConflict
+	}
+	return nil
+}
+
+// UpdateItem guarda cantidad, importes, descuentos e impuesto de una línea
+func (r *SaleRepository) UpdateItem(ctx context.Context, item *models.SaleItem) error {
+	query := `
+		UPDATE sale_items
+		SET quantity = $2, subtotal = $3, discount_value = $4, discount_amount = $5, ticket_discount = $6,
+		    tax_rate_id = $7, tax_rate_bps = $8, tax_amount = $9, total = $10
+		WHERE id = $1
+	`
+	result, err := r.db.Exec(ctx, query,
+		item.ID, item.Quantity, item.Subtotal, item.DiscountValue, item.DiscountAmount, item.TicketDiscount,
+		item.TaxRateID, item.TaxRateBps, item.TaxAmount, item.Total,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SaleRepository) UpdateToppingQuantity(ctx context.Context, toppingID uuid.UUID, quantity int) error {
+	_, err := r.db.Exec(ctx, `UPDATE sale_item_toppings SET quantity = $2 WHERE id = $1`, toppingID, quantity)
+	return err
+}
+
+// DeleteItem borra una línea principal; sus componentes y toppings se borran en cascada
+func (r *SaleRepository) DeleteItem(ctx context.Context, saleID, itemID uuid.UUID) error {
+	query := `DELETE FROM sale_items WHERE id = $1 AND sale_id = $2 AND parent_item_id IS NULL`
+	result, err := r.db.Exec(ctx, query, itemID, saleID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// MoveItems pasa líneas principales (con sus componentes) de una venta a otra
+func (r *SaleRepository) MoveItems(ctx context.Context, fromSaleID, toSaleID uuid.UUID, itemIDs []uuid.UUID) error {
+	query := `
+		UPDATE sale_items SET sale_id = $2
+		WHERE sale_id = $1 AND (id = ANY($3) OR parent_item_id = ANY($3))
+	`
+	_, err := r.db.Exec(ctx, query, fromSaleID, toSaleID, itemIDs)
+	return err
+}
+
+// DeleteTaxes borra el desglose de impuestos para volver a calcularlo
+func (r *SaleRepository) DeleteTaxes(ctx context.Context, saleID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM sale_taxes WHERE sale_id = $1`, saleID)
+	return err
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+// SessionRepository guarda las sesiones de inicio de sesión y sus refresh tokens
+type SessionRepository struct {
+	db DBTX
+}
+
+func NewSessionRepository(pool *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *SessionRepository) WithTx(tx pgx.Tx) *SessionRepository {
+	return &SessionRepository{db: tx}
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, s *models.AuthSession) error {
+	query := `
+		INSERT INTO auth_sessions (id, restaurant_id, user_id, terminal_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+	return r.db.QueryRow(ctx, query, s.ID, s.RestaurantID, s.UserID, s.TerminalID).Scan(&s.CreatedAt)
+}
+
+func (r *SessionRepository) GetSession(ctx context.Context, sessionID uuid.UUID) (*models.AuthSession, error) {
+	query := `
+		SELECT id, restaurant_id, user_id, terminal_id, created_at, revoked_at, COALESCE(revoke_reason, '')
+		FROM auth_sessions
+		WHERE id = $1
+	`
+	var s models.AuthSession
+	err := r.db.QueryRow(ctx, query, sessionID).Scan(
+		&s.ID, &s.RestaurantID, &s.UserID, &s.TerminalID, &s.CreatedAt, &s.RevokedAt, &s.RevokeReason,
+	)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// IsActive indica si la sesión sigue abierta y su usuario activo
+func (r *SessionRepository) IsActive(ctx context.Context, sessionID, userID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM auth_sessions s
+			JOIN users u ON u.id = s.user_id
+			WHERE s.id = $1 AND s.user_id = $2 AND s.revoked_at IS NULL
+			  AND u.active = true AND u.deleted_at IS NULL
+		)
+	`
+	var active bool
+	err := r.db.QueryRow(ctx, query, sessionID, userID).Scan(&active)
+	return active, err
+}
+
+// RevokeSession cierra la sesión; una sesión ya cerrada conserva su motivo
+func (r *SessionRepository) RevokeSession(ctx context.Context, sessionID uuid.UUID, reason string) error {
+	query := `UPDATE auth_sessions SET revoked_at = NOW(), revoke_reason = $2 WHERE id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(ctx, query, sessionID, reason)
+	return err
+}
+
+// RevokeUserSessions cierra todas las sesiones abiertas del usuario
+func (r *SessionRepository) RevokeUserSessions(ctx context.Context, restaurantID, userID uuid.UUID, reason string) error {
+	query := `
+		UPDATE auth_sessions SET revoked_at = NOW(), revoke_reason = $3
+		WHERE restaurant_id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+	_, err := r.db.Exec(ctx, query, restaurantID, userID, reason)
+	return err
+}
+
+// RevokeTerminalSessions cierra las sesiones abiertas en la terminal
+func (r *SessionRepository) RevokeTerminalSessions(ctx context.Context, terminalID uuid.UUID, reason string) error {
+	query := `
+		UPDATE auth_sessions SET revoked_at = NOW(), revoke_reason = $2
+		WHERE terminal_id = $1 AND revoked_at IS NULL
+	`
+	_, err := r.db.Exec(ctx, query, terminalID, reason)
+	return err
+}
+
+func (r *SessionRepository) CreateRefreshToken(ctx context.Context, t *models.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, session_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+	return r.db.QueryRow(ctx, query, t.ID, t.SessionID, t.TokenHash, t.ExpiresAt).Scan(&t.CreatedAt)
+}
+
+// GetRefreshTokenForUpdate busca el token por su hash y lo bloquea para que
+// dos renovaciones simultáneas no lo usen a la vez
+func (r *SessionRepository) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, token_hash, created_at, expires_at, used_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`
+	var t models.RefreshToken
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
+		&t.ID, &t.SessionID, &t.TokenHash, &t.CreatedAt, &t.ExpiresAt, &t.UsedAt,
+	)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *SessionRepository) MarkRefreshTokenUsed(ctx context.Context, tokenID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1`, tokenID)
+	return err
+}
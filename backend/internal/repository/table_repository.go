@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+type TableRepository struct {
+	db DBTX
+}
+
+func NewTableRepository(pool *pgxpool.Pool) *TableRepository {
+	return &TableRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *TableRepository) WithTx(tx pgx.Tx) *TableRepository {
+	return &TableRepository{db: tx}
+}
+
+func (r *TableRepository) CreateArea(ctx context.Context, a *models.TableArea) error {
+	query := `
+		INSERT INTO table_areas (id, restaurant_id, name, sort_order)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(ctx, query, a.ID, a.RestaurantID, a.Name, a.SortOrder).Scan(&a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *TableRepository) GetArea(ctx context.Context, restaurantID, areaID uuid.UUID) (*models.TableArea, error) {
+	query := `SELECT id, restaurant_id, name, sort_order, created_at, updated_at FROM table_areas WHERE id = $1 AND restaurant_id = $2`
+	var a models.TableArea
+	err := r.db.QueryRow(ctx, query, areaID, restaurantID).Scan(&a.ID, &a.RestaurantID, &a.Name, &a.SortOrder, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *TableRepository) ListAreas(ctx context.Context, restaurantID uuid.UUID) ([]*models.TableArea, error) {
+	query := `
+		SELECT id, restaurant_id, name, sort_order, created_at, updated_at
+		FROM table_areas
+		WHERE restaurant_id = $1
+		ORDER BY sort_order, name
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	areas := []*models.TableArea{}
+	for rows.Next() {
+		var a models.TableArea
+		if err := rows.Scan(&a.ID, &a.RestaurantID, &a.Name, &a.SortOrder, &a.CreatedAt, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		areas = append(areas, &a)
+	}
+	return areas, rows.Err()
+}
+
+func (r *TableRepository) UpdateArea(ctx context.Context, a *models.TableArea) error {
+	query := `
+		UPDATE table_areas SET name = $3, sort_order = $4
+		WHERE id = $1 AND restaurant_id = $2
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(ctx, query, a.ID, a.RestaurantID, a.Name, a.SortOrder).Scan(&a.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return errors.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteArea deja sin área a sus mesas
+func (r *TableRepository) DeleteArea(ctx context.Context, restaurantID, areaID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM table_areas WHERE id = $1 AND restaurant_id = $2`, areaID, restaurantID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// tableColumns incluye el resumen de cuentas abiertas de la mesa
+const tableColumns = `t.id, t.restaurant_id, t.area_id, t.name, t.seats, t.active, t.sort_order,
+		(SELECT COUNT(*) FROM sales s WHERE s.table_id = t.id AND s.status = 'pending'),
+		(SELECT COALESCE(SUM(s.total), 0) FROM sales s WHERE s.table_id = t.id AND s.status = 'pending'),
+		t.created_at, t.updated_at`
+
+func scanTable(row pgx.Row) (*models.Table, error) {
+	var t models.Table
+	err := row.Scan(&t.ID, &t.RestaurantID, &t.AreaID, &t.Name, &t.Seats, &t.Active, &t.SortOrder,
+		&t.OpenOrders, &t.OpenTotal, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TableRepository) CreateTable(ctx context.Context, t *models.Table) error {
+	query := `
+		INSERT INTO restaurant_tables (id, restaurant_id, area_id, name, seats, active, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(ctx, query, t.ID, t.RestaurantID, t.AreaID, t.Name, t.Seats, t.Active, t.SortOrder).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *TableRepository) GetTable(ctx context.Context, restaurantID, tableID uuid.UUID) (*models.Table, error) {
+	query := `SELECT ` + tableColumns + ` FROM restaurant_tables t WHERE t.id = $1 AND t.restaurant_id = $2`
+	return scanTable(r.db.QueryRow(ctx, query, tableID, restaurantID))
+}
+
+// ListTables devuelve las mesas por área y orden; areaID nil no filtra
+func (r *TableRepository) ListTables(ctx context.Context, restaurantID uuid.UUID, areaID *uuid.UUID, activeOnly bool) ([]*models.Table, error) {
+	query := `
+		SELECT ` + tableColumns + `
+		FROM restaurant_tables t
+		LEFT JOIN table_areas a ON a.id = t.area_id
+		WHERE t.restaurant_id = $1 AND ($2::uuid IS NULL OR t.area_id = $2)`
+	if activeOnly {
+		query += ` AND t.active = true`
+	}
+	query += ` ORDER BY a.sort_order NULLS LAST, a.name, t.sort_order, t.name`
+
+	rows, err := r.db.Query(ctx, query, restaurantID, areaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []*models.Table{}
+	for rows.Next() {
+		t, err := scanTable(rows)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+func (r *TableRepository) UpdateTable(ctx context.Context, t *models.Table) error {
+	query := `
+		UPDATE restaurant_tables SET area_id = $3, name = $4, seats = $5, active = $6, sort_order = $7
+		WHERE id = $1 AND restaurant_id = $2
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(ctx, query, t.ID, t.RestaurantID, t.AreaID, t.Name, t.Seats, t.Active, t.SortOrder).Scan(&t.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return errors.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteTable devuelve ErrConflict si la mesa ya tiene ventas
+func (r *TableRepository) DeleteTable(ctx context.Context, restaurantID, tableID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM restaurant_tables WHERE id = $1 AND restaurant_id = $2`, tableID, restaurantID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
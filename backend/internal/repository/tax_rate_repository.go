@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+type TaxRateRepository struct {
+	db DBTX
+}
+
+func NewTaxRateRepository(pool *pgxpool.Pool) *TaxRateRepository {
+	return &TaxRateRepository{db: pool}
+}
+
+func (r *TaxRateRepository) Create(ctx context.Context, t *models.TaxRate) error {
+	query := `INSERT INTO tax_rates (id, restaurant_id, name, rate_bps, exempt) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.Exec(ctx, query, t.ID, t.RestaurantID, t.Name, t.RateBps, t.Exempt)
+	return err
+}
+
+func (r *TaxRateRepository) GetByID(ctx context.Context, restaurantID, taxRateID uuid.UUID) (*models.TaxRate, error) {
+	query := `SELECT id, restaurant_id, name, rate_bps, exempt, created_at FROM tax_rates WHERE id = $1 AND restaurant_id = $2`
+	var t models.TaxRate
+	err := r.db.QueryRow(ctx, query, taxRateID, restaurantID).Scan(
+		&t.ID, &t.RestaurantID, &t.Name, &t.RateBps, &t.Exempt, &t.CreatedAt,
+	)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TaxRateRepository) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.TaxRate, error) {
+	query := `
+		SELECT id, restaurant_id, name, rate_bps, exempt, created_at
+		FROM tax_rates
+		WHERE restaurant_id = $1
+		ORDER BY rate_bps DESC, name
+	`
+	rows, err := r.db.Query(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []*models.TaxRate
+	for rows.Next() {
+		var t models.TaxRate
+		if err := rows.Scan(&t.ID, &t.RestaurantID, &t.Name, &t.RateBps, &t.Exempt, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, &t)
+	}
+	return rates, rows.Err()
+}
+
+func (r *TaxRateRepository) Update(ctx context.Context, t *models.TaxRate) error {
+	query := `UPDATE tax_rates SET name = $3, rate_bps = $4, exempt = $5 WHERE id = $1 AND restaurant_id = $2`
+	result, err := r.db.Exec(ctx, query, t.ID, t.RestaurantID, t.Name, t.RateBps, t.Exempt)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Delete elimina la tasa. Devuelve ErrConflict si algún producto, categoría o el
+// restaurante todavía la usan.
+func (r *TaxRateRepository) Delete(ctx context.Context, restaurantID, taxRateID uuid.UUID) error {
+	query := `DELETE FROM tax_rates WHERE id = $1 AND restaurant_id = $2`
+	result, err := r.db.Exec(ctx, query, taxRateID, restaurantID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+// TerminalRepository guarda las terminales compartidas del restaurante
+type TerminalRepository struct {
+	db DBTX
+}
+
+func NewTerminalRepository(pool *pgxpool.Pool) *TerminalRepository {
+	return &TerminalRepository{db: pool}
+}
+
+// WithTx devuelve una copia del repositorio que opera dentro de tx
+func (r *TerminalRepository) WithTx(tx pgx.Tx) *TerminalRepository {
+	return &TerminalRepository{db: tx}
+}
+
+const terminalColumns = `t.id, t.restaurant_id, t.name, t.token_hash, t.last_used_at, t.created_at, t.updated_at`
+
+func scanTerminal(row pgx.Row) (*models.Terminal, error) {
+	var t models.Terminal
+	err := row.Scan(&t.ID, &t.RestaurantID, &t.Name, &t.TokenHash, &t.LastUsedAt, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TerminalRepository) Create(ctx context.Context, t *models.Terminal) error {
+	query := `
+		INSERT INTO terminals (id, restaurant_id, name, token_hash)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(ctx, query, t.ID, t.RestaurantID, t.Name, t.TokenHash).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *TerminalRepository) Get(ctx context.Context, restaurantID, terminalID uuid.UUID) (*models.Terminal, error) {
+	query := `SELECT ` + terminalColumns + ` FROM terminals t WHERE t.id = $1 AND t.restaurant_id = $2`
+	return scanTerminal(r.db.QueryRow(ctx, query, terminalID, restaurantID))
+}
+
+// GetByTokenHash busca la terminal por el hash de su token; las de un
+// restaurante dado de baja no se encuentran
+func (r *TerminalRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.Terminal, error) {
+	query := `
+		SELECT ` + terminalColumns + `
+		FROM terminals t
+		JOIN restaurants r ON r.id = t.restaurant_id
+		WHERE t.token_hash = $1 AND r.deleted_at IS NULL
+	`
+	return scanTerminal(r.db.QueryRow(ctx, query, tokenHash))
+}
+
+func (r *TerminalRepository) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.Terminal, error) {
+	query := `SELECT ` + terminalColumns + ` FROM terminals t WHERE t.restaurant_id = $1 ORDER BY t.name`
+	rows, err := r.db.Query(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terminals := []*models.Terminal{}
+	for rows.Next() {
+		t, err := scanTerminal(rows)
+		if err != nil {
+			return nil, err
+		}
+		terminals = append(terminals, t)
+	}
+	return terminals, rows.Err()
+}
+
+func (r *TerminalRepository) Update(ctx context.Context, t *models.Terminal) error {
+	query := `
+		UPDATE terminals SET name = $3
+		WHERE id = $1 AND restaurant_id = $2
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(ctx, query, t.ID, t.RestaurantID, t.Name).Scan(&t.UpdatedAt)
+	if err != nil {
+		if isNoRows(err) {
+			return errors.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *TerminalRepository) Delete(ctx context.Context, restaurantID, terminalID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM terminals WHERE id = $1 AND restaurant_id = $2`, terminalID, restaurantID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *TerminalRepository) TouchLastUsed(ctx context.Context, terminalID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE terminals SET last_used_at = NOW() WHERE id = $1`, terminalID)
+	return err
+}
@@ -2,28 +2,48 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/pos-saas/restaurant-pos/internal/errors"
 	"github.com/pos-saas/restaurant-pos/internal/middleware"
 	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/permissions"
 	"github.com/pos-saas/restaurant-pos/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
+	txManager    *repository.TxManager
 	repo         *repository.AuthRepository
+	roleRepo     *repository.RoleRepository
+	sessionRepo  *repository.SessionRepository
+	terminalRepo *repository.TerminalRepository
 	jwtSecret    string
-	jwtExpHours  int
+	accessTTL    time.Duration
+	refreshTTL   time.Duration
 }
 
-func NewAuthService(repo *repository.AuthRepository, jwtSecret string, jwtExpHours int) *AuthService {
+func NewAuthService(txManager *repository.TxManager, repo *repository.AuthRepository, roleRepo *repository.RoleRepository, sessionRepo *repository.SessionRepository, terminalRepo *repository.TerminalRepository, jwtSecret string, accessTTL, refreshTTL time.Duration) *AuthService {
 	return &AuthService{
-		repo:        repo,
-		jwtSecret:   jwtSecret,
-		jwtExpHours: jwtExpHours,
+		txManager:    txManager,
+		repo:         repo,
+		roleRepo:     roleRepo,
+		sessionRepo:  sessionRepo,
+		terminalRepo: terminalRepo,
+		jwtSecret:    jwtSecret,
+		accessTTL:    accessTTL,
+		refreshTTL:   refreshTTL,
 	}
 }
 
@@ -34,35 +54,79 @@ type RegisterInput struct {
 	Phone          string `json:"phone"`
 	Address        string `json:"address"`
 	TaxID          string `json:"tax_id"`
+	Timezone       string `json:"timezone"` // IANA, ej. America/Mexico_City
 }
 
+// defaultTimezone es la zona horaria de un restaurante que no indica una al registrarse
+const defaultTimezone = "America/Mexico_City"
+
+// defaultMaxDiscountBps es el tope inicial de descuento para cajeros (10%)
+const defaultMaxDiscountBps = 1000
+
 type LoginInput struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	// Restaurant es el slug del restaurante; solo hace falta si el email
+	// existe en más de uno
+	Restaurant string `json:"restaurant"`
 }
 
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// PINSwitchInput: en la terminal se elige el usuario de la lista y se teclea su PIN
+type PINSwitchInput struct {
+	UserID string `json:"user_id" binding:"required"`
+	PIN    string `json:"pin" binding:"required"`
+}
+
+// Tras pinMaxAttempts PIN incorrectos seguidos el usuario no puede entrar con
+// PIN durante pinLockout
+const (
+	pinMaxAttempts = 5
+	pinLockout     = 15 * time.Minute
+)
+
+// AuthResponse: token es el access token de vida corta; refresh_token sirve
+// una sola vez para obtener un par nuevo en /auth/refresh
 type AuthResponse struct {
-	Token        string           `json:"token"`
-	ExpiresAt    time.Time        `json:"expires_at"`
-	User         UserResponse     `json:"user"`
-	Restaurant   RestaurantResponse `json:"restaurant"`
+	Token            string             `json:"token"`
+	ExpiresAt        time.Time          `json:"expires_at"`
+	RefreshToken     string             `json:"refresh_token"`
+	RefreshExpiresAt time.Time          `json:"refresh_expires_at"`
+	User             UserResponse       `json:"user"`
+	Restaurant       RestaurantResponse `json:"restaurant"`
 }
 
 type UserResponse struct {
-	ID    string `json:"id"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID          string   `json:"id"`
+	Email       string   `json:"email"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
 }
 
 type RestaurantResponse struct {
 	ID    string `json:"id"`
 	Name  string `json:"name"`
+	Slug  string `json:"slug"`
 	Email string `json:"email"`
 }
 
+// RestaurantChoiceError indica que las credenciales valen en varios
+// restaurantes; el cliente repite el login con el slug del elegido
+type RestaurantChoiceError struct {
+	Restaurants []RestaurantResponse
+}
+
+func (e *RestaurantChoiceError) Error() string {
+	return "el usuario pertenece a varios restaurantes, indica restaurant"
+}
+
 func (s *AuthService) Register(ctx context.Context, input RegisterInput) (*AuthResponse, error) {
+	email := normalizeEmail(input.Email)
 	// Verificar si el email del restaurante ya existe
-	_, err := s.repo.GetRestaurantByEmail(ctx, input.Email)
+	_, err := s.repo.GetRestaurantByEmail(ctx, email)
 	if err == nil {
 		return nil, NewAppError(errors.ErrConflict, 409, "el restaurante ya está registrado con ese email")
 	}
@@ -70,6 +134,14 @@ func (s *AuthService) Register(ctx context.Context, input RegisterInput) (*AuthR
 		return nil, err
 	}
 
+	timezone := input.Timezone
+	if timezone == "" {
+		timezone = defaultTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, NewValidationError("timezone", "zona horaria inválida")
+	}
+
 	// Hash de la contraseña
 	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -79,107 +151,430 @@ func (s *AuthService) Register(ctx context.Context, input RegisterInput) (*AuthR
 	restID := uuid.New()
 	userID := uuid.New()
 
+	slug, err := s.newSlug(ctx, input.RestaurantName, restID)
+	if err != nil {
+		return nil, err
+	}
+
 	restaurant := &models.Restaurant{
-		ID:      restID,
-		Name:    input.RestaurantName,
-		Email:   input.Email,
-		Phone:   input.Phone,
-		Address: input.Address,
-		TaxID:   input.TaxID,
+		ID:       restID,
+		Name:     input.RestaurantName,
+		Slug:     slug,
+		Email:    email,
+		Phone:    input.Phone,
+		Address:  input.Address,
+		TaxID:    input.TaxID,
+		Timezone: timezone,
+		// Por omisión los precios del menú ya incluyen impuestos
+		PricesIncludeTax: true,
+		MaxDiscountBps:   defaultMaxDiscountBps,
 	}
 
 	user := &models.User{
 		ID:           userID,
 		RestaurantID: restID,
-		Email:        input.Email,
+		Email:        email,
 		PasswordHash: string(hash),
-		Role:         "admin",
+		Role:         permissions.RoleAdmin,
 		Active:       true,
 	}
 
-	if err := s.repo.CreateRestaurant(ctx, restaurant); err != nil {
+	// Restaurante y usuario admin se crean juntos o no se crea ninguno
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.CreateRestaurant(ctx, restaurant); err != nil {
+			return err
+		}
+		return repo.CreateUser(ctx, user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := s.repo.CreateUser(ctx, user); err != nil {
+	perms, _ := permissions.Builtin(permissions.RoleAdmin)
+	return s.newSession(ctx, user, perms, RestaurantResponse{
+		ID:    restaurant.ID.String(),
+		Name:  restaurant.Name,
+		Slug:  restaurant.Slug,
+		Email: restaurant.Email,
+	})
+}
+
+// Login busca al usuario por su propio email en todos los restaurantes. Si la
+// contraseña vale en más de uno y no se indicó restaurant, devuelve
+// RestaurantChoiceError con las opciones.
+func (s *AuthService) Login(ctx context.Context, input LoginInput) (*AuthResponse, error) {
+	slug := strings.ToLower(strings.TrimSpace(input.Restaurant))
+	candidates, err := s.repo.ListLoginCandidates(ctx, normalizeEmail(input.Email), slug)
+	if err != nil {
 		return nil, err
 	}
 
-	token, exp, err := s.generateToken(user)
+	// Solo cuentan las cuentas cuya contraseña coincide: a quien no la conoce
+	// no se le revela en qué restaurantes existe el email
+	var matches, active []*repository.LoginCandidate
+	for _, c := range candidates {
+		if bcrypt.CompareHashAndPassword([]byte(c.User.PasswordHash), []byte(input.Password)) != nil {
+			continue
+		}
+		matches = append(matches, c)
+		if c.User.Active {
+			active = append(active, c)
+		}
+	}
+
+	switch {
+	case len(matches) == 0:
+		return nil, NewAppError(errors.ErrInvalidCredentials, 401, "credenciales inválidas")
+	case len(active) == 0:
+		return nil, NewAppError(errors.ErrForbidden, 403, "usuario inactivo")
+	case len(active) > 1:
+		choice := &RestaurantChoiceError{}
+		for _, c := range active {
+			choice.Restaurants = append(choice.Restaurants, RestaurantResponse{
+				ID:    c.User.RestaurantID.String(),
+				Name:  c.RestaurantName,
+				Slug:  c.RestaurantSlug,
+				Email: c.RestaurantEmail,
+			})
+		}
+		return nil, choice
+	}
+
+	c := active[0]
+	user := &c.User
+	perms, err := rolePermissions(ctx, s.roleRepo, user.RestaurantID, user.Role)
 	if err != nil {
 		return nil, err
 	}
-
-	return &AuthResponse{
-		Token:     token,
-		ExpiresAt: exp,
-		User: UserResponse{
-			ID:    user.ID.String(),
-			Email: user.Email,
-			Role:  user.Role,
-		},
-		Restaurant: RestaurantResponse{
-			ID:    restaurant.ID.String(),
-			Name:  restaurant.Name,
-			Email: restaurant.Email,
-		},
-	}, nil
+	return s.newSession(ctx, user, perms, RestaurantResponse{
+		ID:    user.RestaurantID.String(),
+		Name:  c.RestaurantName,
+		Slug:  c.RestaurantSlug,
+		Email: c.RestaurantEmail,
+	})
 }
 
-func (s *AuthService) Login(ctx context.Context, input LoginInput) (*AuthResponse, error) {
-	// Buscar restaurante por email (el login usa email del restaurante como identificador)
-	restaurant, err := s.repo.GetRestaurantByEmail(ctx, input.Email)
-	if err != nil {
-		if errors.Is(err, errors.ErrNotFound) {
-			return nil, NewAppError(errors.ErrInvalidCredentials, 401, "credenciales inválidas")
+// Refresh canjea un refresh token por un par nuevo. Cada refresh token sirve
+// una vez: si llega uno ya usado, alguien lo copió y se cierra la sesión
+// completa. Los permisos se vuelven a calcular, así que un cambio de rol se
+// aplica en la siguiente renovación.
+func (s *AuthService) Refresh(ctx context.Context, input RefreshInput) (*AuthResponse, error) {
+	var (
+		user       *models.User
+		sessionID  uuid.UUID
+		refresh    string
+		refreshExp time.Time
+		reused     bool
+	)
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.sessionRepo.WithTx(tx)
+		token, err := repo.GetRefreshTokenForUpdate(ctx, hashToken(input.RefreshToken))
+		if err != nil {
+			if errors.Is(err, errors.ErrNotFound) {
+				return invalidSessionError()
+			}
+			return err
+		}
+		session, err := repo.GetSession(ctx, token.SessionID)
+		if err != nil {
+			return err
+		}
+		if session.RevokedAt != nil {
+			return invalidSessionError()
+		}
+		if token.UsedAt != nil {
+			// La revocación debe confirmarse: el error se devuelve fuera de la transacción
+			reused = true
+			return repo.RevokeSession(ctx, session.ID, "reuse")
+		}
+		if time.Now().After(token.ExpiresAt) {
+			return invalidSessionError()
+		}
+
+		user, err = s.repo.WithTx(tx).GetUserByID(ctx, session.RestaurantID, session.UserID)
+		if err != nil {
+			if errors.Is(err, errors.ErrNotFound) {
+				return invalidSessionError()
+			}
+			return err
+		}
+		if !user.Active {
+			return NewAppError(errors.ErrForbidden, 403, "usuario inactivo")
 		}
+
+		if err := repo.MarkRefreshTokenUsed(ctx, token.ID); err != nil {
+			return err
+		}
+		sessionID = session.ID
+		refresh, refreshExp, err = s.issueRefreshToken(ctx, repo, session.ID)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
+	if reused {
+		return nil, NewAppError(errors.ErrUnauthorized, 401, "refresh token reutilizado; la sesión se cerró por seguridad")
+	}
 
-	user, err := s.repo.GetUserByEmail(ctx, restaurant.ID, input.Email)
+	perms, err := rolePermissions(ctx, s.roleRepo, user.RestaurantID, user.Role)
 	if err != nil {
-		if errors.Is(err, errors.ErrNotFound) {
-			return nil, NewAppError(errors.ErrInvalidCredentials, 401, "credenciales inválidas")
+		return nil, err
+	}
+	restaurant, err := s.repo.GetRestaurantByID(ctx, user.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.authResponse(user, perms, sessionID, refresh, refreshExp, RestaurantResponse{
+		ID:    restaurant.ID.String(),
+		Name:  restaurant.Name,
+		Slug:  restaurant.Slug,
+		Email: restaurant.Email,
+	})
+}
+
+// Logout cierra la sesión del refresh token. Un token desconocido no es un
+// error: la sesión ya no existe
+func (s *AuthService) Logout(ctx context.Context, input RefreshInput) error {
+	return s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.sessionRepo.WithTx(tx)
+		token, err := repo.GetRefreshTokenForUpdate(ctx, hashToken(input.RefreshToken))
+		if err != nil {
+			if errors.Is(err, errors.ErrNotFound) {
+				return nil
+			}
+			return err
 		}
+		return repo.RevokeSession(ctx, token.SessionID, "logout")
+	})
+}
+
+// SwitchUser cambia el usuario activo de una terminal compartida con su PIN,
+// sin pedir la contraseña. La sesión del usuario anterior en la terminal se
+// cierra, así que lo que se cobre desde ahí queda a nombre del nuevo.
+func (s *AuthService) SwitchUser(ctx context.Context, terminalToken string, input PINSwitchInput) (*AuthResponse, error) {
+	userID, err := uuid.Parse(input.UserID)
+	if err != nil {
+		return nil, NewValidationError("user_id", "ID inválido")
+	}
+	terminal, err := terminalByToken(ctx, s.terminalRepo, terminalToken)
+	if err != nil {
 		return nil, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)); err != nil {
-		return nil, NewAppError(errors.ErrInvalidCredentials, 401, "credenciales inválidas")
+	var (
+		user       *models.User
+		sessionID  uuid.UUID
+		refresh    string
+		refreshExp time.Time
+		failure    *AppError
+	)
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.repo.WithTx(tx)
+		pin, err := repo.GetUserPINForUpdate(ctx, terminal.RestaurantID, userID)
+		if err != nil {
+			return err
+		}
+		if !pin.User.Active || pin.PINHash == "" {
+			return NewAppError(errors.ErrForbidden, 403, "el usuario no puede entrar con PIN")
+		}
+
+		now := time.Now()
+		if pin.LockedUntil != nil && now.Before(*pin.LockedUntil) {
+			return pinLockedError(*pin.LockedUntil, now)
+		}
+		if bcrypt.CompareHashAndPassword([]byte(pin.PINHash), []byte(input.PIN)) != nil {
+			attempts := pin.FailedAttempts + 1
+			var lockedUntil *time.Time
+			if attempts >= pinMaxAttempts {
+				until := now.Add(pinLockout)
+				lockedUntil = &until
+				attempts = 0
+				failure = pinLockedError(until, now)
+			} else {
+				failure = NewAppError(errors.ErrInvalidCredentials, 401,
+					fmt.Sprintf("PIN incorrecto, quedan %d intentos", pinMaxAttempts-attempts))
+			}
+			// El intento debe confirmarse: el error se devuelve fuera de la transacción
+			return repo.UpdatePINAttempts(ctx, userID, attempts, lockedUntil)
+		}
+		if pin.FailedAttempts > 0 || pin.LockedUntil != nil {
+			if err := repo.UpdatePINAttempts(ctx, userID, 0, nil); err != nil {
+				return err
+			}
+		}
+
+		sessions := s.sessionRepo.WithTx(tx)
+		if err := sessions.RevokeTerminalSessions(ctx, terminal.ID, "terminal_switch"); err != nil {
+			return err
+		}
+		user = &pin.User
+		session := &models.AuthSession{ID: uuid.New(), RestaurantID: user.RestaurantID, UserID: user.ID, TerminalID: &terminal.ID}
+		refresh, refreshExp, err = s.openSession(ctx, sessions, session)
+		if err != nil {
+			return err
+		}
+		sessionID = session.ID
+		return s.terminalRepo.WithTx(tx).TouchLastUsed(ctx, terminal.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if failure != nil {
+		return nil, failure
 	}
 
-	if !user.Active {
-		return nil, NewAppError(errors.ErrForbidden, 403, "usuario inactivo")
+	perms, err := rolePermissions(ctx, s.roleRepo, user.RestaurantID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	restaurant, err := s.repo.GetRestaurantByID(ctx, user.RestaurantID)
+	if err != nil {
+		return nil, err
 	}
+	return s.authResponse(user, perms, sessionID, refresh, refreshExp, RestaurantResponse{
+		ID:    restaurant.ID.String(),
+		Name:  restaurant.Name,
+		Slug:  restaurant.Slug,
+		Email: restaurant.Email,
+	})
+}
 
-	token, exp, err := s.generateToken(user)
+func pinLockedError(until, now time.Time) *AppError {
+	minutes := int(math.Ceil(until.Sub(now).Minutes()))
+	return NewAppError(errors.ErrTooManyRequests, 429,
+		fmt.Sprintf("PIN bloqueado por demasiados intentos, intenta de nuevo en %d min", minutes))
+}
+
+// ValidateSession implementa middleware.SessionValidator: el access token solo
+// vale mientras su sesión siga abierta y el usuario activo
+func (s *AuthService) ValidateSession(ctx context.Context, sessionID, userID string) error {
+	sid, err := uuid.Parse(sessionID)
+	if err != nil {
+		return errors.ErrUnauthorized
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return errors.ErrUnauthorized
+	}
+	active, err := s.sessionRepo.IsActive(ctx, sid, uid)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return errors.ErrUnauthorized
+	}
+	return nil
+}
+
+func invalidSessionError() *AppError {
+	return NewAppError(errors.ErrUnauthorized, 401, "sesión inválida o expirada")
+}
+
+// newSession abre una sesión para el usuario y emite sus primeros tokens
+func (s *AuthService) newSession(ctx context.Context, user *models.User, perms []string, restaurant RestaurantResponse) (*AuthResponse, error) {
+	session := &models.AuthSession{ID: uuid.New(), RestaurantID: user.RestaurantID, UserID: user.ID}
+	var (
+		refresh    string
+		refreshExp time.Time
+	)
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		var err error
+		refresh, refreshExp, err = s.openSession(ctx, s.sessionRepo.WithTx(tx), session)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	return s.authResponse(user, perms, session.ID, refresh, refreshExp, restaurant)
+}
+
+// openSession guarda la sesión y emite su primer refresh token
+func (s *AuthService) openSession(ctx context.Context, repo *repository.SessionRepository, session *models.AuthSession) (string, time.Time, error) {
+	if err := repo.CreateSession(ctx, session); err != nil {
+		return "", time.Time{}, err
+	}
+	return s.issueRefreshToken(ctx, repo, session.ID)
+}
 
+func (s *AuthService) authResponse(user *models.User, perms []string, sessionID uuid.UUID, refresh string, refreshExp time.Time, restaurant RestaurantResponse) (*AuthResponse, error) {
+	token, exp, err := s.generateToken(user, perms, sessionID)
+	if err != nil {
+		return nil, err
+	}
 	return &AuthResponse{
-		Token:     token,
-		ExpiresAt: exp,
+		Token:            token,
+		ExpiresAt:        exp,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: refreshExp,
 		User: UserResponse{
-			ID:    user.ID.String(),
-			Email: user.Email,
-			Role:  user.Role,
-		},
-		Restaurant: RestaurantResponse{
-			ID:    restaurant.ID.String(),
-			Name:  restaurant.Name,
-			Email: restaurant.Email,
+			ID:          user.ID.String(),
+			Email:       user.Email,
+			Role:        user.Role,
+			Permissions: perms,
 		},
+		Restaurant: restaurant,
 	}, nil
 }
 
-func (s *AuthService) generateToken(user *models.User) (string, time.Time, error) {
-	exp := time.Now().Add(time.Duration(s.jwtExpHours) * time.Hour)
+// issueRefreshToken genera un refresh token aleatorio para la sesión; en la
+// base solo queda su hash
+func (s *AuthService) issueRefreshToken(ctx context.Context, repo *repository.SessionRepository, sessionID uuid.UUID) (string, time.Time, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	rt := &models.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: sessionID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}
+	if err := repo.CreateRefreshToken(ctx, rt); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, rt.ExpiresAt, nil
+}
+
+// randomToken genera un token opaco de 32 bytes aleatorios
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newSlug arma el slug del restaurante a partir de su nombre; si ya está en
+// uso le agrega el inicio del ID
+func (s *AuthService) newSlug(ctx context.Context, name string, restaurantID uuid.UUID) (string, error) {
+	slug := slugify(name)
+	exists, err := s.repo.RestaurantSlugExists(ctx, slug)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		slug += "-" + strings.ReplaceAll(restaurantID.String(), "-", "")[:6]
+	}
+	return slug, nil
+}
+
+// generateToken firma el access token con los permisos del rol y la sesión
+// a la que pertenece
+func (s *AuthService) generateToken(user *models.User, perms []string, sessionID uuid.UUID) (string, time.Time, error) {
+	exp := time.Now().Add(s.accessTTL)
 	claims := &middleware.Claims{
 		UserID:       user.ID.String(),
+		SessionID:    sessionID.String(),
 		RestaurantID: user.RestaurantID.String(),
 		Email:        user.Email,
 		Role:         user.Role,
+		Permissions:  perms,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(exp),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -194,3 +589,34 @@ func (s *AuthService) generateToken(user *models.User) (string, time.Time, error
 
 	return tokenString, exp, nil
 }
+
+// slugPattern es el formato válido de un slug: minúsculas, dígitos y guiones
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// maxSlugLength deja lugar al sufijo que se agrega cuando el slug ya existe
+const maxSlugLength = 60
+
+var slugAccents = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n")
+
+// slugify convierte un nombre en slug, p. ej. "Café Olé" → "cafe-ole"
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range slugAccents.Replace(strings.ToLower(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimSuffix(slug[:maxSlugLength], "-")
+	}
+	if slug == "" {
+		slug = "restaurante"
+	}
+	return slug
+}
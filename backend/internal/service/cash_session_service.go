@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+	"github.com/pos-saas/restaurant-pos/internal/permissions"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+type CashSessionService struct {
+	txManager       *repository.TxManager
+	cashSessionRepo *repository.CashSessionRepository
+}
+
+func NewCashSessionService(txManager *repository.TxManager, cashSessionRepo *repository.CashSessionRepository) *CashSessionService {
+	return &CashSessionService{
+		txManager:       txManager,
+		cashSessionRepo: cashSessionRepo,
+	}
+}
+
+type OpenCashSessionInput struct {
+	OpeningFloat money.Money `json:"opening_float" binding:"gte=0"`
+	Notes        string      `json:"notes"`
+}
+
+type CloseCashSessionInput struct {
+	CountedCash *money.Money `json:"counted_cash" binding:"required,gte=0"`
+	Notes       string       `json:"notes"`
+}
+
+const cashSessionListLimit = 50
+
+// Open abre un turno para el cajero con el fondo inicial indicado
+func (s *CashSessionService) Open(ctx context.Context, restaurantID, userID uuid.UUID, input OpenCashSessionInput) (*models.CashSession, error) {
+	session := &models.CashSession{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		UserID:       userID,
+		Status:       models.CashSessionOpen,
+		OpeningFloat: input.OpeningFloat,
+		Notes:        strings.TrimSpace(input.Notes),
+	}
+	if err := s.cashSessionRepo.Create(ctx, session); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya tienes un turno de caja abierto")
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+// Current devuelve el turno abierto del usuario con su corte parcial
+func (s *CashSessionService) Current(ctx context.Context, restaurantID, userID uuid.UUID) (*models.CashSessionSummary, error) {
+	session, err := s.cashSessionRepo.GetOpenByUser(ctx, restaurantID, userID)
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil, NewAppError(errors.ErrNotFound, 404, "no tienes un turno de caja abierto")
+		}
+		return nil, err
+	}
+	return s.cashSessionRepo.Summary(ctx, session)
+}
+
+// GetByID devuelve el turno con su corte. Sin cash:manage solo se ven los turnos propios.
+func (s *CashSessionService) GetByID(ctx context.Context, restaurantID, sessionID, userID uuid.UUID, perms permissions.Set) (*models.CashSessionSummary, error) {
+	session, err := s.cashSessionRepo.GetByID(ctx, restaurantID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID && !perms.Has(permissions.CashManage) {
+		return nil, NewAppError(errors.ErrForbidden, 403, "no puedes ver turnos de otro cajero")
+	}
+	return s.cashSessionRepo.Summary(ctx, session)
+}
+
+func (s *CashSessionService) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.CashSession, error) {
+	return s.cashSessionRepo.List(ctx, restaurantID, cashSessionListLimit)
+}
+
+// Close cierra el turno: calcula el efectivo esperado, guarda lo contado y la
+// diferencia. Solo el dueño del turno o quien tiene cash:manage pueden cerrarlo.
+func (s *CashSessionService) Close(ctx context.Context, restaurantID, sessionID, userID uuid.UUID, perms permissions.Set, input CloseCashSessionInput) (*models.CashSessionSummary, error) {
+	var summary *models.CashSessionSummary
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.cashSessionRepo.WithTx(tx)
+
+		session, err := repo.GetByIDForUpdate(ctx, restaurantID, sessionID)
+		if err != nil {
+			return err
+		}
+		if session.UserID != userID && !perms.Has(permissions.CashManage) {
+			return NewAppError(errors.ErrForbidden, 403, "solo el cajero del turno o un encargado pueden cerrarlo")
+		}
+		if session.Status != models.CashSessionOpen {
+			return NewAppError(errors.ErrConflict, 409, "el turno ya está cerrado")
+		}
+
+		summary, err = repo.Summary(ctx, session)
+		if err != nil {
+			return err
+		}
+
+		expected := summary.ExpectedCash
+		counted := *input.CountedCash
+		difference := counted - expected
+		session.ExpectedCash = &expected
+		session.CountedCash = &counted
+		session.Difference = &difference
+		session.ClosedBy = &userID
+		if notes := strings.TrimSpace(input.Notes); notes != "" {
+			session.Notes = notes
+		}
+		return repo.Close(ctx, session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
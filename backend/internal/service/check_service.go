@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/events"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+	"github.com/pos-saas/restaurant-pos/internal/permissions"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+// CheckService divide una cuenta abierta en subcuentas que se cobran por separado
+type CheckService struct {
+	txManager    *repository.TxManager
+	saleRepo     *repository.SaleRepository
+	checkRepo    *repository.SaleCheckRepository
+	authRepo     *repository.AuthRepository
+	taxRateRepo  *repository.TaxRateRepository
+	categoryRepo *repository.CategoryRepository
+	// orders completa la venta al cobrar la última subcuenta
+	orders *OrderService
+}
+
+func NewCheckService(txManager *repository.TxManager, saleRepo *repository.SaleRepository, checkRepo *repository.SaleCheckRepository, authRepo *repository.AuthRepository, taxRateRepo *repository.TaxRateRepository, categoryRepo *repository.CategoryRepository, orders *OrderService) *CheckService {
+	return &CheckService{
+		txManager:    txManager,
+		saleRepo:     saleRepo,
+		checkRepo:    checkRepo,
+		authRepo:     authRepo,
+		taxRateRepo:  taxRateRepo,
+		categoryRepo: categoryRepo,
+		orders:       orders,
+	}
+}
+
+// CheckItemInput asigna a la subcuenta quantity unidades de la línea (todas si
+// se omite) divididas en parts partes iguales: una pizza entre tres es
+// quantity 1 y parts 3 en cada subcuenta
+type CheckItemInput struct {
+	ItemID   string `json:"item_id" binding:"required"`
+	Quantity int    `json:"quantity" binding:"omitempty,gt=0"`
+	Parts    int    `json:"parts" binding:"omitempty,gt=0"`
+}
+
+type CheckInput struct {
+	Items []CheckItemInput `json:"items" binding:"required,min=1,dive"`
+}
+
+// SplitChecksInput: checks reparte la cuenta por líneas y parts la divide en
+// partes iguales. El descuento del ticket se aplica antes de repartir.
+type SplitChecksInput struct {
+	Checks   []CheckInput   `json:"checks" binding:"omitempty,min=2,dive"`
+	Parts    int            `json:"parts" binding:"omitempty,gte=2,lte=50"`
+	Discount *DiscountInput `json:"discount"`
+}
+
+type PayCheckInput struct {
+	Payments []SalePaymentInput `json:"payments" binding:"required,min=1,dive"`
+	Tip      *TipInput          `json:"tip"`
+}
+
+// checkShare es la fracción num/den de una línea que va a la subcuenta check
+type checkShare struct {
+	check    int
+	num, den int64
+}
+
+// Split divide la cuenta en subcuentas y reemplaza una división previa que aún
+// no tenga cobros. Cada línea debe quedar repartida completa.
+func (s *CheckService) Split(ctx context.Context, restaurantID, orderID uuid.UUID, perms permissions.Set, input SplitChecksInput) ([]*models.SaleCheck, error) {
+	if (len(input.Checks) == 0) == (input.Parts == 0) {
+		return nil, NewValidationError("checks", "indica las subcuentas o el número de partes iguales")
+	}
+	restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	taxes, err := newTaxResolver(ctx, restaurant, s.taxRateRepo, s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+		checkRepo := s.checkRepo.WithTx(tx)
+		sale, err := lockOpenOrder(ctx, saleRepo, restaurantID, orderID)
+		if err != nil {
+			return err
+		}
+		if err := resetChecks(ctx, checkRepo, sale.ID); err != nil {
+			return err
+		}
+		lines, err := loadLines(ctx, saleRepo, sale.ID)
+		if err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			return NewValidationError("items", "la cuenta no tiene productos")
+		}
+
+		listTotal, err := reprice(ctx, saleRepo, taxes, sale, lines, input.Discount)
+		if err != nil {
+			return err
+		}
+		if err := checkDiscountLimit(perms, restaurant.MaxDiscountBps, sale.DiscountTotal, listTotal); err != nil {
+			return err
+		}
+		if err := saleRepo.UpdateTotals(ctx, sale); err != nil {
+			return err
+		}
+
+		numChecks := input.Parts
+		var shares map[uuid.UUID][]checkShare
+		if input.Parts > 0 {
+			shares = equalShares(lines, input.Parts)
+		} else {
+			numChecks = len(input.Checks)
+			if shares, err = assignedShares(lines, input.Checks); err != nil {
+				return err
+			}
+		}
+
+		checks := make([]*models.SaleCheck, numChecks)
+		for i := range checks {
+			checks[i] = &models.SaleCheck{
+				ID:     uuid.New(),
+				SaleID: sale.ID,
+				Number: i + 1,
+				Status: models.SaleCheckOpen,
+			}
+		}
+		var parts []*models.SaleCheckItem
+		for _, line := range lines {
+			lineShares := shares[line.ID]
+			weights := shareWeights(lineShares)
+			totals := allocateDiscount(line.Total, weights)
+			lineTaxes := allocateDiscount(line.TaxAmount, weights)
+			for i, sh := range lineShares {
+				check := checks[sh.check]
+				check.Total += totals[i]
+				check.TaxTotal += lineTaxes[i]
+				parts = append(parts, &models.SaleCheckItem{
+					ID:         uuid.New(),
+					CheckID:    check.ID,
+					SaleItemID: line.ID,
+					ShareNum:   int(sh.num),
+					ShareDen:   int(sh.den),
+					TaxAmount:  lineTaxes[i],
+					Total:      totals[i],
+				})
+			}
+		}
+
+		for _, check := range checks {
+			if check.Total <= 0 {
+				return NewValidationError("checks", "cada subcuenta debe tener un importe a cobrar")
+			}
+			if err := checkRepo.Create(ctx, check); err != nil {
+				return err
+			}
+		}
+		for _, part := range parts {
+			if err := checkRepo.CreateItem(ctx, part); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.List(ctx, restaurantID, orderID)
+}
+
+// List devuelve las subcuentas de la venta con sus partes y pagos
+func (s *CheckService) List(ctx context.Context, restaurantID, orderID uuid.UUID) ([]*models.SaleCheck, error) {
+	if _, err := s.saleRepo.GetByID(ctx, restaurantID, orderID); err != nil {
+		return nil, err
+	}
+	checks, err := s.checkRepo.ListBySale(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.SaleCheck, len(checks))
+	for _, check := range checks {
+		byID[check.ID] = check
+	}
+
+	items, err := s.checkRepo.ListItemsBySale(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, it := range items {
+		if check, ok := byID[it.CheckID]; ok {
+			check.Items = append(check.Items, it)
+		}
+	}
+	payments, err := s.saleRepo.GetPayments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range payments {
+		if p.CheckID == nil {
+			continue
+		}
+		if check, ok := byID[*p.CheckID]; ok {
+			check.Payments = append(check.Payments, p)
+		}
+	}
+	return checks, nil
+}
+
+func (s *CheckService) Get(ctx context.Context, restaurantID, orderID, checkID uuid.UUID) (*models.SaleCheck, error) {
+	checks, err := s.List(ctx, restaurantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, check := range checks {
+		if check.ID == checkID {
+			return check, nil
+		}
+	}
+	return nil, errors.ErrNotFound
+}
+
+// Pay cobra una subcuenta: sus pagos quedan en el turno de quien cobra, aunque
+// otra subcuenta la cobre otro cajero. Al cobrar la última la venta se
+// completa en el turno de ese cajero y se descuenta el inventario.
+func (s *CheckService) Pay(ctx context.Context, restaurantID, orderID, checkID, userID uuid.UUID, input PayCheckInput) (*models.SaleCheck, error) {
+	completed := false
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+		checkRepo := s.checkRepo.WithTx(tx)
+		sale, err := lockOpenOrder(ctx, saleRepo, restaurantID, orderID)
+		if err != nil {
+			return err
+		}
+		check, err := checkRepo.GetByIDForUpdate(ctx, sale.ID, checkID)
+		if err != nil {
+			return err
+		}
+		if check.Status != models.SaleCheckOpen {
+			return NewAppError(errors.ErrConflict, 409, "la subcuenta ya fue cobrada")
+		}
+		session, err := s.orders.sales.openSession(ctx, tx, restaurantID, userID)
+		if err != nil {
+			return err
+		}
+
+		// Los pagos se validan contra el total de la subcuenta
+		due := &models.Sale{ID: sale.ID, Total: check.Total}
+		payments, err := buildPayments(due, input.Payments, input.Tip)
+		if err != nil {
+			return err
+		}
+		for _, payment := range payments {
+			payment.CheckID = &check.ID
+			payment.CashSessionID = &session.ID
+			if err := saleRepo.CreatePayment(ctx, payment); err != nil {
+				return err
+			}
+		}
+		check.TipTotal = due.TipTotal
+		check.PaidBy = &userID
+		if err := checkRepo.MarkPaid(ctx, check); err != nil {
+			return err
+		}
+
+		_, open, err := checkRepo.CountPaid(ctx, sale.ID)
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return nil
+		}
+		checks, err := checkRepo.ListBySale(ctx, sale.ID)
+		if err != nil {
+			return err
+		}
+		sale.TipTotal = 0
+		for _, c := range checks {
+			sale.TipTotal += c.TipTotal
+		}
+		if err := saleRepo.UpdateTotals(ctx, sale); err != nil {
+			return err
+		}
+		items, err := loadLines(ctx, saleRepo, sale.ID)
+		if err != nil {
+			return err
+		}
+		completed = true
+		return s.orders.complete(ctx, tx, sale, items, session.ID, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if completed {
+		sale, err := s.saleRepo.GetByID(ctx, restaurantID, orderID)
+		if err != nil {
+			return nil, err
+		}
+		s.orders.publisher.Publish(events.New(events.SaleCreated, restaurantID, sale))
+	}
+	return s.Get(ctx, restaurantID, orderID, checkID)
+}
+
+// equalShares asigna a cada subcuenta 1/parts de cada línea
+func equalShares(lines []*models.SaleItem, parts int) map[uuid.UUID][]checkShare {
+	shares := make(map[uuid.UUID][]checkShare, len(lines))
+	for j, line := range lines {
+		// Se rota el orden por línea para que los centavos sobrantes del
+		// reparto no caigan siempre en la misma subcuenta
+		for k := 0; k < parts; k++ {
+			shares[line.ID] = append(shares[line.ID], checkShare{check: (j + k) % parts, num: 1, den: int64(parts)})
+		}
+	}
+	return shares
+}
+
+// assignedShares convierte lo asignado a cada subcuenta en fracciones de
+// línea y valida que cada línea quede repartida exactamente una vez
+func assignedShares(lines []*models.SaleItem, checks []CheckInput) (map[uuid.UUID][]checkShare, error) {
+	byID := make(map[uuid.UUID]*models.SaleItem, len(lines))
+	for _, line := range lines {
+		byID[line.ID] = line
+	}
+
+	shares := make(map[uuid.UUID][]checkShare, len(lines))
+	for ci, check := range checks {
+		seen := make(map[uuid.UUID]bool, len(check.Items))
+		for _, in := range check.Items {
+			id, err := uuid.Parse(in.ItemID)
+			if err != nil {
+				return nil, NewValidationError("checks.items.item_id", "UUID inválido")
+			}
+			line, ok := byID[id]
+			if !ok {
+				return nil, NewValidationError("checks.items.item_id", "la línea no pertenece a la cuenta")
+			}
+			if seen[id] {
+				return nil, NewValidationError("checks.items.item_id", "línea repetida en la subcuenta")
+			}
+			seen[id] = true
+
+			qty, parts := in.Quantity, in.Parts
+			if qty == 0 {
+				qty = line.Quantity
+			}
+			if parts == 0 {
+				parts = 1
+			}
+			if qty > line.Quantity {
+				return nil, NewValidationError("checks.items.quantity", "la cantidad supera la de la línea")
+			}
+			num, den := int64(qty), int64(line.Quantity*parts)
+			g := gcd(num, den)
+			shares[id] = append(shares[id], checkShare{check: ci, num: num / g, den: den / g})
+		}
+	}
+
+	for _, line := range lines {
+		var num, den int64 = 0, 1
+		for _, sh := range shares[line.ID] {
+			num = num*sh.den + sh.num*den
+			den *= sh.den
+			g := gcd(num, den)
+			num, den = num/g, den/g
+		}
+		if num != den {
+			return nil, NewValidationError("checks", "cada línea debe quedar repartida completa entre las subcuentas")
+		}
+	}
+	return shares, nil
+}
+
+// shareWeights expresa las fracciones de una línea con denominador común para
+// repartir sus importes con allocateDiscount
+func shareWeights(shares []checkShare) []money.Money {
+	var common int64 = 1
+	for _, sh := range shares {
+		common = common / gcd(common, sh.den) * sh.den
+	}
+	weights := make([]money.Money, len(shares))
+	for i, sh := range shares {
+		weights[i] = money.Money(sh.num * (common / sh.den))
+	}
+	return weights
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a == 0 {
+		return 1
+	}
+	return a
+}
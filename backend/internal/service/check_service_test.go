@@ -0,0 +1,156 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+)
+
+func testLines(quantities ...int) []*models.SaleItem {
+	lines := make([]*models.SaleItem, len(quantities))
+	for i, qty := range quantities {
+		lines[i] = &models.SaleItem{ID: uuid.New(), Quantity: qty}
+	}
+	return lines
+}
+
+func TestAssignedShares(t *testing.T) {
+	lines := testLines(3, 1, 2)
+	a, b, c := lines[0].ID.String(), lines[1].ID.String(), lines[2].ID.String()
+
+	shares, err := assignedShares(lines, []CheckInput{
+		{Items: []CheckItemInput{{ItemID: a, Quantity: 1}, {ItemID: b, Parts: 3}, {ItemID: c}}},
+		{Items: []CheckItemInput{{ItemID: a, Quantity: 2}, {ItemID: b, Parts: 3}}},
+		{Items: []CheckItemInput{{ItemID: b, Parts: 3}}},
+	})
+	if err != nil {
+		t.Fatalf("assignedShares: %v", err)
+	}
+
+	want := map[uuid.UUID][]checkShare{
+		lines[0].ID: {{check: 0, num: 1, den: 3}, {check: 1, num: 2, den: 3}},
+		lines[1].ID: {{check: 0, num: 1, den: 3}, {check: 1, num: 1, den: 3}, {check: 2, num: 1, den: 3}},
+		lines[2].ID: {{check: 0, num: 1, den: 1}},
+	}
+	for id, ws := range want {
+		got := shares[id]
+		if len(got) != len(ws) {
+			t.Errorf("línea %s: %v, want %v", id, got, ws)
+			continue
+		}
+		for i := range ws {
+			if got[i] != ws[i] {
+				t.Errorf("línea %s: share[%d] = %+v, want %+v", id, i, got[i], ws[i])
+			}
+		}
+	}
+}
+
+func TestAssignedSharesInvalid(t *testing.T) {
+	lines := testLines(2, 1)
+	a, b := lines[0].ID.String(), lines[1].ID.String()
+
+	tests := []struct {
+		name   string
+		checks []CheckInput
+	}{
+		{"falta una línea", []CheckInput{
+			{Items: []CheckItemInput{{ItemID: a}}},
+		}},
+		{"fracciones que no llegan a 1", []CheckInput{
+			{Items: []CheckItemInput{{ItemID: a}, {ItemID: b, Parts: 3}}},
+			{Items: []CheckItemInput{{ItemID: b, Parts: 3}}},
+		}},
+		{"fracciones que pasan de 1", []CheckInput{
+			{Items: []CheckItemInput{{ItemID: a}, {ItemID: b, Parts: 2}}},
+			{Items: []CheckItemInput{{ItemID: b, Parts: 2}}},
+			{Items: []CheckItemInput{{ItemID: b, Parts: 2}}},
+		}},
+		{"línea completa en dos subcuentas", []CheckInput{
+			{Items: []CheckItemInput{{ItemID: a}, {ItemID: b}}},
+			{Items: []CheckItemInput{{ItemID: b}}},
+		}},
+		{"línea repetida en la subcuenta", []CheckInput{
+			{Items: []CheckItemInput{{ItemID: a, Quantity: 1}, {ItemID: a, Quantity: 1}, {ItemID: b}}},
+		}},
+		{"cantidad mayor que la línea", []CheckInput{
+			{Items: []CheckItemInput{{ItemID: a, Quantity: 3}, {ItemID: b}}},
+		}},
+		{"línea ajena", []CheckInput{
+			{Items: []CheckItemInput{{ItemID: a}, {ItemID: b}, {ItemID: uuid.NewString()}}},
+		}},
+		{"UUID inválido", []CheckInput{
+			{Items: []CheckItemInput{{ItemID: a}, {ItemID: "no-es-uuid"}}},
+		}},
+	}
+	for _, tt := range tests {
+		if _, err := assignedShares(lines, tt.checks); err == nil {
+			t.Errorf("%s: se esperaba error", tt.name)
+		}
+	}
+}
+
+func TestEqualShares(t *testing.T) {
+	lines := testLines(1, 4, 2)
+	for _, parts := range []int{2, 3, 7} {
+		shares := equalShares(lines, parts)
+		for j, line := range lines {
+			got := shares[line.ID]
+			if len(got) != parts {
+				t.Fatalf("parts=%d línea %d: %d fracciones", parts, j, len(got))
+			}
+			seen := make(map[int]bool, parts)
+			for _, sh := range got {
+				if sh.num != 1 || sh.den != int64(parts) {
+					t.Errorf("parts=%d línea %d: fracción %d/%d", parts, j, sh.num, sh.den)
+				}
+				seen[sh.check] = true
+			}
+			if len(seen) != parts {
+				t.Errorf("parts=%d línea %d: subcuentas repetidas %+v", parts, j, got)
+			}
+			// El primer centavo sobrante de cada línea cae en otra subcuenta
+			if got[0].check != j%parts {
+				t.Errorf("parts=%d línea %d: empieza en la subcuenta %d", parts, j, got[0].check)
+			}
+		}
+	}
+}
+
+func TestShareWeights(t *testing.T) {
+	tests := []struct {
+		shares []checkShare
+		want   []money.Money
+	}{
+		{[]checkShare{{num: 1, den: 1}}, []money.Money{1}},
+		{[]checkShare{{num: 1, den: 2}, {num: 1, den: 2}}, []money.Money{1, 1}},
+		{[]checkShare{{num: 1, den: 3}, {num: 1, den: 2}, {num: 1, den: 6}}, []money.Money{2, 3, 1}},
+		{[]checkShare{{num: 2, den: 3}, {num: 1, den: 4}, {num: 1, den: 12}}, []money.Money{8, 3, 1}},
+	}
+	for _, tt := range tests {
+		got := shareWeights(tt.shares)
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("shareWeights(%+v) = %v, want %v", tt.shares, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// Las fracciones repartidas por importe siempre suman el total de la línea
+func TestShareWeightsAllocation(t *testing.T) {
+	shares := []checkShare{{num: 1, den: 3}, {num: 1, den: 2}, {num: 1, den: 6}}
+	for _, total := range []money.Money{1, 100, 101, 1999} {
+		var sum money.Money
+		for _, part := range allocateDiscount(total, shareWeights(shares)) {
+			sum += part
+		}
+		if sum != total {
+			t.Errorf("total %d repartido suma %d", total, sum)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package service
+
+import "time"
+
+// parseDateParam acepta RFC3339 o una fecha YYYY-MM-DD (medianoche en loc);
+// dateOnly indica el segundo caso
+func parseDateParam(v string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.Parse(time.RFC3339, v); err == nil {
+		return t, false, nil
+	}
+	t, err = time.ParseInLocation("2006-01-02", v, loc)
+	return t, true, err
+}
+
+// loadLocation resuelve la zona horaria del restaurante; si no es válida se usa UTC
+func loadLocation(name string) *time.Location {
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	return time.UTC
+}
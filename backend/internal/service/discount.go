@@ -0,0 +1,75 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+	"github.com/pos-saas/restaurant-pos/internal/permissions"
+)
+
+// DiscountInput: con type=percent, value es el porcentaje con dos decimales
+// (12.5 = 12.5%); con type=fixed, value es el importe a descontar.
+type DiscountInput struct {
+	Type   string      `json:"type" binding:"required,oneof=percent fixed"`
+	Value  money.Money `json:"value" binding:"gt=0"`
+	Reason string      `json:"reason"`
+}
+
+// amount calcula el descuento sobre base. Un importe fijo no puede superar la base.
+func (d *DiscountInput) amount(field string, base money.Money) (money.Money, error) {
+	if d.Type == models.DiscountPercent {
+		// 10.00 se guarda como 1000 centavos, que son justamente 1000 puntos básicos
+		bps := int64(d.Value)
+		if bps > 10000 {
+			return 0, NewValidationError(field, "el porcentaje no puede superar 100")
+		}
+		return base.Percent(bps), nil
+	}
+	if d.Value > base {
+		return 0, NewValidationError(field, "el descuento supera el importe")
+	}
+	return d.Value, nil
+}
+
+// checkDiscountLimit exige el permiso de descuentos y aplica el tope a quien
+// no tiene descuentos sin tope. El tope se mide sobre el descuento total del
+// ticket respecto al precio de lista.
+func checkDiscountLimit(perms permissions.Set, maxBps int64, discount, listTotal money.Money) error {
+	if discount == 0 {
+		return nil
+	}
+	if !perms.Has(permissions.DiscountsApply) && !perms.Has(permissions.DiscountsUnlimited) {
+		return NewAppError(errors.ErrForbidden, 403, "no tienes permiso para aplicar descuentos")
+	}
+	if perms.Has(permissions.DiscountsUnlimited) {
+		return nil
+	}
+	if listTotal <= 0 || discount > listTotal.Percent(maxBps) {
+		return NewValidationError("discount", fmt.Sprintf("el descuento supera el máximo permitido para tu rol (%s%%)", money.Money(maxBps)))
+	}
+	return nil
+}
+
+// allocateDiscount reparte amount entre las líneas en proporción a su importe.
+// Se redondea sobre el acumulado para que las partes sumen exactamente amount.
+func allocateDiscount(amount money.Money, weights []money.Money) []money.Money {
+	shares := make([]money.Money, len(weights))
+	var total money.Money
+	for _, w := range weights {
+		total += w
+	}
+	if amount == 0 || total <= 0 {
+		return shares
+	}
+
+	var cumulative, assigned money.Money
+	for i, w := range weights {
+		cumulative += w
+		upTo := amount.MulDiv(int64(cumulative), int64(total))
+		shares[i] = upTo - assigned
+		assigned = upTo
+	}
+	return shares
+}
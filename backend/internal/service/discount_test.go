@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/pos-saas/restaurant-pos/internal/money"
+)
+
+func TestAllocateDiscount(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  money.Money
+		weights []money.Money
+		want    []money.Money
+	}{
+		{"proporcional", 300, []money.Money{100, 200}, []money.Money{100, 200}},
+		{"centavos sobrantes", 100, []money.Money{1, 1, 1}, []money.Money{33, 34, 33}},
+		{"un centavo entre tres", 1, []money.Money{1, 1, 1}, []money.Money{0, 1, 0}},
+		{"pesos desiguales", 1000, []money.Money{333, 333, 334}, []money.Money{333, 333, 334}},
+		{"peso cero", 50, []money.Money{0, 700, 300}, []money.Money{0, 35, 15}},
+		{"una sola línea", 999, []money.Money{1234}, []money.Money{999}},
+		{"importe cero", 0, []money.Money{100, 200}, []money.Money{0, 0}},
+		{"total cero", 100, []money.Money{0, 0}, []money.Money{0, 0}},
+		{"sin líneas", 100, nil, []money.Money{}},
+	}
+	for _, tt := range tests {
+		got := allocateDiscount(tt.amount, tt.weights)
+		if len(got) != len(tt.want) {
+			t.Fatalf("%s: len = %d, want %d", tt.name, len(got), len(tt.want))
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: share[%d] = %d, want %d", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+// El reparto siempre suma exactamente el importe, por mucho que redondee
+func TestAllocateDiscountSum(t *testing.T) {
+	weights := [][]money.Money{
+		{1, 1, 1},
+		{7, 13, 29, 31},
+		{999, 1, 1},
+		{1050, 2375, 180, 1, 9999},
+	}
+	for _, ws := range weights {
+		for _, amount := range []money.Money{1, 2, 99, 100, 101, 12345} {
+			var sum money.Money
+			for i, share := range allocateDiscount(amount, ws) {
+				if share < 0 {
+					t.Errorf("allocateDiscount(%d, %v)[%d] = %d, negativo", amount, ws, i, share)
+				}
+				sum += share
+			}
+			if sum != amount {
+				t.Errorf("allocateDiscount(%d, %v) suma %d", amount, ws, sum)
+			}
+		}
+	}
+}
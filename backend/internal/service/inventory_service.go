@@ -0,0 +1,440 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/quantity"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+type InventoryService struct {
+	txManager     *repository.TxManager
+	inventoryRepo *repository.InventoryRepository
+	productRepo   *repository.ProductRepository
+	variantRepo   *repository.ProductVariantRepository
+	modifierRepo  *repository.ModifierRepository
+}
+
+func NewInventoryService(txManager *repository.TxManager, inventoryRepo *repository.InventoryRepository, productRepo *repository.ProductRepository, variantRepo *repository.ProductVariantRepository, modifierRepo *repository.ModifierRepository) *InventoryService {
+	return &InventoryService{
+		txManager:     txManager,
+		inventoryRepo: inventoryRepo,
+		productRepo:   productRepo,
+		variantRepo:   variantRepo,
+		modifierRepo:  modifierRepo,
+	}
+}
+
+// IngredientInput: el stock inicial solo se toma al crear; después el stock
+// cambia con ventas y ajustes.
+type IngredientInput struct {
+	Name              string       `json:"name" binding:"required"`
+	Unit              string       `json:"unit" binding:"required,oneof=unit g kg ml l"`
+	InitialStock      quantity.Qty `json:"initial_stock" binding:"gte=0"`
+	LowStockThreshold quantity.Qty `json:"low_stock_threshold" binding:"gte=0"`
+	Active            *bool        `json:"active"` // por defecto true
+}
+
+// StockAdjustmentInput: delta suma o resta al stock; count fija el stock
+// contado físicamente. Se envía uno de los dos.
+type StockAdjustmentInput struct {
+	Delta  *quantity.Qty `json:"delta"`
+	Count  *quantity.Qty `json:"count" binding:"omitempty,gte=0"`
+	Reason string        `json:"reason" binding:"required"`
+}
+
+type RecipeItemInput struct {
+	IngredientID string       `json:"ingredient_id" binding:"required"`
+	Quantity     quantity.Qty `json:"quantity" binding:"gt=0"`
+}
+
+// SetRecipeInput: variant_id vacío es la receta del producto; con variante, la
+// receta propia de esa variante (reemplaza a la del producto al vender).
+type SetRecipeInput struct {
+	VariantID string            `json:"variant_id"`
+	Items     []RecipeItemInput `json:"items" binding:"dive"`
+}
+
+type ListMovementsInput struct {
+	Limit int `form:"limit" binding:"omitempty,gt=0"`
+}
+
+const (
+	defaultMovementLimit = 50
+	maxMovementLimit     = 200
+)
+
+func (s *InventoryService) CreateIngredient(ctx context.Context, restaurantID, userID uuid.UUID, input IngredientInput) (*models.Ingredient, error) {
+	ingredient := &models.Ingredient{
+		ID:                uuid.New(),
+		RestaurantID:      restaurantID,
+		Name:              strings.TrimSpace(input.Name),
+		Unit:              input.Unit,
+		LowStockThreshold: input.LowStockThreshold,
+		Active:            input.Active == nil || *input.Active,
+	}
+
+	// El stock inicial entra como ajuste para que el historial cuadre con el stock
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.inventoryRepo.WithTx(tx)
+		if err := repo.CreateIngredient(ctx, ingredient); err != nil {
+			return err
+		}
+		if input.InitialStock == 0 {
+			return nil
+		}
+		ingredient.Stock = input.InitialStock
+		return repo.ApplyMovement(ctx, &models.StockMovement{
+			ID:           uuid.New(),
+			RestaurantID: restaurantID,
+			IngredientID: ingredient.ID,
+			Quantity:     input.InitialStock,
+			Type:         models.StockMovementAdjustment,
+			Reason:       "stock inicial",
+			UserID:       &userID,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe un insumo con ese nombre")
+		}
+		return nil, err
+	}
+	return ingredient, nil
+}
+
+func (s *InventoryService) ListIngredients(ctx context.Context, restaurantID uuid.UUID) ([]*models.Ingredient, error) {
+	return s.inventoryRepo.ListIngredients(ctx, restaurantID)
+}
+
+func (s *InventoryService) GetIngredient(ctx context.Context, restaurantID, ingredientID uuid.UUID) (*models.Ingredient, error) {
+	return s.inventoryRepo.GetIngredient(ctx, restaurantID, ingredientID)
+}
+
+// UpdateIngredient cambia nombre, unidad, umbral y estado; initial_stock se ignora
+// UpdateIngredient no permite cambiar la unidad de un insumo con stock,
+// recetas, movimientos o compras: cambiaría el significado de esas cantidades
+func (s *InventoryService) UpdateIngredient(ctx context.Context, restaurantID, ingredientID uuid.UUID, input IngredientInput) (*models.Ingredient, error) {
+	var ingredient *models.Ingredient
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.inventoryRepo.WithTx(tx)
+		var err error
+		// Bloqueado para que no entre un movimiento mientras se cambia la unidad
+		ingredient, err = repo.GetIngredientForUpdate(ctx, restaurantID, ingredientID)
+		if err != nil {
+			return err
+		}
+		if input.Unit != ingredient.Unit {
+			inUse, err := repo.IngredientInUse(ctx, ingredientID)
+			if err != nil {
+				return err
+			}
+			if inUse || ingredient.Stock != 0 {
+				return NewAppError(errors.ErrConflict, 409, "el insumo tiene stock, recetas o movimientos; no se puede cambiar su unidad")
+			}
+		}
+		ingredient.Name = strings.TrimSpace(input.Name)
+		ingredient.Unit = input.Unit
+		ingredient.LowStockThreshold = input.LowStockThreshold
+		if input.Active != nil {
+			ingredient.Active = *input.Active
+		}
+		if err := repo.UpdateIngredient(ctx, ingredient); err != nil {
+			if errors.Is(err, errors.ErrConflict) {
+				return NewAppError(errors.ErrConflict, 409, "ya existe un insumo con ese nombre")
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ingredient, nil
+}
+
+func (s *InventoryService) DeleteIngredient(ctx context.Context, restaurantID, ingredientID uuid.UUID) error {
+	err := s.inventoryRepo.DeleteIngredient(ctx, restaurantID, ingredientID)
+	if errors.Is(err, errors.ErrConflict) {
+		return NewAppError(errors.ErrConflict, 409, "el insumo tiene recetas o movimientos; desactívalo en su lugar")
+	}
+	return err
+}
+
+// Adjust registra un ajuste manual (merma, conteo físico, corrección)
+func (s *InventoryService) Adjust(ctx context.Context, restaurantID, ingredientID, userID uuid.UUID, input StockAdjustmentInput) (*models.Ingredient, error) {
+	if (input.Delta == nil) == (input.Count == nil) {
+		return nil, NewValidationError("delta", "envía delta o count")
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, NewValidationError("reason", "el motivo del ajuste es obligatorio")
+	}
+
+	var ingredient *models.Ingredient
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.inventoryRepo.WithTx(tx)
+		// Bloqueado para que un conteo no se calcule sobre un stock que una venta está cambiando
+		current, err := repo.GetIngredientForUpdate(ctx, restaurantID, ingredientID)
+		if err != nil {
+			return err
+		}
+
+		var delta quantity.Qty
+		if input.Delta != nil {
+			delta = *input.Delta
+		} else {
+			delta = *input.Count - current.Stock
+		}
+		if delta == 0 {
+			return NewValidationError("delta", "el ajuste no cambia el stock")
+		}
+
+		err = repo.ApplyMovement(ctx, &models.StockMovement{
+			ID:           uuid.New(),
+			RestaurantID: restaurantID,
+			IngredientID: ingredientID,
+			Quantity:     delta,
+			Type:         models.StockMovementAdjustment,
+			Reason:       reason,
+			UserID:       &userID,
+		})
+		if err != nil {
+			return err
+		}
+		ingredient, err = repo.GetIngredient(ctx, restaurantID, ingredientID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ingredient, nil
+}
+
+func (s *InventoryService) ListMovements(ctx context.Context, restaurantID, ingredientID uuid.UUID, input ListMovementsInput) ([]*models.StockMovement, error) {
+	if _, err := s.inventoryRepo.GetIngredient(ctx, restaurantID, ingredientID); err != nil {
+		return nil, err
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultMovementLimit
+	}
+	if limit > maxMovementLimit {
+		limit = maxMovementLimit
+	}
+	return s.inventoryRepo.ListMovements(ctx, restaurantID, ingredientID, limit)
+}
+
+// LowStock devuelve los insumos en o por debajo de su umbral de alerta
+func (s *InventoryService) LowStock(ctx context.Context, restaurantID uuid.UUID) ([]*models.Ingredient, error) {
+	return s.inventoryRepo.ListLowStock(ctx, restaurantID)
+}
+
+func (s *InventoryService) GetProductRecipe(ctx context.Context, restaurantID, productID uuid.UUID) ([]*models.RecipeItem, error) {
+	if _, err := s.productRepo.GetByID(ctx, restaurantID, productID); err != nil {
+		return nil, err
+	}
+	return s.inventoryRepo.ListProductRecipe(ctx, restaurantID, productID)
+}
+
+func (s *InventoryService) SetProductRecipe(ctx context.Context, restaurantID, productID uuid.UUID, input SetRecipeInput) ([]*models.RecipeItem, error) {
+	if _, err := s.productRepo.GetByID(ctx, restaurantID, productID); err != nil {
+		return nil, err
+	}
+
+	var variantID *uuid.UUID
+	if input.VariantID != "" {
+		id, err := uuid.Parse(input.VariantID)
+		if err != nil {
+			return nil, NewValidationError("variant_id", "UUID inválido")
+		}
+		variant, err := s.variantRepo.GetByID(ctx, restaurantID, id)
+		if err != nil || variant.ProductID != productID {
+			return nil, NewValidationError("variant_id", "variante no encontrada para el producto")
+		}
+		variantID = &id
+	}
+
+	items, err := s.buildRecipe(ctx, restaurantID, input.Items)
+	if err != nil {
+		return nil, err
+	}
+	for _, ri := range items {
+		ri.ProductID = &productID
+		ri.VariantID = variantID
+	}
+
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		return s.inventoryRepo.WithTx(tx).SetProductRecipe(ctx, restaurantID, productID, variantID, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.inventoryRepo.ListProductRecipe(ctx, restaurantID, productID)
+}
+
+func (s *InventoryService) GetOptionRecipe(ctx context.Context, restaurantID, groupID, optionID uuid.UUID) ([]*models.RecipeItem, error) {
+	if err := s.checkOption(ctx, restaurantID, groupID, optionID); err != nil {
+		return nil, err
+	}
+	return s.inventoryRepo.ListOptionRecipe(ctx, restaurantID, optionID)
+}
+
+func (s *InventoryService) SetOptionRecipe(ctx context.Context, restaurantID, groupID, optionID uuid.UUID, input SetRecipeInput) ([]*models.RecipeItem, error) {
+	if err := s.checkOption(ctx, restaurantID, groupID, optionID); err != nil {
+		return nil, err
+	}
+	if input.VariantID != "" {
+		return nil, NewValidationError("variant_id", "las opciones de modificador no tienen variantes")
+	}
+
+	items, err := s.buildRecipe(ctx, restaurantID, input.Items)
+	if err != nil {
+		return nil, err
+	}
+	for _, ri := range items {
+		ri.ModifierOptionID = &optionID
+	}
+
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		return s.inventoryRepo.WithTx(tx).SetOptionRecipe(ctx, restaurantID, optionID, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.inventoryRepo.ListOptionRecipe(ctx, restaurantID, optionID)
+}
+
+// checkOption verifica que la opción pertenezca al grupo y el grupo al restaurante
+func (s *InventoryService) checkOption(ctx context.Context, restaurantID, groupID, optionID uuid.UUID) error {
+	group, err := s.modifierRepo.GetGroup(ctx, restaurantID, groupID)
+	if err != nil {
+		return err
+	}
+	for _, o := range group.Options {
+		if o.ID == optionID {
+			return nil
+		}
+	}
+	return errors.ErrNotFound
+}
+
+func (s *InventoryService) buildRecipe(ctx context.Context, restaurantID uuid.UUID, inputs []RecipeItemInput) ([]*models.RecipeItem, error) {
+	items := make([]*models.RecipeItem, 0, len(inputs))
+	seen := make(map[uuid.UUID]bool, len(inputs))
+	for _, in := range inputs {
+		id, err := uuid.Parse(in.IngredientID)
+		if err != nil {
+			return nil, NewValidationError("items.ingredient_id", "UUID inválido")
+		}
+		if seen[id] {
+			return nil, NewValidationError("items.ingredient_id", "insumo repetido en la receta")
+		}
+		if _, err := s.inventoryRepo.GetIngredient(ctx, restaurantID, id); err != nil {
+			if errors.Is(err, errors.ErrNotFound) {
+				return nil, NewValidationError("items.ingredient_id", "insumo no encontrado")
+			}
+			return nil, err
+		}
+		seen[id] = true
+		items = append(items, &models.RecipeItem{
+			ID:           uuid.New(),
+			RestaurantID: restaurantID,
+			IngredientID: id,
+			Quantity:     in.Quantity,
+		})
+	}
+	return items, nil
+}
+
+// saleConsumption calcula cuánto consume de cada insumo una venta: la receta de
+// cada línea (la de su variante si tiene una propia), incluidos los componentes
+// de combo, más la receta de cada modificador elegido.
+func saleConsumption(ctx context.Context, inventoryRepo *repository.InventoryRepository, restaurantID uuid.UUID, items []*models.SaleItem) (map[uuid.UUID]quantity.Qty, error) {
+	var lines []*models.SaleItem
+	for _, item := range items {
+		lines = append(lines, item)
+		lines = append(lines, item.Components...)
+	}
+
+	var productIDs, optionIDs []uuid.UUID
+	for _, line := range lines {
+		productIDs = append(productIDs, line.ProductID)
+		for _, tp := range line.Toppings {
+			if tp.ModifierOptionID != nil {
+				optionIDs = append(optionIDs, *tp.ModifierOptionID)
+			}
+		}
+	}
+	recipes, err := inventoryRepo.ListRecipesFor(ctx, restaurantID, productIDs, optionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byProduct := make(map[uuid.UUID][]*models.RecipeItem)
+	byVariant := make(map[uuid.UUID][]*models.RecipeItem)
+	byOption := make(map[uuid.UUID][]*models.RecipeItem)
+	for _, ri := range recipes {
+		switch {
+		case ri.ModifierOptionID != nil:
+			byOption[*ri.ModifierOptionID] = append(byOption[*ri.ModifierOptionID], ri)
+		case ri.VariantID != nil:
+			byVariant[*ri.VariantID] = append(byVariant[*ri.VariantID], ri)
+		default:
+			byProduct[*ri.ProductID] = append(byProduct[*ri.ProductID], ri)
+		}
+	}
+
+	consumption := make(map[uuid.UUID]quantity.Qty)
+	for _, line := range lines {
+		recipe := byProduct[line.ProductID]
+		if line.VariantID != nil && len(byVariant[*line.VariantID]) > 0 {
+			recipe = byVariant[*line.VariantID]
+		}
+		for _, ri := range recipe {
+			consumption[ri.IngredientID] += ri.Quantity.Times(line.Quantity)
+		}
+		// La cantidad del topping ya es la de toda la línea
+		for _, tp := range line.Toppings {
+			if tp.ModifierOptionID == nil {
+				continue
+			}
+			for _, ri := range byOption[*tp.ModifierOptionID] {
+				consumption[ri.IngredientID] += ri.Quantity.Times(tp.Quantity)
+			}
+		}
+	}
+	return consumption, nil
+}
+
+// applyStockMovements registra un movimiento por insumo; de base se toman el
+// tipo, la venta o recepción de origen y el usuario. Los insumos se actualizan
+// en orden de ID para que dos operaciones simultáneas no se bloqueen
+// mutuamente. Usar con un repositorio dentro de la transacción.
+func applyStockMovements(ctx context.Context, inventoryRepo *repository.InventoryRepository, base models.StockMovement, deltas map[uuid.UUID]quantity.Qty) error {
+	ids := make([]uuid.UUID, 0, len(deltas))
+	for id, q := range deltas {
+		if q != 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+
+	for _, id := range ids {
+		m := base
+		m.ID = uuid.New()
+		m.IngredientID = id
+		m.Quantity = deltas[id]
+		if err := inventoryRepo.ApplyMovement(ctx, &m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,520 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/events"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+// KitchenService maneja las estaciones de cocina y las comandas que reciben
+type KitchenService struct {
+	txManager   *repository.TxManager
+	kitchenRepo *repository.KitchenRepository
+	publisher   events.Publisher
+}
+
+func NewKitchenService(txManager *repository.TxManager, kitchenRepo *repository.KitchenRepository, publisher events.Publisher) *KitchenService {
+	return &KitchenService{txManager: txManager, kitchenRepo: kitchenRepo, publisher: publisher}
+}
+
+type KitchenStationInput struct {
+	Name      string `json:"name" binding:"required"`
+	Active    *bool  `json:"active"` // por defecto true
+	SortOrder int    `json:"sort_order"`
+}
+
+// ListKitchenTicketsInput: sin filtros se devuelven las comandas no servidas.
+// Con updated_since la pantalla recibe solo lo que cambió, incluidas las servidas.
+type ListKitchenTicketsInput struct {
+	StationID    string `form:"station_id"`
+	Status       string `form:"status"` // lista separada por comas
+	UpdatedSince string `form:"updated_since"`
+	Limit        int    `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+type KitchenTicketStatusInput struct {
+	Status string `json:"status" binding:"required,oneof=new in_progress ready served"`
+}
+
+const defaultKitchenTicketLimit = 200
+
+// kitchenTransitions son los cambios de estado permitidos. Una comanda lista
+// puede volver a preparación; servida y anulada son definitivas.
+var kitchenTransitions = map[string][]string{
+	models.KitchenTicketNew:        {models.KitchenTicketInProgress, models.KitchenTicketReady},
+	models.KitchenTicketInProgress: {models.KitchenTicketReady},
+	models.KitchenTicketReady:      {models.KitchenTicketServed, models.KitchenTicketInProgress},
+}
+
+func (s *KitchenService) CreateStation(ctx context.Context, restaurantID uuid.UUID, input KitchenStationInput) (*models.KitchenStation, error) {
+	station := &models.KitchenStation{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		Name:         input.Name,
+		Active:       input.Active == nil || *input.Active,
+		SortOrder:    input.SortOrder,
+	}
+	if err := s.kitchenRepo.CreateStation(ctx, station); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe una estación con ese nombre")
+		}
+		return nil, err
+	}
+	return station, nil
+}
+
+func (s *KitchenService) ListStations(ctx context.Context, restaurantID uuid.UUID) ([]*models.KitchenStation, error) {
+	return s.kitchenRepo.ListStations(ctx, restaurantID, false)
+}
+
+// UpdateStation cambia la estación; una estación inactiva deja de recibir comandas
+func (s *KitchenService) UpdateStation(ctx context.Context, restaurantID, stationID uuid.UUID, input KitchenStationInput) (*models.KitchenStation, error) {
+	station, err := s.kitchenRepo.GetStation(ctx, restaurantID, stationID)
+	if err != nil {
+		return nil, err
+	}
+	station.Name = input.Name
+	if input.Active != nil {
+		station.Active = *input.Active
+	}
+	station.SortOrder = input.SortOrder
+	if err := s.kitchenRepo.UpdateStation(ctx, station); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe una estación con ese nombre")
+		}
+		return nil, err
+	}
+	return station, nil
+}
+
+func (s *KitchenService) DeleteStation(ctx context.Context, restaurantID, stationID uuid.UUID) error {
+	err := s.kitchenRepo.DeleteStation(ctx, restaurantID, stationID)
+	if errors.Is(err, errors.ErrConflict) {
+		return NewAppError(errors.ErrConflict, 409, "la estación ya recibió comandas; desactívala en lugar de eliminarla")
+	}
+	return err
+}
+
+// ListTickets devuelve las comandas con sus líneas, de la más antigua a la más nueva
+func (s *KitchenService) ListTickets(ctx context.Context, restaurantID uuid.UUID, input ListKitchenTicketsInput) ([]*models.KitchenTicket, error) {
+	filter := repository.KitchenTicketFilter{Limit: defaultKitchenTicketLimit}
+	if input.Limit > 0 {
+		filter.Limit = input.Limit
+	}
+	if input.StationID != "" {
+		id, err := uuid.Parse(input.StationID)
+		if err != nil {
+			return nil, NewValidationError("station_id", "UUID inválido")
+		}
+		filter.StationID = &id
+	}
+	if input.Status != "" {
+		for _, st := range strings.Split(input.Status, ",") {
+			switch st {
+			case models.KitchenTicketNew, models.KitchenTicketInProgress, models.KitchenTicketReady, models.KitchenTicketServed, models.KitchenTicketVoided:
+			default:
+				return nil, NewValidationError("status", "estado inválido: "+st)
+			}
+			filter.Statuses = append(filter.Statuses, st)
+		}
+	}
+	if input.UpdatedSince != "" {
+		since, err := time.Parse(time.RFC3339Nano, input.UpdatedSince)
+		if err != nil {
+			return nil, NewValidationError("updated_since", "fecha inválida, usa RFC 3339")
+		}
+		filter.UpdatedSince = &since
+	}
+
+	tickets, err := s.kitchenRepo.ListTickets(ctx, restaurantID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachItems(ctx, tickets); err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (s *KitchenService) GetTicket(ctx context.Context, restaurantID, ticketID uuid.UUID) (*models.KitchenTicket, error) {
+	ticket, err := s.kitchenRepo.GetTicket(ctx, restaurantID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachItems(ctx, []*models.KitchenTicket{ticket}); err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// UpdateStatus avanza la comanda y registra la hora de cada estado
+func (s *KitchenService) UpdateStatus(ctx context.Context, restaurantID, ticketID uuid.UUID, input KitchenTicketStatusInput) (*models.KitchenTicket, error) {
+	changed := false
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		kitchenRepo := s.kitchenRepo.WithTx(tx)
+		ticket, err := kitchenRepo.GetTicketForUpdate(ctx, restaurantID, ticketID)
+		if err != nil {
+			return err
+		}
+		if ticket.Status == input.Status {
+			return nil
+		}
+		allowed := false
+		for _, next := range kitchenTransitions[ticket.Status] {
+			if next == input.Status {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return NewAppError(errors.ErrConflict, 409, fmt.Sprintf("la comanda no puede pasar de %s a %s", ticket.Status, input.Status))
+		}
+
+		now := time.Now()
+		switch input.Status {
+		case models.KitchenTicketInProgress:
+			if ticket.StartedAt == nil {
+				ticket.StartedAt = &now
+			}
+			ticket.ReadyAt = nil
+		case models.KitchenTicketReady:
+			ticket.ReadyAt = &now
+		case models.KitchenTicketServed:
+			ticket.ServedAt = &now
+		}
+		ticket.Status = input.Status
+		changed = true
+		return kitchenRepo.UpdateTicketStatus(ctx, ticket)
+	})
+	if err != nil {
+		return nil, err
+	}
+	ticket, err := s.GetTicket(ctx, restaurantID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		s.publisher.Publish(events.New(events.KitchenTicketStatusChanged, restaurantID, ticket))
+	}
+	return ticket, nil
+}
+
+func (s *KitchenService) attachItems(ctx context.Context, tickets []*models.KitchenTicket) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(tickets))
+	for i, t := range tickets {
+		ids[i] = t.ID
+	}
+	byTicket, err := s.kitchenRepo.ListTicketItems(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, t := range tickets {
+		t.Items = byTicket[t.ID]
+		if t.Items == nil {
+			t.Items = []*models.KitchenTicketItem{}
+		}
+	}
+	return nil
+}
+
+// kitchenTickets arma una comanda por estación con las líneas que le tocan.
+// Los componentes de combo van a la estación de su propio producto (o a la
+// del combo si no tienen) y las líneas sin estación no generan comanda.
+func kitchenTickets(ctx context.Context, kitchenRepo *repository.KitchenRepository, sale *models.Sale, items []*models.SaleItem) ([]*models.KitchenTicket, error) {
+	var productIDs []uuid.UUID
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+		for _, c := range item.Components {
+			productIDs = append(productIDs, c.ProductID)
+		}
+	}
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+	routes, err := kitchenRepo.RoutesByProducts(ctx, sale.RestaurantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []*models.KitchenTicket
+	byStation := make(map[uuid.UUID]*models.KitchenTicket)
+	add := func(stationID *uuid.UUID, line *models.SaleItem, comboName string) {
+		if stationID == nil {
+			return
+		}
+		ticket, ok := byStation[*stationID]
+		if !ok {
+			ticket = &models.KitchenTicket{
+				ID:           uuid.New(),
+				RestaurantID: sale.RestaurantID,
+				StationID:    *stationID,
+				SaleID:       sale.ID,
+				Status:       models.KitchenTicketNew,
+			}
+			byStation[*stationID] = ticket
+			tickets = append(tickets, ticket)
+		}
+		it := kitchenLine(ticket.ID, line, routes[line.ProductID].Name, comboName)
+		it.Position = len(ticket.Items)
+		ticket.Items = append(ticket.Items, it)
+	}
+
+	for _, item := range items {
+		route := routes[item.ProductID]
+		if len(item.Components) == 0 {
+			add(route.StationID, item, "")
+			continue
+		}
+		// El combo solo va a cocina si tiene notas o toppings propios
+		if item.Notes != "" || len(item.Toppings) > 0 {
+			add(route.StationID, item, "")
+		}
+		for _, c := range item.Components {
+			stationID := routes[c.ProductID].StationID
+			if stationID == nil {
+				stationID = route.StationID
+			}
+			add(stationID, c, route.Name)
+		}
+	}
+	return tickets, nil
+}
+
+// kitchenLine copia la línea de venta a la comanda. Los toppings muestran su
+// cantidad por unidad cuando es más de uno.
+func kitchenLine(ticketID uuid.UUID, line *models.SaleItem, productName, comboName string) *models.KitchenTicketItem {
+	name := productName
+	if line.VariantName != "" {
+		name += " (" + line.VariantName + ")"
+	}
+	if line.ComboSlotName != "" {
+		name = line.ComboSlotName + ": " + name
+	}
+	modifiers := make([]string, 0, len(line.Toppings))
+	for _, tp := range line.Toppings {
+		label := tp.Name
+		switch {
+		case tp.Quantity%line.Quantity == 0 && tp.Quantity/line.Quantity > 1:
+			label += fmt.Sprintf(" x%d", tp.Quantity/line.Quantity)
+		case tp.Quantity%line.Quantity != 0:
+			label += fmt.Sprintf(" x%d en total", tp.Quantity)
+		}
+		modifiers = append(modifiers, label)
+	}
+	saleItemID := line.ID
+	return &models.KitchenTicketItem{
+		ID:         uuid.New(),
+		TicketID:   ticketID,
+		SaleItemID: &saleItemID,
+		Name:       name,
+		Quantity:   line.Quantity,
+		Notes:      line.Notes,
+		Modifiers:  modifiers,
+		ComboName:  comboName,
+	}
+}
+
+// insertKitchenTickets guarda las comandas. Usar con un repositorio dentro de
+// la transacción de la venta.
+func insertKitchenTickets(ctx context.Context, kitchenRepo *repository.KitchenRepository, tickets []*models.KitchenTicket) error {
+	for _, ticket := range tickets {
+		if err := kitchenRepo.CreateTicket(ctx, ticket); err != nil {
+			return err
+		}
+		for _, it := range ticket.Items {
+			if err := kitchenRepo.CreateTicketItem(ctx, it); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// publishKitchenTickets avisa a las pantallas de cocina de las comandas nuevas.
+// Llamar después de confirmar la transacción; cada comanda se relee para
+// enviarla con estación y mesa, como la devuelve la API.
+func publishKitchenTickets(ctx context.Context, kitchenRepo *repository.KitchenRepository, publisher events.Publisher, restaurantID uuid.UUID, tickets []*models.KitchenTicket) {
+	for _, t := range tickets {
+		ticket, err := kitchenRepo.GetTicket(ctx, restaurantID, t.ID)
+		if err != nil {
+			ticket = t
+		}
+		ticket.Items = t.Items
+		publisher.Publish(events.New(events.KitchenTicketCreated, restaurantID, ticket))
+	}
+}
+
+// removeKitchenLines quita de las comandas una línea de venta que se borra y
+// anula las comandas que se quedan vacías. Usar con un repositorio dentro de la
+// transacción, antes de borrar la línea.
+func removeKitchenLines(ctx context.Context, kitchenRepo *repository.KitchenRepository, saleID, saleItemID uuid.UUID) ([]uuid.UUID, error) {
+	ids, err := kitchenRepo.RemoveSaleItemLines(ctx, saleID, saleItemID)
+	if err != nil || len(ids) == 0 {
+		return ids, err
+	}
+	return ids, kitchenRepo.VoidEmptyTickets(ctx, ids)
+}
+
+// kitchenMove pasa qty unidades de la línea de venta from, ya enviadas a
+// cocina, a la línea to de otra cuenta. Si pasa la línea entera, from y to son
+// la misma línea.
+type kitchenMove struct {
+	from, to uuid.UUID
+	qty      int
+}
+
+// lineMoves arma los movimientos de una línea y sus componentes; part es la
+// parte separada con splitLine, o la misma línea si pasa entera
+func lineMoves(line, part *models.SaleItem) []kitchenMove {
+	moves := []kitchenMove{{from: line.ID, to: part.ID, qty: part.Quantity}}
+	for i, c := range part.Components {
+		moves = append(moves, lineMoves(line.Components[i], c)...)
+	}
+	return moves
+}
+
+// moveKitchenLines lleva a la cuenta toSaleID las líneas de comanda de las
+// líneas de venta que pasan a ella. Una comanda que pasa entera cambia de
+// cuenta; si no, sus líneas van a una copia de la comanda en la otra cuenta,
+// con la misma estación y estado. Usar con un repositorio dentro de la
+// transacción, con las líneas de venta ya guardadas. Devuelve las comandas que
+// cambiaron.
+func moveKitchenLines(ctx context.Context, kitchenRepo *repository.KitchenRepository, fromSaleID, toSaleID uuid.UUID, moves []kitchenMove) ([]uuid.UUID, error) {
+	if len(moves) == 0 {
+		return nil, nil
+	}
+	byItem := make(map[uuid.UUID]kitchenMove, len(moves))
+	for _, m := range moves {
+		if m.qty > 0 {
+			byItem[m.from] = m
+		}
+	}
+	ticketIDs, err := kitchenRepo.SaleTicketIDs(ctx, fromSaleID)
+	if err != nil || len(ticketIDs) == 0 {
+		return nil, err
+	}
+	items, err := kitchenRepo.ListTicketItems(ctx, ticketIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed, touched []uuid.UUID
+	for _, ticketID := range ticketIDs {
+		var moving []*models.KitchenTicketItem
+		whole := true
+		for _, it := range items[ticketID] {
+			var m kitchenMove
+			ok := it.SaleItemID != nil
+			if ok {
+				m, ok = byItem[*it.SaleItemID]
+			}
+			if ok {
+				moving = append(moving, it)
+			}
+			if !ok || m.to != m.from || m.qty < it.Quantity {
+				whole = false
+			}
+		}
+		if len(moving) == 0 {
+			continue
+		}
+		if whole {
+			if err := kitchenRepo.MoveTicket(ctx, ticketID, toSaleID); err != nil {
+				return nil, err
+			}
+			changed = append(changed, ticketID)
+			continue
+		}
+
+		copyID := uuid.New()
+		if err := kitchenRepo.CopyTicket(ctx, ticketID, copyID, toSaleID); err != nil {
+			return nil, err
+		}
+		for _, it := range moving {
+			m := byItem[*it.SaleItemID]
+			if m.qty >= it.Quantity {
+				if err := kitchenRepo.MoveTicketItem(ctx, it.ID, copyID, m.to); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err := kitchenRepo.UpdateTicketItemQuantity(ctx, it.ID, it.Quantity-m.qty); err != nil {
+				return nil, err
+			}
+			part := *it
+			part.ID = uuid.New()
+			part.TicketID = copyID
+			part.SaleItemID = &m.to
+			part.Quantity = m.qty
+			if err := kitchenRepo.CreateTicketItem(ctx, &part); err != nil {
+				return nil, err
+			}
+		}
+		touched = append(touched, ticketID)
+		changed = append(changed, ticketID, copyID)
+	}
+	if len(touched) > 0 {
+		if err := kitchenRepo.TouchTickets(ctx, touched); err != nil {
+			return nil, err
+		}
+	}
+	return changed, nil
+}
+
+// publishKitchenChanges avisa a las pantallas de cocina de las comandas que
+// cambiaron de líneas o de cuenta, o se anularon. Llamar después de confirmar
+// la transacción.
+func publishKitchenChanges(ctx context.Context, kitchenRepo *repository.KitchenRepository, publisher events.Publisher, restaurantID uuid.UUID, ticketIDs []uuid.UUID) {
+	if len(ticketIDs) == 0 {
+		return
+	}
+	items, err := kitchenRepo.ListTicketItems(ctx, ticketIDs)
+	if err != nil {
+		return
+	}
+	for _, id := range ticketIDs {
+		ticket, err := kitchenRepo.GetTicket(ctx, restaurantID, id)
+		if err != nil {
+			continue
+		}
+		ticket.Items = items[id]
+		if ticket.Items == nil {
+			ticket.Items = []*models.KitchenTicketItem{}
+		}
+		eventType := events.KitchenTicketUpdated
+		if ticket.Status == models.KitchenTicketVoided {
+			eventType = events.KitchenTicketStatusChanged
+		}
+		publisher.Publish(events.New(eventType, restaurantID, ticket))
+	}
+}
+
+// resolveStationID valida un station_id opcional del restaurante. Una cadena
+// vacía significa quitar la estación.
+func resolveStationID(ctx context.Context, kitchenRepo *repository.KitchenRepository, restaurantID uuid.UUID, raw string) (*uuid.UUID, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, NewValidationError("station_id", "UUID inválido")
+	}
+	if _, err := kitchenRepo.GetStation(ctx, restaurantID, id); err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil, NewValidationError("station_id", "estación no encontrada")
+		}
+		return nil, err
+	}
+	return &id, nil
+}
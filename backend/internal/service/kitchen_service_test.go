@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-saas/restaurant-pos/internal/models"
+)
+
+func TestLineMoves(t *testing.T) {
+	combo := &models.SaleItem{ID: uuid.New(), Quantity: 4, Subtotal: 4000}
+	combo.Components = []*models.SaleItem{
+		{ID: uuid.New(), Quantity: 4, ParentItemID: &combo.ID},
+		{ID: uuid.New(), Quantity: 8, ParentItemID: &combo.ID},
+	}
+	from := []uuid.UUID{combo.ID, combo.Components[0].ID, combo.Components[1].ID}
+
+	// Línea entera: cada línea pasa sobre sí misma con todas sus unidades
+	moves := lineMoves(combo, combo)
+	want := []kitchenMove{
+		{from: from[0], to: from[0], qty: 4},
+		{from: from[1], to: from[1], qty: 4},
+		{from: from[2], to: from[2], qty: 8},
+	}
+	if len(moves) != len(want) {
+		t.Fatalf("línea entera: %+v, want %+v", moves, want)
+	}
+	for i := range want {
+		if moves[i] != want[i] {
+			t.Errorf("línea entera: move[%d] = %+v, want %+v", i, moves[i], want[i])
+		}
+	}
+
+	// Parte de la línea: las unidades separadas pasan a las líneas nuevas
+	part := splitLine(combo, 1, uuid.New())
+	moves = lineMoves(combo, part)
+	want = []kitchenMove{
+		{from: from[0], to: part.ID, qty: 1},
+		{from: from[1], to: part.Components[0].ID, qty: 1},
+		{from: from[2], to: part.Components[1].ID, qty: 2},
+	}
+	if len(moves) != len(want) {
+		t.Fatalf("parte: %+v, want %+v", moves, want)
+	}
+	for i := range want {
+		if moves[i] != want[i] {
+			t.Errorf("parte: move[%d] = %+v, want %+v", i, moves[i], want[i])
+		}
+	}
+}
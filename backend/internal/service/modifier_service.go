@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+type ModifierService struct {
+	txManager    *repository.TxManager
+	modifierRepo *repository.ModifierRepository
+	productRepo  *repository.ProductRepository
+}
+
+func NewModifierService(txManager *repository.TxManager, modifierRepo *repository.ModifierRepository, productRepo *repository.ProductRepository) *ModifierService {
+	return &ModifierService{
+		txManager:    txManager,
+		modifierRepo: modifierRepo,
+		productRepo:  productRepo,
+	}
+}
+
+// ModifierGroupInput: max_select = 0 significa sin límite. Un grupo requerido
+// exige al menos una opción aunque min_select sea 0.
+type ModifierGroupInput struct {
+	Name      string `json:"name" binding:"required"`
+	MinSelect int    `json:"min_select" binding:"gte=0"`
+	MaxSelect int    `json:"max_select" binding:"gte=0"`
+	Required  bool   `json:"required"`
+	SortOrder int    `json:"sort_order"`
+}
+
+func (in ModifierGroupInput) validate() error {
+	if in.MaxSelect > 0 && in.MaxSelect < in.MinSelect {
+		return NewValidationError("max_select", "debe ser mayor o igual a min_select")
+	}
+	return nil
+}
+
+type ModifierOptionInput struct {
+	Name      string      `json:"name" binding:"required"`
+	Price     money.Money `json:"price" binding:"gte=0"`
+	Active    *bool       `json:"active"` // por defecto true
+	SortOrder int         `json:"sort_order"`
+}
+
+type SetProductModifiersInput struct {
+	GroupIDs []string `json:"group_ids"`
+}
+
+func (s *ModifierService) CreateGroup(ctx context.Context, restaurantID uuid.UUID, input ModifierGroupInput) (*models.ModifierGroup, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	group := &models.ModifierGroup{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		Name:         input.Name,
+		MinSelect:    input.MinSelect,
+		MaxSelect:    input.MaxSelect,
+		Required:     input.Required,
+		SortOrder:    input.SortOrder,
+		Options:      []*models.ModifierOption{},
+	}
+	if err := s.modifierRepo.CreateGroup(ctx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (s *ModifierService) ListGroups(ctx context.Context, restaurantID uuid.UUID) ([]*models.ModifierGroup, error) {
+	return s.modifierRepo.ListGroups(ctx, restaurantID)
+}
+
+func (s *ModifierService) GetGroup(ctx context.Context, restaurantID, groupID uuid.UUID) (*models.ModifierGroup, error) {
+	return s.modifierRepo.GetGroup(ctx, restaurantID, groupID)
+}
+
+func (s *ModifierService) UpdateGroup(ctx context.Context, restaurantID, groupID uuid.UUID, input ModifierGroupInput) (*models.ModifierGroup, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	group, err := s.modifierRepo.GetGroup(ctx, restaurantID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	group.Name = input.Name
+	group.MinSelect = input.MinSelect
+	group.MaxSelect = input.MaxSelect
+	group.Required = input.Required
+	group.SortOrder = input.SortOrder
+	if err := s.modifierRepo.UpdateGroup(ctx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (s *ModifierService) DeleteGroup(ctx context.Context, restaurantID, groupID uuid.UUID) error {
+	return s.modifierRepo.DeleteGroup(ctx, restaurantID, groupID)
+}
+
+func (s *ModifierService) CreateOption(ctx context.Context, restaurantID, groupID uuid.UUID, input ModifierOptionInput) (*models.ModifierOption, error) {
+	// El grupo debe pertenecer al restaurante
+	if _, err := s.modifierRepo.GetGroup(ctx, restaurantID, groupID); err != nil {
+		return nil, err
+	}
+	option := &models.ModifierOption{
+		ID:        uuid.New(),
+		GroupID:   groupID,
+		Name:      input.Name,
+		Price:     input.Price,
+		Active:    input.Active == nil || *input.Active,
+		SortOrder: input.SortOrder,
+	}
+	if err := s.modifierRepo.CreateOption(ctx, option); err != nil {
+		return nil, err
+	}
+	return option, nil
+}
+
+func (s *ModifierService) UpdateOption(ctx context.Context, restaurantID, groupID, optionID uuid.UUID, input ModifierOptionInput) (*models.ModifierOption, error) {
+	if _, err := s.modifierRepo.GetGroup(ctx, restaurantID, groupID); err != nil {
+		return nil, err
+	}
+	option := &models.ModifierOption{
+		ID:        optionID,
+		GroupID:   groupID,
+		Name:      input.Name,
+		Price:     input.Price,
+		Active:    input.Active == nil || *input.Active,
+		SortOrder: input.SortOrder,
+	}
+	if err := s.modifierRepo.UpdateOption(ctx, option); err != nil {
+		return nil, err
+	}
+	return option, nil
+}
+
+func (s *ModifierService) DeleteOption(ctx context.Context, restaurantID, groupID, optionID uuid.UUID) error {
+	if _, err := s.modifierRepo.GetGroup(ctx, restaurantID, groupID); err != nil {
+		return err
+	}
+	return s.modifierRepo.DeleteOption(ctx, groupID, optionID)
+}
+
+func (s *ModifierService) ListProductGroups(ctx context.Context, restaurantID, productID uuid.UUID) ([]*models.ModifierGroup, error) {
+	if _, err := s.productRepo.GetByID(ctx, restaurantID, productID); err != nil {
+		return nil, err
+	}
+	return s.modifierRepo.ListByProduct(ctx, restaurantID, productID)
+}
+
+// SetProductGroups reemplaza los grupos del producto; el orden recibido es el
+// orden en que se muestran.
+func (s *ModifierService) SetProductGroups(ctx context.Context, restaurantID, productID uuid.UUID, input SetProductModifiersInput) ([]*models.ModifierGroup, error) {
+	if _, err := s.productRepo.GetByID(ctx, restaurantID, productID); err != nil {
+		return nil, err
+	}
+
+	groupIDs := make([]uuid.UUID, 0, len(input.GroupIDs))
+	seen := make(map[uuid.UUID]bool, len(input.GroupIDs))
+	for _, raw := range input.GroupIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, NewValidationError("group_ids", "UUID inválido")
+		}
+		if seen[id] {
+			continue
+		}
+		if _, err := s.modifierRepo.GetGroup(ctx, restaurantID, id); err != nil {
+			if errors.Is(err, errors.ErrNotFound) {
+				return nil, NewValidationError("group_ids", "grupo de modificadores no encontrado")
+			}
+			return nil, err
+		}
+		seen[id] = true
+		groupIDs = append(groupIDs, id)
+	}
+
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		return s.modifierRepo.WithTx(tx).SetProductGroups(ctx, productID, groupIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.modifierRepo.ListByProduct(ctx, restaurantID, productID)
+}
+
+// ModifierSelectionInput: quantity es por unidad del producto (0 cuenta como 1)
+type ModifierSelectionInput struct {
+	OptionID string `json:"option_id" binding:"required"`
+	Quantity int    `json:"quantity" binding:"gte=0"`
+}
+
+// resolveModifiers valida la selección contra los grupos del producto y arma
+// las copias para sale_item_toppings con los precios del catálogo. La cantidad
+// guardada es la de toda la línea (por unidad × cantidad del producto).
+func resolveModifiers(groups []*models.ModifierGroup, selections []ModifierSelectionInput, itemQty int, saleItemID uuid.UUID) ([]*models.Topping, error) {
+	type choice struct {
+		group  *models.ModifierGroup
+		option *models.ModifierOption
+	}
+	available := make(map[uuid.UUID]choice)
+	for _, g := range groups {
+		for _, o := range g.Options {
+			available[o.ID] = choice{group: g, option: o}
+		}
+	}
+
+	var order []uuid.UUID
+	perOption := make(map[uuid.UUID]int)
+	perGroup := make(map[uuid.UUID]int)
+	for _, sel := range selections {
+		id, err := uuid.Parse(sel.OptionID)
+		if err != nil {
+			return nil, NewValidationError("modifiers", "UUID inválido")
+		}
+		c, ok := available[id]
+		if !ok {
+			return nil, NewValidationError("modifiers", "opción no disponible para el producto")
+		}
+		if !c.option.Active {
+			return nil, NewValidationError("modifiers", fmt.Sprintf("la opción %s no está disponible", c.option.Name))
+		}
+		qty := sel.Quantity
+		if qty == 0 {
+			qty = 1
+		}
+		if _, ok := perOption[id]; !ok {
+			order = append(order, id)
+		}
+		perOption[id] += qty
+		perGroup[c.group.ID] += qty
+	}
+
+	for _, g := range groups {
+		minSelect := g.MinSelect
+		if g.Required && minSelect < 1 {
+			minSelect = 1
+		}
+		count := perGroup[g.ID]
+		if count < minSelect {
+			return nil, NewValidationError("modifiers", fmt.Sprintf("elige al menos %d en %s", minSelect, g.Name))
+		}
+		if g.MaxSelect > 0 && count > g.MaxSelect {
+			return nil, NewValidationError("modifiers", fmt.Sprintf("máximo %d en %s", g.MaxSelect, g.Name))
+		}
+	}
+
+	toppings := make([]*models.Topping, 0, len(order))
+	for _, id := range order {
+		c := available[id]
+		optionID := id
+		toppings = append(toppings, &models.Topping{
+			ID:               uuid.New(),
+			SaleItemID:       saleItemID,
+			ModifierOptionID: &optionID,
+			Name:             c.option.Name,
+			Price:            c.option.Price,
+			Quantity:         perOption[id] * itemQty,
+		})
+	}
+	return toppings, nil
+}
@@ -0,0 +1,788 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/events"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+	"github.com/pos-saas/restaurant-pos/internal/permissions"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+// OrderService maneja las cuentas abiertas del servicio en mesa: ventas en
+// estado pending a las que se agregan y quitan líneas hasta cobrarlas.
+type OrderService struct {
+	txManager     *repository.TxManager
+	saleRepo      *repository.SaleRepository
+	checkRepo     *repository.SaleCheckRepository
+	tableRepo     *repository.TableRepository
+	inventoryRepo *repository.InventoryRepository
+	categoryRepo  *repository.CategoryRepository
+	taxRateRepo   *repository.TaxRateRepository
+	authRepo      *repository.AuthRepository
+	kitchenRepo   *repository.KitchenRepository
+	publisher     events.Publisher
+	// sales arma las líneas con las mismas reglas que una venta de mostrador
+	sales *SaleService
+}
+
+func NewOrderService(txManager *repository.TxManager, saleRepo *repository.SaleRepository, checkRepo *repository.SaleCheckRepository, tableRepo *repository.TableRepository, inventoryRepo *repository.InventoryRepository, categoryRepo *repository.CategoryRepository, taxRateRepo *repository.TaxRateRepository, authRepo *repository.AuthRepository, kitchenRepo *repository.KitchenRepository, publisher events.Publisher, sales *SaleService) *OrderService {
+	return &OrderService{
+		txManager:     txManager,
+		saleRepo:      saleRepo,
+		checkRepo:     checkRepo,
+		tableRepo:     tableRepo,
+		inventoryRepo: inventoryRepo,
+		categoryRepo:  categoryRepo,
+		taxRateRepo:   taxRateRepo,
+		authRepo:      authRepo,
+		kitchenRepo:   kitchenRepo,
+		publisher:     publisher,
+		sales:         sales,
+	}
+}
+
+type OpenOrderInput struct {
+	TableID string          `json:"table_id"` // vacío: cuenta sin mesa (barra, para llevar)
+	Items   []SaleItemInput `json:"items" binding:"dive"`
+}
+
+type ListOrdersInput struct {
+	TableID string `form:"table_id"`
+}
+
+type AddOrderItemsInput struct {
+	Items []SaleItemInput `json:"items" binding:"required,min=1,dive"`
+}
+
+type TransferOrderInput struct {
+	TableID string `json:"table_id" binding:"required"`
+}
+
+// SplitOrderItemInput: sin quantity se mueve la línea completa
+type SplitOrderItemInput struct {
+	ItemID   string `json:"item_id" binding:"required"`
+	Quantity int    `json:"quantity" binding:"omitempty,gt=0"`
+}
+
+// SplitOrderInput: las líneas elegidas pasan a una cuenta nueva, en la misma
+// mesa salvo que se indique otra
+type SplitOrderInput struct {
+	Items   []SplitOrderItemInput `json:"items" binding:"required,min=1,dive"`
+	TableID string                `json:"table_id"`
+}
+
+type MergeOrderInput struct {
+	OrderID string `json:"order_id" binding:"required"` // cuenta que se une a esta
+}
+
+type CloseOrderInput struct {
+	Payments []SalePaymentInput `json:"payments" binding:"required,min=1,dive"`
+	Discount *DiscountInput     `json:"discount"` // descuento sobre todo el ticket
+	Tip      *TipInput          `json:"tip"`
+}
+
+// OrderDetail es una cuenta con sus líneas y su desglose de impuestos
+type OrderDetail struct {
+	Sale  *models.Sale       `json:"sale"`
+	Items []*models.SaleItem `json:"items"`
+	Taxes []*models.SaleTax  `json:"taxes"`
+}
+
+// Open abre una cuenta, opcionalmente en una mesa y con las primeras líneas
+func (s *OrderService) Open(ctx context.Context, restaurantID, userID uuid.UUID, input OpenOrderInput) (*OrderDetail, error) {
+	tableID, err := s.resolveTable(ctx, restaurantID, input.TableID)
+	if err != nil {
+		return nil, err
+	}
+	taxes, err := s.taxResolver(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sale := &models.Sale{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		UserID:       userID,
+		Status:       models.SaleStatusPending,
+		TableID:      tableID,
+		OpenedAt:     &now,
+	}
+	added, err := s.buildLines(ctx, restaurantID, sale.ID, taxes, input.Items)
+	if err != nil {
+		return nil, err
+	}
+	tickets, err := kitchenTickets(ctx, s.kitchenRepo, sale, added)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+		if err := saleRepo.Create(ctx, sale); err != nil {
+			return err
+		}
+		if err := insertLines(ctx, saleRepo, added); err != nil {
+			return err
+		}
+		if err := insertKitchenTickets(ctx, s.kitchenRepo.WithTx(tx), tickets); err != nil {
+			return err
+		}
+		return s.save(ctx, saleRepo, taxes, sale, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	publishKitchenTickets(ctx, s.kitchenRepo, s.publisher, restaurantID, tickets)
+	return s.Get(ctx, restaurantID, sale.ID)
+}
+
+// List devuelve las cuentas abiertas, todas o las de una mesa
+func (s *OrderService) List(ctx context.Context, restaurantID uuid.UUID, input ListOrdersInput) ([]*models.Sale, error) {
+	var tableID *uuid.UUID
+	if input.TableID != "" {
+		id, err := uuid.Parse(input.TableID)
+		if err != nil {
+			return nil, NewValidationError("table_id", "UUID inválido")
+		}
+		tableID = &id
+	}
+	return s.saleRepo.ListOpen(ctx, restaurantID, tableID)
+}
+
+func (s *OrderService) Get(ctx context.Context, restaurantID, orderID uuid.UUID) (*OrderDetail, error) {
+	sale, err := s.saleRepo.GetByID(ctx, restaurantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := loadLines(ctx, s.saleRepo, sale.ID)
+	if err != nil {
+		return nil, err
+	}
+	taxes, err := s.saleRepo.GetTaxes(ctx, sale.ID)
+	if err != nil {
+		return nil, err
+	}
+	if taxes == nil {
+		taxes = []*models.SaleTax{}
+	}
+	return &OrderDetail{Sale: sale, Items: items, Taxes: taxes}, nil
+}
+
+// AddItems agrega líneas a la cuenta y recalcula sus totales
+func (s *OrderService) AddItems(ctx context.Context, restaurantID, orderID uuid.UUID, input AddOrderItemsInput) (*OrderDetail, error) {
+	taxes, err := s.taxResolver(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	added, err := s.buildLines(ctx, restaurantID, orderID, taxes, input.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []*models.KitchenTicket
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+		sale, err := lockOpenOrder(ctx, saleRepo, restaurantID, orderID)
+		if err != nil {
+			return err
+		}
+		if err := resetChecks(ctx, s.checkRepo.WithTx(tx), sale.ID); err != nil {
+			return err
+		}
+		if err := insertLines(ctx, saleRepo, added); err != nil {
+			return err
+		}
+		// Solo las líneas nuevas van a cocina
+		tickets, err = kitchenTickets(ctx, s.kitchenRepo, sale, added)
+		if err != nil {
+			return err
+		}
+		if err := insertKitchenTickets(ctx, s.kitchenRepo.WithTx(tx), tickets); err != nil {
+			return err
+		}
+		return s.save(ctx, saleRepo, taxes, sale, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	publishKitchenTickets(ctx, s.kitchenRepo, s.publisher, restaurantID, tickets)
+	return s.Get(ctx, restaurantID, orderID)
+}
+
+// RemoveItem quita una línea (con sus componentes) de la cuenta y de las
+// comandas ya enviadas a cocina
+func (s *OrderService) RemoveItem(ctx context.Context, restaurantID, orderID, itemID uuid.UUID) (*OrderDetail, error) {
+	taxes, err := s.taxResolver(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []uuid.UUID
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+		sale, err := lockOpenOrder(ctx, saleRepo, restaurantID, orderID)
+		if err != nil {
+			return err
+		}
+		if err := resetChecks(ctx, s.checkRepo.WithTx(tx), sale.ID); err != nil {
+			return err
+		}
+		tickets, err = removeKitchenLines(ctx, s.kitchenRepo.WithTx(tx), sale.ID, itemID)
+		if err != nil {
+			return err
+		}
+		if err := saleRepo.DeleteItem(ctx, sale.ID, itemID); err != nil {
+			return err
+		}
+		return s.save(ctx, saleRepo, taxes, sale, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	publishKitchenChanges(ctx, s.kitchenRepo, s.publisher, restaurantID, tickets)
+	return s.Get(ctx, restaurantID, orderID)
+}
+
+// Transfer pasa la cuenta a otra mesa
+func (s *OrderService) Transfer(ctx context.Context, restaurantID, orderID uuid.UUID, input TransferOrderInput) (*OrderDetail, error) {
+	tableID, err := s.resolveTable(ctx, restaurantID, input.TableID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+		if _, err := lockOpenOrder(ctx, saleRepo, restaurantID, orderID); err != nil {
+			return err
+		}
+		return saleRepo.SetTable(ctx, restaurantID, orderID, tableID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, restaurantID, orderID)
+}
+
+// Split pasa líneas completas o parte de sus unidades a una cuenta nueva y la
+// devuelve; lo ya enviado a cocina pasa con ellas. La cuenta original debe
+// conservar al menos una línea.
+func (s *OrderService) Split(ctx context.Context, restaurantID, orderID uuid.UUID, input SplitOrderInput) (*OrderDetail, error) {
+	taxes, err := s.taxResolver(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	var tableID *uuid.UUID
+	if input.TableID != "" {
+		if tableID, err = s.resolveTable(ctx, restaurantID, input.TableID); err != nil {
+			return nil, err
+		}
+	}
+
+	newID := uuid.New()
+	var tickets []uuid.UUID
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+		source, err := lockOpenOrder(ctx, saleRepo, restaurantID, orderID)
+		if err != nil {
+			return err
+		}
+		if err := resetChecks(ctx, s.checkRepo.WithTx(tx), source.ID); err != nil {
+			return err
+		}
+		lines, err := loadLines(ctx, saleRepo, source.ID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		target := &models.Sale{
+			ID:           newID,
+			RestaurantID: restaurantID,
+			UserID:       source.UserID,
+			Status:       models.SaleStatusPending,
+			TableID:      source.TableID,
+			OpenedAt:     &now,
+		}
+		if tableID != nil {
+			target.TableID = tableID
+		}
+		if err := saleRepo.Create(ctx, target); err != nil {
+			return err
+		}
+
+		byID := make(map[uuid.UUID]*models.SaleItem, len(lines))
+		for _, line := range lines {
+			byID[line.ID] = line
+		}
+		var moveIDs []uuid.UUID
+		var parts, remainders []*models.SaleItem
+		var kitchenMoves []kitchenMove
+		seen := make(map[uuid.UUID]bool, len(input.Items))
+		for _, in := range input.Items {
+			id, err := uuid.Parse(in.ItemID)
+			if err != nil {
+				return NewValidationError("items.item_id", "UUID inválido")
+			}
+			line, ok := byID[id]
+			if !ok {
+				return NewValidationError("items.item_id", "la línea no pertenece a la cuenta")
+			}
+			if seen[id] {
+				return NewValidationError("items.item_id", "línea repetida")
+			}
+			seen[id] = true
+
+			qty := in.Quantity
+			if qty == 0 {
+				qty = line.Quantity
+			}
+			switch {
+			case qty > line.Quantity:
+				return NewValidationError("items.quantity", "la cantidad supera la de la línea")
+			case qty == line.Quantity:
+				moveIDs = append(moveIDs, line.ID)
+				kitchenMoves = append(kitchenMoves, lineMoves(line, line)...)
+			default:
+				part := splitLine(line, qty, target.ID)
+				parts = append(parts, part)
+				remainders = append(remainders, line)
+				kitchenMoves = append(kitchenMoves, lineMoves(line, part)...)
+			}
+		}
+		if len(moveIDs) == len(lines) {
+			return NewValidationError("items", "la cuenta original quedaría vacía; usa transferir")
+		}
+
+		if len(moveIDs) > 0 {
+			if err := saleRepo.MoveItems(ctx, source.ID, target.ID, moveIDs); err != nil {
+				return err
+			}
+		}
+		for _, line := range remainders {
+			if err := updateLine(ctx, saleRepo, line); err != nil {
+				return err
+			}
+		}
+		if err := insertLines(ctx, saleRepo, parts); err != nil {
+			return err
+		}
+		tickets, err = moveKitchenLines(ctx, s.kitchenRepo.WithTx(tx), source.ID, target.ID, kitchenMoves)
+		if err != nil {
+			return err
+		}
+
+		if err := s.save(ctx, saleRepo, taxes, source, nil); err != nil {
+			return err
+		}
+		return s.save(ctx, saleRepo, taxes, target, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	publishKitchenChanges(ctx, s.kitchenRepo, s.publisher, restaurantID, tickets)
+	return s.Get(ctx, restaurantID, newID)
+}
+
+// Merge une otra cuenta abierta a esta: sus líneas pasan aquí y la otra se borra
+func (s *OrderService) Merge(ctx context.Context, restaurantID, orderID uuid.UUID, input MergeOrderInput) (*OrderDetail, error) {
+	sourceID, err := uuid.Parse(input.OrderID)
+	if err != nil {
+		return nil, NewValidationError("order_id", "UUID inválido")
+	}
+	if sourceID == orderID {
+		return nil, NewValidationError("order_id", "no se puede unir una cuenta consigo misma")
+	}
+	taxes, err := s.taxResolver(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+		// Se bloquean en orden de ID para que dos uniones cruzadas no se bloqueen mutuamente
+		first, second := orderID, sourceID
+		if bytes.Compare(first[:], second[:]) > 0 {
+			first, second = second, first
+		}
+		locked := make(map[uuid.UUID]*models.Sale, 2)
+		for _, id := range []uuid.UUID{first, second} {
+			sale, err := lockOpenOrder(ctx, saleRepo, restaurantID, id)
+			if err != nil {
+				return err
+			}
+			if err := resetChecks(ctx, s.checkRepo.WithTx(tx), id); err != nil {
+				return err
+			}
+			locked[id] = sale
+		}
+
+		lines, err := loadLines(ctx, saleRepo, sourceID)
+		if err != nil {
+			return err
+		}
+		if len(lines) > 0 {
+			ids := make([]uuid.UUID, len(lines))
+			for i, line := range lines {
+				ids[i] = line.ID
+			}
+			if err := saleRepo.MoveItems(ctx, sourceID, orderID, ids); err != nil {
+				return err
+			}
+		}
+		// Las comandas siguen a sus líneas para que cocina no las pierda
+		if err := s.kitchenRepo.WithTx(tx).MoveTickets(ctx, sourceID, orderID); err != nil {
+			return err
+		}
+		if err := saleRepo.DeletePending(ctx, restaurantID, sourceID); err != nil {
+			return err
+		}
+		return s.save(ctx, saleRepo, taxes, locked[orderID], nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, restaurantID, orderID)
+}
+
+// Void anula una cuenta abierta que no se va a cobrar, p. ej. si el cliente se
+// fue. No se puede si ya se cobró alguna subcuenta: ese dinero está en un turno.
+// Como la cuenta no descontó inventario, no hay stock que devolver; sus
+// comandas pendientes se anulan.
+func (s *OrderService) Void(ctx context.Context, restaurantID, orderID, userID uuid.UUID, input CancelSaleInput) (*models.Sale, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, NewValidationError("reason", "el motivo de anulación es obligatorio")
+	}
+
+	var tickets []uuid.UUID
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+		sale, err := lockOpenOrder(ctx, saleRepo, restaurantID, orderID)
+		if err != nil {
+			return err
+		}
+		if err := resetChecks(ctx, s.checkRepo.WithTx(tx), sale.ID); err != nil {
+			return err
+		}
+		if err := saleRepo.Cancel(ctx, restaurantID, sale.ID, userID, reason); err != nil {
+			return err
+		}
+		tickets, err = s.kitchenRepo.WithTx(tx).VoidSaleTickets(ctx, sale.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sale, err := s.saleRepo.GetByID(ctx, restaurantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	s.publisher.Publish(events.New(events.SaleCancelled, restaurantID, sale))
+	publishKitchenChanges(ctx, s.kitchenRepo, s.publisher, restaurantID, tickets)
+	return sale, nil
+}
+
+// Close cobra la cuenta: aplica el descuento del ticket, registra los pagos en
+// el turno de quien cobra, descuenta el inventario y la venta queda completada.
+// El tope de descuento se valida aquí, sobre el ticket completo.
+func (s *OrderService) Close(ctx context.Context, restaurantID, orderID, userID uuid.UUID, perms permissions.Set, input CloseOrderInput) (*models.Sale, error) {
+	restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	taxes, err := newTaxResolver(ctx, restaurant, s.taxRateRepo, s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+		sale, err := lockOpenOrder(ctx, saleRepo, restaurantID, orderID)
+		if err != nil {
+			return err
+		}
+		if err := resetChecks(ctx, s.checkRepo.WithTx(tx), sale.ID); err != nil {
+			return err
+		}
+		items, err := loadLines(ctx, saleRepo, sale.ID)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return NewValidationError("items", "la cuenta no tiene productos")
+		}
+
+		listTotal, err := reprice(ctx, saleRepo, taxes, sale, items, input.Discount)
+		if err != nil {
+			return err
+		}
+		if err := checkDiscountLimit(perms, restaurant.MaxDiscountBps, sale.DiscountTotal, listTotal); err != nil {
+			return err
+		}
+		payments, err := buildPayments(sale, input.Payments, input.Tip)
+		if err != nil {
+			return err
+		}
+		session, err := s.sales.openSession(ctx, tx, restaurantID, userID)
+		if err != nil {
+			return err
+		}
+		if err := saleRepo.UpdateTotals(ctx, sale); err != nil {
+			return err
+		}
+		for _, payment := range payments {
+			payment.CashSessionID = &session.ID
+			if err := saleRepo.CreatePayment(ctx, payment); err != nil {
+				return err
+			}
+		}
+		return s.complete(ctx, tx, sale, items, session.ID, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sale, err := s.saleRepo.GetByID(ctx, restaurantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	s.publisher.Publish(events.New(events.SaleCreated, restaurantID, sale))
+	return sale, nil
+}
+
+// complete deja la venta completada en el turno de quien cobra y descuenta el
+// inventario de sus líneas. Los pagos ya deben estar registrados.
+func (s *OrderService) complete(ctx context.Context, tx pgx.Tx, sale *models.Sale, items []*models.SaleItem, sessionID, userID uuid.UUID) error {
+	if err := s.saleRepo.WithTx(tx).Complete(ctx, sale.RestaurantID, sale.ID, sessionID); err != nil {
+		return err
+	}
+
+	inventoryRepo := s.inventoryRepo.WithTx(tx)
+	consumption, err := saleConsumption(ctx, inventoryRepo, sale.RestaurantID, items)
+	if err != nil {
+		return err
+	}
+	for id, q := range consumption {
+		consumption[id] = -q
+	}
+	return applyStockMovements(ctx, inventoryRepo, models.StockMovement{
+		RestaurantID: sale.RestaurantID,
+		Type:         models.StockMovementSale,
+		SaleID:       &sale.ID,
+		UserID:       &userID,
+	}, consumption)
+}
+
+// resetChecks descarta la división de la cuenta antes de modificarla o cobrarla
+// entera; no se puede si ya se cobró alguna subcuenta
+func resetChecks(ctx context.Context, checkRepo *repository.SaleCheckRepository, saleID uuid.UUID) error {
+	paid, _, err := checkRepo.CountPaid(ctx, saleID)
+	if err != nil {
+		return err
+	}
+	if paid > 0 {
+		return NewAppError(errors.ErrConflict, 409, "la cuenta tiene subcuentas cobradas; cobra las restantes")
+	}
+	return checkRepo.DeleteBySale(ctx, saleID)
+}
+
+func (s *OrderService) taxResolver(ctx context.Context, restaurantID uuid.UUID) (*taxResolver, error) {
+	restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	return newTaxResolver(ctx, restaurant, s.taxRateRepo, s.categoryRepo)
+}
+
+// resolveTable valida que la mesa exista y esté activa; vacío es sin mesa
+func (s *OrderService) resolveTable(ctx context.Context, restaurantID uuid.UUID, raw string) (*uuid.UUID, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, NewValidationError("table_id", "UUID inválido")
+	}
+	table, err := s.tableRepo.GetTable(ctx, restaurantID, id)
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil, NewValidationError("table_id", "mesa no encontrada")
+		}
+		return nil, err
+	}
+	if !table.Active {
+		return nil, NewValidationError("table_id", "la mesa está inactiva")
+	}
+	return &id, nil
+}
+
+// buildLines arma las líneas nuevas con la tasa de impuesto vigente, que la
+// línea conserva mientras la cuenta siga abierta
+func (s *OrderService) buildLines(ctx context.Context, restaurantID, saleID uuid.UUID, taxes *taxResolver, inputs []SaleItemInput) ([]*models.SaleItem, error) {
+	items := make([]*models.SaleItem, len(inputs))
+	for i, it := range inputs {
+		item, product, err := s.sales.buildLine(ctx, restaurantID, saleID, it)
+		if err != nil {
+			return nil, err
+		}
+		if rate := taxes.rateFor(product); rate != nil {
+			item.TaxRateID = &rate.ID
+			item.TaxRateBps = rate.RateBps
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// save recalcula la cuenta con sus líneas actuales y guarda líneas, impuestos y totales
+func (s *OrderService) save(ctx context.Context, saleRepo *repository.SaleRepository, taxes *taxResolver, sale *models.Sale, discount *DiscountInput) error {
+	items, err := loadLines(ctx, saleRepo, sale.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := reprice(ctx, saleRepo, taxes, sale, items, discount); err != nil {
+		return err
+	}
+	return saleRepo.UpdateTotals(ctx, sale)
+}
+
+// reprice recalcula descuento del ticket, impuestos y totales de la cuenta y
+// guarda líneas e impuestos; los totales de la cabecera los guarda quien llama.
+// Devuelve el importe de lista para el tope de descuento.
+func reprice(ctx context.Context, saleRepo *repository.SaleRepository, taxes *taxResolver, sale *models.Sale, items []*models.SaleItem, discount *DiscountInput) (money.Money, error) {
+	rates := make([]*models.TaxRate, len(items))
+	for i, item := range items {
+		rates[i] = lineRate(taxes, item)
+	}
+	breakdown, listTotal, err := priceSale(sale, items, rates, taxes.inclusive, discount)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, item := range items {
+		if err := saleRepo.UpdateItem(ctx, item); err != nil {
+			return 0, err
+		}
+	}
+	if err := saleRepo.DeleteTaxes(ctx, sale.ID); err != nil {
+		return 0, err
+	}
+	for _, tax := range breakdown.list() {
+		tax.ID = uuid.New()
+		tax.SaleID = sale.ID
+		if err := saleRepo.CreateTax(ctx, tax); err != nil {
+			return 0, err
+		}
+	}
+	return listTotal, nil
+}
+
+// lineRate es la tasa con la que se agregó la línea a la cuenta
+func lineRate(taxes *taxResolver, item *models.SaleItem) *models.TaxRate {
+	if item.TaxRateID == nil {
+		return nil
+	}
+	if rate, ok := taxes.rates[*item.TaxRateID]; ok {
+		return rate
+	}
+	return &models.TaxRate{ID: *item.TaxRateID, RateBps: item.TaxRateBps}
+}
+
+// lockOpenOrder bloquea la cuenta y verifica que siga abierta
+func lockOpenOrder(ctx context.Context, saleRepo *repository.SaleRepository, restaurantID, orderID uuid.UUID) (*models.Sale, error) {
+	sale, err := saleRepo.GetByIDForUpdate(ctx, restaurantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if sale.Status != models.SaleStatusPending {
+		return nil, NewAppError(errors.ErrConflict, 409, "la cuenta ya fue cobrada o anulada")
+	}
+	return sale, nil
+}
+
+// loadLines devuelve las líneas principales de la venta con sus toppings y
+// componentes
+func loadLines(ctx context.Context, saleRepo *repository.SaleRepository, saleID uuid.UUID) ([]*models.SaleItem, error) {
+	items, err := saleRepo.GetItems(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if item.Toppings, err = saleRepo.GetItemToppings(ctx, item.ID); err != nil {
+			return nil, err
+		}
+	}
+	lines := nestComponents(items)
+	if lines == nil {
+		lines = []*models.SaleItem{}
+	}
+	return lines, nil
+}
+
+// splitLine separa qty unidades de la línea en una línea nueva de la venta
+// saleID y deja el resto en la original. Subtotal, toppings y componentes son
+// proporcionales a la cantidad, así que se reparten sin redondeo; un descuento
+// fijo se prorratea y uno porcentual se vuelve a calcular en cada parte.
+func splitLine(item *models.SaleItem, qty int, saleID uuid.UUID) *models.SaleItem {
+	part := *item
+	part.ID = uuid.New()
+	part.SaleID = saleID
+	part.Quantity = qty
+	part.Subtotal = item.Subtotal.MulDiv(int64(qty), int64(item.Quantity))
+	part.Toppings = nil
+	part.Components = nil
+
+	for _, tp := range item.Toppings {
+		moved := *tp
+		moved.ID = uuid.New()
+		moved.SaleItemID = part.ID
+		moved.Quantity = tp.Quantity * qty / item.Quantity
+		tp.Quantity -= moved.Quantity
+		part.Toppings = append(part.Toppings, &moved)
+	}
+	for _, component := range item.Components {
+		moved := splitLine(component, component.Quantity*qty/item.Quantity, saleID)
+		moved.ParentItemID = &part.ID
+		part.Components = append(part.Components, moved)
+	}
+
+	item.Quantity -= qty
+	item.Subtotal -= part.Subtotal
+	switch item.DiscountType {
+	case models.DiscountPercent:
+		part.DiscountAmount = part.Subtotal.Percent(int64(item.DiscountValue))
+		item.DiscountAmount = item.Subtotal.Percent(int64(item.DiscountValue))
+	case models.DiscountFixed:
+		part.DiscountAmount = item.DiscountAmount.MulDiv(int64(qty), int64(item.Quantity+qty))
+		item.DiscountAmount -= part.DiscountAmount
+		part.DiscountValue = part.DiscountAmount
+		item.DiscountValue = item.DiscountAmount
+	}
+	return &part
+}
+
+// updateLine guarda una línea que cambió de cantidad junto con sus toppings y componentes
+func updateLine(ctx context.Context, saleRepo *repository.SaleRepository, item *models.SaleItem) error {
+	if err := saleRepo.UpdateItem(ctx, item); err != nil {
+		return err
+	}
+	for _, tp := range item.Toppings {
+		if err := saleRepo.UpdateToppingQuantity(ctx, tp.ID, tp.Quantity); err != nil {
+			return err
+		}
+	}
+	for _, component := range item.Components {
+		if err := updateLine(ctx, saleRepo, component); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,23 +4,33 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jung-kurt/gofpdf"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
 	"github.com/pos-saas/restaurant-pos/internal/repository"
 )
 
 type PDFService struct {
-	saleRepo    *repository.SaleRepository
-	productRepo *repository.ProductRepository
-	authRepo    *repository.AuthRepository
+	saleRepo        *repository.SaleRepository
+	checkRepo       *repository.SaleCheckRepository
+	refundRepo      *repository.RefundRepository
+	productRepo     *repository.ProductRepository
+	authRepo        *repository.AuthRepository
+	cashSessionRepo *repository.CashSessionRepository
 }
 
-func NewPDFService(saleRepo *repository.SaleRepository, productRepo *repository.ProductRepository, authRepo *repository.AuthRepository) *PDFService {
+func NewPDFService(saleRepo *repository.SaleRepository, checkRepo *repository.SaleCheckRepository, refundRepo *repository.RefundRepository, productRepo *repository.ProductRepository, authRepo *repository.AuthRepository, cashSessionRepo *repository.CashSessionRepository) *PDFService {
 	return &PDFService{
-		saleRepo:    saleRepo,
-		productRepo: productRepo,
-		authRepo:    authRepo,
+		saleRepo:        saleRepo,
+		checkRepo:       checkRepo,
+		refundRepo:      refundRepo,
+		productRepo:     productRepo,
+		authRepo:        authRepo,
+		cashSessionRepo: cashSessionRepo,
 	}
 }
 
@@ -39,12 +49,18 @@ func (s *PDFService) GenerateInvoice(ctx context.Context, restaurantID, saleID u
 		toppings, _ := s.saleRepo.GetItemToppings(ctx, item.ID)
 		item.Toppings = toppings
 	}
+	items = nestComponents(items)
 
 	payments, err := s.saleRepo.GetPayments(ctx, saleID)
 	if err != nil {
 		return nil, err
 	}
 
+	taxes, err := s.saleRepo.GetTaxes(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+
 	restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
 	if err != nil {
 		return nil, err
@@ -53,8 +69,8 @@ func (s *PDFService) GenerateInvoice(ctx context.Context, restaurantID, saleID u
 	itemDetails := make([]struct {
 		Name     string
 		Qty      int
-		Price    float64
-		Subtotal float64
+		Price    money.Money
+		Subtotal money.Money
 		Toppings []string
 	}, len(items))
 
@@ -64,15 +80,27 @@ func (s *PDFService) GenerateInvoice(ctx context.Context, restaurantID, saleID u
 		if product != nil {
 			name = product.Name
 		}
+		if item.VariantName != "" {
+			name += " (" + item.VariantName + ")"
+		}
 		toppingStrs := make([]string, 0)
 		for _, t := range item.Toppings {
-			toppingStrs = append(toppingStrs, fmt.Sprintf("  + %s x%d $%.2f", t.Name, t.Quantity, t.Price*float64(t.Quantity)))
+			toppingStrs = append(toppingStrs, fmt.Sprintf("  + %s x%d $%s", t.Name, t.Quantity, t.Price.Times(t.Quantity)))
+		}
+		for _, c := range item.Components {
+			toppingStrs = append(toppingStrs, s.componentLine(ctx, restaurantID, c))
+			for _, t := range c.Toppings {
+				toppingStrs = append(toppingStrs, fmt.Sprintf("      + %s x%d $%s", t.Name, t.Quantity, t.Price.Times(t.Quantity)))
+			}
+		}
+		if item.DiscountAmount > 0 {
+			toppingStrs = append(toppingStrs, fmt.Sprintf("  %s -$%s", discountLabel(item.DiscountType, item.DiscountValue, item.DiscountReason), item.DiscountAmount))
 		}
 		itemDetails[i] = struct {
 			Name     string
 			Qty      int
-			Price    float64
-			Subtotal float64
+			Price    money.Money
+			Subtotal money.Money
 			Toppings []string
 		}{Name: name, Qty: item.Quantity, Price: item.UnitPrice, Subtotal: item.Subtotal, Toppings: toppingStrs}
 	}
@@ -81,26 +109,7 @@ func (s *PDFService) GenerateInvoice(ctx context.Context, restaurantID, saleID u
 	pdf.AddPage()
 	pdf.SetFont("Helvetica", "", 12)
 
-	// Encabezado
-	pdf.SetX(20)
-	pdf.SetY(20)
-	pdf.SetFont("Helvetica", "B", 16)
-	pdf.CellFormat(0, 8, restaurant.Name, "", 0, "L", false, 0, "")
-	pdf.Ln(10)
-
-	pdf.SetFont("Helvetica", "", 10)
-	if restaurant.Address != "" {
-		pdf.CellFormat(0, 6, restaurant.Address, "", 0, "L", false, 0, "")
-		pdf.Ln(5)
-	}
-	if restaurant.TaxID != "" {
-		pdf.CellFormat(0, 6, "RFC/NIT: "+restaurant.TaxID, "", 0, "L", false, 0, "")
-		pdf.Ln(5)
-	}
-	if restaurant.Phone != "" {
-		pdf.CellFormat(0, 6, "Tel: "+restaurant.Phone, "", 0, "L", false, 0, "")
-		pdf.Ln(10)
-	}
+	writeRestaurantHeader(pdf, restaurant)
 
 	pdf.SetFont("Helvetica", "B", 14)
 	pdf.CellFormat(0, 8, "FACTURA / TICKET DE VENTA", "", 0, "L", false, 0, "")
@@ -112,6 +121,10 @@ func (s *PDFService) GenerateInvoice(ctx context.Context, restaurantID, saleID u
 	pdf.CellFormat(0, 6, fmt.Sprintf("Fecha: %s", sale.CreatedAt.Format("02/01/2006 15:04")), "", 0, "L", false, 0, "")
 	pdf.Ln(12)
 
+	if sale.Status == models.SaleStatusCancelled {
+		stampVoid(pdf, sale)
+	}
+
 	// Tabla
 	pdf.SetFont("Helvetica", "B", 10)
 	pdf.CellFormat(80, 7, "Producto", "B", 0, "L", false, 0, "")
@@ -124,8 +137,8 @@ func (s *PDFService) GenerateInvoice(ctx context.Context, restaurantID, saleID u
 	for _, it := range itemDetails {
 		pdf.CellFormat(80, 6, it.Name, "", 0, "L", false, 0, "")
 		pdf.CellFormat(20, 6, fmt.Sprintf("%d", it.Qty), "", 0, "R", false, 0, "")
-		pdf.CellFormat(35, 6, fmt.Sprintf("$%.2f", it.Price), "", 0, "R", false, 0, "")
-		pdf.CellFormat(50, 6, fmt.Sprintf("$%.2f", it.Subtotal), "", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 6, fmt.Sprintf("$%s", it.Price), "", 0, "R", false, 0, "")
+		pdf.CellFormat(50, 6, fmt.Sprintf("$%s", it.Subtotal), "", 0, "R", false, 0, "")
 		pdf.Ln(5)
 		for _, tp := range it.Toppings {
 			pdf.CellFormat(80, 5, tp, "", 0, "L", false, 0, "")
@@ -134,26 +147,55 @@ func (s *PDFService) GenerateInvoice(ctx context.Context, restaurantID, saleID u
 	}
 
 	pdf.Ln(8)
+	pdf.SetFont("Helvetica", "", 10)
+	if sale.DiscountAmount > 0 {
+		pdf.CellFormat(135, 6, discountLabel(sale.DiscountType, sale.DiscountValue, sale.DiscountReason)+":", "", 0, "R", false, 0, "")
+		pdf.CellFormat(50, 6, fmt.Sprintf("-$%s", sale.DiscountAmount), "", 0, "R", false, 0, "")
+		pdf.Ln(6)
+	}
+	if sale.DiscountTotal > 0 {
+		pdf.CellFormat(135, 6, "Ahorro total:", "", 0, "R", false, 0, "")
+		pdf.CellFormat(50, 6, fmt.Sprintf("$%s", sale.DiscountTotal), "", 0, "R", false, 0, "")
+		pdf.Ln(6)
+	}
+	pdf.CellFormat(135, 6, "Subtotal:", "", 0, "R", false, 0, "")
+	pdf.CellFormat(50, 6, fmt.Sprintf("$%s", sale.Subtotal), "", 0, "R", false, 0, "")
+	pdf.Ln(6)
+	for _, t := range taxes {
+		pdf.CellFormat(135, 6, fmt.Sprintf("%s (base $%s):", taxLabel(t), t.Base), "", 0, "R", false, 0, "")
+		pdf.CellFormat(50, 6, fmt.Sprintf("$%s", t.Amount), "", 0, "R", false, 0, "")
+		pdf.Ln(6)
+	}
 	pdf.SetFont("Helvetica", "B", 12)
 	pdf.CellFormat(135, 8, "TOTAL:", "", 0, "R", false, 0, "")
-	pdf.CellFormat(50, 8, fmt.Sprintf("$%.2f", sale.Total), "", 0, "R", false, 0, "")
-	pdf.Ln(12)
+	pdf.CellFormat(50, 8, fmt.Sprintf("$%s", sale.Total), "", 0, "R", false, 0, "")
+	pdf.Ln(8)
+	if sale.TipTotal > 0 {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(135, 6, "Propina:", "", 0, "R", false, 0, "")
+		pdf.CellFormat(50, 6, fmt.Sprintf("$%s", sale.TipTotal), "", 0, "R", false, 0, "")
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(135, 6, "Total con propina:", "", 0, "R", false, 0, "")
+		pdf.CellFormat(50, 6, fmt.Sprintf("$%s", sale.Total+sale.TipTotal), "", 0, "R", false, 0, "")
+		pdf.Ln(6)
+	}
+	if restaurant.PricesIncludeTax && sale.TaxTotal > 0 {
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.CellFormat(0, 5, "Precios con impuestos incluidos", "", 0, "R", false, 0, "")
+		pdf.Ln(5)
+	}
+	pdf.Ln(4)
 
 	pdf.SetFont("Helvetica", "B", 10)
 	pdf.CellFormat(0, 6, "Metodos de pago:", "", 0, "L", false, 0, "")
 	pdf.Ln(6)
 	pdf.SetFont("Helvetica", "", 10)
 	for _, p := range payments {
-		method := p.Method
-		switch p.Method {
-		case "cash":
-			method = "Efectivo"
-		case "card":
-			method = "Tarjeta"
-		case "transfer":
-			method = "Transferencia"
-		}
-		line := fmt.Sprintf("  - %s: $%.2f", method, p.Amount)
+		line := fmt.Sprintf("  - %s: $%s", paymentMethodLabel(p.Method), p.Amount)
+		if p.Tip > 0 {
+			line += fmt.Sprintf(" + propina $%s", p.Tip)
+		}
 		if p.Reference != "" {
 			line += " (Ref: " + p.Reference + ")"
 		}
@@ -168,3 +210,529 @@ func (s *PDFService) GenerateInvoice(ctx context.Context, restaurantID, saleID u
 	}
 	return buf.Bytes(), nil
 }
+
+// GenerateCheckTicket genera el ticket de una subcuenta de una venta dividida:
+// la parte de cada línea que le toca, sus impuestos y sus pagos
+func (s *PDFService) GenerateCheckTicket(ctx context.Context, restaurantID, saleID, checkID uuid.UUID) ([]byte, error) {
+	sale, err := s.saleRepo.GetByID(ctx, restaurantID, saleID)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := s.checkRepo.ListBySale(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	var check *models.SaleCheck
+	for _, c := range checks {
+		if c.ID == checkID {
+			check = c
+		}
+	}
+	if check == nil {
+		return nil, errors.ErrNotFound
+	}
+
+	parts, err := s.checkRepo.ListItemsBySale(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	saleItems, err := s.saleRepo.GetItems(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	saleItemByID := make(map[uuid.UUID]*models.SaleItem, len(saleItems))
+	for _, it := range saleItems {
+		saleItemByID[it.ID] = it
+	}
+	saleTaxes, err := s.saleRepo.GetTaxes(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.saleRepo.GetPayments(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+
+	writeRestaurantHeader(pdf, restaurant)
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, fmt.Sprintf("TICKET - CUENTA %d DE %d", check.Number, len(checks)), "", 0, "L", false, 0, "")
+	pdf.Ln(10)
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Venta original #%s", saleID.String()[:8]), "", 0, "L", false, 0, "")
+	pdf.Ln(4)
+	date := sale.CreatedAt
+	if check.PaidAt != nil {
+		date = *check.PaidAt
+	}
+	pdf.CellFormat(0, 6, fmt.Sprintf("Fecha: %s", date.Format("02/01/2006 15:04")), "", 0, "L", false, 0, "")
+	pdf.Ln(12)
+
+	if sale.Status == models.SaleStatusCancelled {
+		stampVoid(pdf, sale)
+	}
+
+	// Tabla
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(115, 7, "Producto", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(20, 7, "Cant", "B", 0, "R", false, 0, "")
+	pdf.CellFormat(50, 7, "Importe", "B", 0, "R", false, 0, "")
+	pdf.Ln(8)
+
+	// Impuesto de la subcuenta por tasa, con la etiqueta del desglose de la venta
+	taxByRate := make(map[uuid.UUID]money.Money)
+	var exemptTax money.Money
+	pdf.SetFont("Helvetica", "", 10)
+	for _, part := range parts {
+		if part.CheckID != check.ID {
+			continue
+		}
+		name, qty := "Producto", shareLabel(part.ShareNum, part.ShareDen, 1)
+		if saleItem, ok := saleItemByID[part.SaleItemID]; ok {
+			if product, _ := s.productRepo.GetByID(ctx, restaurantID, saleItem.ProductID); product != nil {
+				name = product.Name
+			}
+			if saleItem.VariantName != "" {
+				name += " (" + saleItem.VariantName + ")"
+			}
+			qty = shareLabel(part.ShareNum, part.ShareDen, saleItem.Quantity)
+			if saleItem.TaxRateID != nil {
+				taxByRate[*saleItem.TaxRateID] += part.TaxAmount
+			} else {
+				exemptTax += part.TaxAmount
+			}
+		}
+		pdf.CellFormat(115, 6, name, "", 0, "L", false, 0, "")
+		pdf.CellFormat(20, 6, qty, "", 0, "R", false, 0, "")
+		pdf.CellFormat(50, 6, fmt.Sprintf("$%s", part.Total), "", 0, "R", false, 0, "")
+		pdf.Ln(5)
+	}
+
+	pdf.Ln(8)
+	for _, t := range saleTaxes {
+		amount := exemptTax
+		if t.TaxRateID != nil {
+			amount = taxByRate[*t.TaxRateID]
+		}
+		if amount == 0 {
+			continue
+		}
+		pdf.CellFormat(135, 6, taxLabel(t)+":", "", 0, "R", false, 0, "")
+		pdf.CellFormat(50, 6, fmt.Sprintf("$%s", amount), "", 0, "R", false, 0, "")
+		pdf.Ln(6)
+	}
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(135, 8, "TOTAL:", "", 0, "R", false, 0, "")
+	pdf.CellFormat(50, 8, fmt.Sprintf("$%s", check.Total), "", 0, "R", false, 0, "")
+	pdf.Ln(8)
+	if check.TipTotal > 0 {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(135, 6, "Propina:", "", 0, "R", false, 0, "")
+		pdf.CellFormat(50, 6, fmt.Sprintf("$%s", check.TipTotal), "", 0, "R", false, 0, "")
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(135, 6, "Total con propina:", "", 0, "R", false, 0, "")
+		pdf.CellFormat(50, 6, fmt.Sprintf("$%s", check.Total+check.TipTotal), "", 0, "R", false, 0, "")
+		pdf.Ln(6)
+	}
+	if restaurant.PricesIncludeTax && check.TaxTotal > 0 {
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.CellFormat(0, 5, "Precios con impuestos incluidos", "", 0, "R", false, 0, "")
+		pdf.Ln(5)
+	}
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "B", 10)
+	if check.Status == models.SaleCheckOpen {
+		pdf.CellFormat(0, 6, "Pendiente de pago", "", 0, "L", false, 0, "")
+		pdf.Ln(6)
+	} else {
+		pdf.CellFormat(0, 6, "Metodos de pago:", "", 0, "L", false, 0, "")
+		pdf.Ln(6)
+	}
+	pdf.SetFont("Helvetica", "", 10)
+	for _, p := range payments {
+		if p.CheckID == nil || *p.CheckID != check.ID {
+			continue
+		}
+		line := fmt.Sprintf("  - %s: $%s", paymentMethodLabel(p.Method), p.Amount)
+		if p.Tip > 0 {
+			line += fmt.Sprintf(" + propina $%s", p.Tip)
+		}
+		if p.Reference != "" {
+			line += " (Ref: " + p.Reference + ")"
+		}
+		pdf.CellFormat(0, 5, line, "", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateCreditNote genera la nota de crédito de una devolución
+func (s *PDFService) GenerateCreditNote(ctx context.Context, restaurantID, refundID uuid.UUID) ([]byte, error) {
+	refund, err := s.refundRepo.GetByID(ctx, restaurantID, refundID)
+	if err != nil {
+		return nil, err
+	}
+
+	refundItems, err := s.refundRepo.GetItems(ctx, refundID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.refundRepo.GetPayments(ctx, refundID)
+	if err != nil {
+		return nil, err
+	}
+
+	saleItems, err := s.saleRepo.GetItems(ctx, refund.SaleID)
+	if err != nil {
+		return nil, err
+	}
+	saleItemByID := make(map[uuid.UUID]*models.SaleItem, len(saleItems))
+	for _, it := range saleItems {
+		saleItemByID[it.ID] = it
+	}
+
+	restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+
+	writeRestaurantHeader(pdf, restaurant)
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, "NOTA DE CREDITO / DEVOLUCION", "", 0, "L", false, 0, "")
+	pdf.Ln(10)
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Nota #%s", refund.ID.String()[:8]), "", 0, "L", false, 0, "")
+	pdf.Ln(4)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Venta original #%s", refund.SaleID.String()[:8]), "", 0, "L", false, 0, "")
+	pdf.Ln(4)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Fecha: %s", refund.CreatedAt.Format("02/01/2006 15:04")), "", 0, "L", false, 0, "")
+	pdf.Ln(4)
+	if refund.Reason != "" {
+		pdf.CellFormat(0, 6, "Motivo: "+refund.Reason, "", 0, "L", false, 0, "")
+		pdf.Ln(4)
+	}
+	pdf.Ln(8)
+
+	// Tabla
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(115, 7, "Producto", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(20, 7, "Cant", "B", 0, "R", false, 0, "")
+	pdf.CellFormat(50, 7, "Importe", "B", 0, "R", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, it := range refundItems {
+		name := "Producto"
+		if saleItem, ok := saleItemByID[it.SaleItemID]; ok {
+			if product, _ := s.productRepo.GetByID(ctx, restaurantID, saleItem.ProductID); product != nil {
+				name = product.Name
+			}
+			if saleItem.VariantName != "" {
+				name += " (" + saleItem.VariantName + ")"
+			}
+		}
+		pdf.CellFormat(115, 6, name, "", 0, "L", false, 0, "")
+		pdf.CellFormat(20, 6, fmt.Sprintf("%d", it.Quantity), "", 0, "R", false, 0, "")
+		pdf.CellFormat(50, 6, fmt.Sprintf("$%s", it.Amount), "", 0, "R", false, 0, "")
+		pdf.Ln(5)
+	}
+
+	pdf.Ln(8)
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(135, 8, "TOTAL DEVUELTO:", "", 0, "R", false, 0, "")
+	pdf.CellFormat(50, 8, fmt.Sprintf("$%s", refund.Total), "", 0, "R", false, 0, "")
+	pdf.Ln(12)
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 6, "Reembolsado en:", "", 0, "L", false, 0, "")
+	pdf.Ln(6)
+	pdf.SetFont("Helvetica", "", 10)
+	for _, p := range payments {
+		line := fmt.Sprintf("  - %s: $%s", paymentMethodLabel(p.Method), p.Amount)
+		if p.Tip > 0 {
+			line += fmt.Sprintf(" + propina $%s", p.Tip)
+		}
+		if p.Reference != "" {
+			line += " (Ref: " + p.Reference + ")"
+		}
+		pdf.CellFormat(0, 5, line, "", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateZReport genera el corte de caja (reporte Z) de un turno
+func (s *PDFService) GenerateZReport(ctx context.Context, restaurantID, sessionID uuid.UUID) ([]byte, error) {
+	session, err := s.cashSessionRepo.GetByID(ctx, restaurantID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.cashSessionRepo.Summary(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	cashier := session.UserID.String()[:8]
+	if user, _ := s.authRepo.GetUserByID(ctx, restaurantID, session.UserID); user != nil {
+		cashier = user.Email
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+
+	writeRestaurantHeader(pdf, restaurant)
+
+	title := "CORTE DE CAJA - REPORTE Z"
+	if session.Status == models.CashSessionOpen {
+		title = "CORTE PARCIAL - REPORTE X"
+	}
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, title, "", 0, "L", false, 0, "")
+	pdf.Ln(10)
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Turno #%s", session.ID.String()[:8]), "", 0, "L", false, 0, "")
+	pdf.Ln(4)
+	pdf.CellFormat(0, 6, "Cajero: "+cashier, "", 0, "L", false, 0, "")
+	pdf.Ln(4)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Apertura: %s", session.OpenedAt.Format("02/01/2006 15:04")), "", 0, "L", false, 0, "")
+	pdf.Ln(4)
+	if session.ClosedAt != nil {
+		pdf.CellFormat(0, 6, fmt.Sprintf("Cierre: %s", session.ClosedAt.Format("02/01/2006 15:04")), "", 0, "L", false, 0, "")
+		pdf.Ln(4)
+	}
+	pdf.Ln(8)
+
+	row := func(label string, value string) {
+		pdf.CellFormat(135, 6, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(50, 6, value, "", 0, "R", false, 0, "")
+		pdf.Ln(6)
+	}
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 7, "Ventas", "B", 0, "L", false, 0, "")
+	pdf.Ln(8)
+	pdf.SetFont("Helvetica", "", 10)
+	row(fmt.Sprintf("Ventas completadas (%d)", summary.SalesCount), fmt.Sprintf("$%s", summary.SalesTotal))
+	row(fmt.Sprintf("Ventas anuladas (%d)", summary.CancelledCount), fmt.Sprintf("$%s", summary.CancelledTotal))
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 7, "Cobros por metodo", "B", 0, "L", false, 0, "")
+	pdf.Ln(8)
+	pdf.SetFont("Helvetica", "", 10)
+	for _, t := range summary.Payments {
+		row(fmt.Sprintf("%s (%d)", paymentMethodLabel(t.Method), t.Count), fmt.Sprintf("$%s", t.Amount))
+	}
+	if summary.TipsTotal > 0 {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(0, 7, "Propinas por metodo", "B", 0, "L", false, 0, "")
+		pdf.Ln(8)
+		pdf.SetFont("Helvetica", "", 10)
+		for _, t := range summary.Payments {
+			if t.Tips > 0 {
+				row(paymentMethodLabel(t.Method), fmt.Sprintf("$%s", t.Tips))
+			}
+		}
+		row("Total propinas", fmt.Sprintf("$%s", summary.TipsTotal))
+	}
+	if len(summary.Refunds) > 0 {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(0, 7, "Devoluciones por metodo", "B", 0, "L", false, 0, "")
+		pdf.Ln(8)
+		pdf.SetFont("Helvetica", "", 10)
+		for _, t := range summary.Refunds {
+			row(fmt.Sprintf("%s (%d)", paymentMethodLabel(t.Method), t.Count), fmt.Sprintf("-$%s", t.Amount))
+		}
+	}
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 7, "Efectivo", "B", 0, "L", false, 0, "")
+	pdf.Ln(8)
+	pdf.SetFont("Helvetica", "", 10)
+	row("Fondo inicial", fmt.Sprintf("$%s", session.OpeningFloat))
+	expected := summary.ExpectedCash
+	if session.ExpectedCash != nil {
+		expected = *session.ExpectedCash // lo calculado al cerrar el turno
+	}
+	row("Efectivo esperado", fmt.Sprintf("$%s", expected))
+	if session.CountedCash != nil {
+		row("Efectivo contado", fmt.Sprintf("$%s", *session.CountedCash))
+	}
+	if session.Difference != nil {
+		pdf.SetFont("Helvetica", "B", 12)
+		label := "Diferencia"
+		switch {
+		case *session.Difference > 0:
+			label = "Diferencia (sobrante)"
+		case *session.Difference < 0:
+			label = "Diferencia (faltante)"
+		}
+		row(label, fmt.Sprintf("$%s", *session.Difference))
+	}
+	if session.Notes != "" {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 5, "Notas: "+session.Notes, "", "L", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeRestaurantHeader escribe nombre, dirección, RFC/NIT y teléfono del restaurante
+func writeRestaurantHeader(pdf *gofpdf.Fpdf, restaurant *models.Restaurant) {
+	pdf.SetX(20)
+	pdf.SetY(20)
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 8, restaurant.Name, "", 0, "L", false, 0, "")
+	pdf.Ln(10)
+
+	pdf.SetFont("Helvetica", "", 10)
+	if restaurant.Address != "" {
+		pdf.CellFormat(0, 6, restaurant.Address, "", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+	if restaurant.TaxID != "" {
+		pdf.CellFormat(0, 6, "RFC/NIT: "+restaurant.TaxID, "", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+	if restaurant.Phone != "" {
+		pdf.CellFormat(0, 6, "Tel: "+restaurant.Phone, "", 0, "L", false, 0, "")
+		pdf.Ln(10)
+	}
+}
+
+func paymentMethodLabel(method string) string {
+	switch method {
+	case "cash":
+		return "Efectivo"
+	case "card":
+		return "Tarjeta"
+	case "transfer":
+		return "Transferencia"
+	}
+	return method
+}
+
+// stampVoid marca el documento como anulado: una leyenda con fecha y motivo
+// y una marca de agua diagonal sobre la página
+func stampVoid(pdf *gofpdf.Fpdf, sale *models.Sale) {
+	pdf.SetTextColor(200, 0, 0)
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, "VENTA ANULADA", "1", 0, "C", false, 0, "")
+	pdf.Ln(9)
+	pdf.SetFont("Helvetica", "", 10)
+	if sale.CancelledAt != nil {
+		pdf.CellFormat(0, 5, fmt.Sprintf("Anulada el %s", sale.CancelledAt.Format("02/01/2006 15:04")), "", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+	if sale.CancelReason != "" {
+		pdf.MultiCell(0, 5, "Motivo: "+sale.CancelReason, "", "L", false)
+	}
+	pdf.Ln(6)
+
+	x, y := pdf.GetXY()
+	pdf.SetFont("Helvetica", "B", 72)
+	pdf.SetTextColor(235, 180, 180)
+	pdf.TransformBegin()
+	pdf.TransformRotate(45, 105, 160)
+	pdf.Text(40, 180, "ANULADA")
+	pdf.TransformEnd()
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetXY(x, y)
+}
+
+// shareLabel muestra las unidades que representa num/den de una línea de qty
+// unidades: "2" si es un número entero, "1/3" si es una fracción
+func shareLabel(num, den, qty int) string {
+	units, d := int64(num*qty), int64(den)
+	g := gcd(units, d)
+	units, d = units/g, d/g
+	if d == 1 {
+		return fmt.Sprintf("%d", units)
+	}
+	return fmt.Sprintf("%d/%d", units, d)
+}
+
+// taxLabel arma la etiqueta de una línea de impuesto, p. ej. "IVA 16%" o "IVA 8.5%"
+func taxLabel(t *models.SaleTax) string {
+	if t.Exempt {
+		return t.Name + " (exento)"
+	}
+	rate := fmt.Sprintf("%d", t.RateBps/100)
+	if frac := t.RateBps % 100; frac != 0 {
+		rate = strings.TrimRight(fmt.Sprintf("%s.%02d", rate, frac), "0")
+	}
+	return fmt.Sprintf("%s %s%%", t.Name, rate)
+}
+
+// discountLabel describe un descuento, p. ej. "Descuento 10% (cortesía)"
+func discountLabel(discountType string, value money.Money, reason string) string {
+	label := "Descuento"
+	if discountType == models.DiscountPercent {
+		label += " " + strings.TrimSuffix(strings.TrimRight(value.String(), "0"), ".") + "%"
+	}
+	if reason != "" {
+		label += " (" + reason + ")"
+	}
+	return label
+}
+
+// componentLine describe un componente de combo; el recargo se muestra solo si existe
+func (s *PDFService) componentLine(ctx context.Context, restaurantID uuid.UUID, c *models.SaleItem) string {
+	name := "Producto"
+	if product, _ := s.productRepo.GetByID(ctx, restaurantID, c.ProductID); product != nil {
+		name = product.Name
+	}
+	if c.VariantName != "" {
+		name += " (" + c.VariantName + ")"
+	}
+	line := fmt.Sprintf("  > %s: %s x%d", c.ComboSlotName, name, c.Quantity)
+	if c.UnitPrice > 0 {
+		line += fmt.Sprintf(" +$%s", c.UnitPrice.Times(c.Quantity))
+	}
+	return line
+}
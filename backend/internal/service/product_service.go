@@ -2,41 +2,63 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/events"
 	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
 	"github.com/pos-saas/restaurant-pos/internal/repository"
 )
 
 type ProductService struct {
+	txManager    *repository.TxManager
 	productRepo  *repository.ProductRepository
 	categoryRepo *repository.CategoryRepository
+	taxRateRepo  *repository.TaxRateRepository
+	variantRepo  *repository.ProductVariantRepository
+	comboRepo    *repository.ComboRepository
+	kitchenRepo  *repository.KitchenRepository
+	publisher    events.Publisher
 }
 
-func NewProductService(productRepo *repository.ProductRepository, categoryRepo *repository.CategoryRepository) *ProductService {
+func NewProductService(txManager *repository.TxManager, productRepo *repository.ProductRepository, categoryRepo *repository.CategoryRepository, taxRateRepo *repository.TaxRateRepository, variantRepo *repository.ProductVariantRepository, comboRepo *repository.ComboRepository, kitchenRepo *repository.KitchenRepository, publisher events.Publisher) *ProductService {
 	return &ProductService{
+		txManager:    txManager,
 		productRepo:  productRepo,
 		categoryRepo: categoryRepo,
+		taxRateRepo:  taxRateRepo,
+		variantRepo:  variantRepo,
+		comboRepo:    comboRepo,
+		kitchenRepo:  kitchenRepo,
+		publisher:    publisher,
 	}
 }
 
 type CreateProductInput struct {
-	CategoryID  *string  `json:"category_id"`
-	Name        string   `json:"name" binding:"required"`
-	Description string   `json:"description"`
-	Price       float64  `json:"price" binding:"required,gt=0"`
-	ImageURL    string   `json:"image_url"`
-	Active      bool     `json:"active"`
+	CategoryID  *string     `json:"category_id"`
+	Name        string      `json:"name" binding:"required"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price" binding:"required,gt=0"`
+	ImageURL    string      `json:"image_url"`
+	Active      bool        `json:"active"`
+	TaxRateID   *string     `json:"tax_rate_id"`
+	StationID   *string     `json:"station_id"`                                  // sin estación usa la de su categoría
+	Type        string      `json:"type" binding:"omitempty,oneof=simple combo"` // por defecto simple
 }
 
 type UpdateProductInput struct {
-	CategoryID  *string  `json:"category_id"`
-	Name        *string  `json:"name"`
-	Description *string  `json:"description"`
-	Price       *float64 `json:"price"`
-	ImageURL    *string  `json:"image_url"`
-	Active      *bool    `json:"active"`
+	CategoryID  *string      `json:"category_id"`
+	Name        *string      `json:"name"`
+	Description *string      `json:"description"`
+	Price       *money.Money `json:"price"`
+	ImageURL    *string      `json:"image_url"`
+	Active      *bool        `json:"active"`
+	TaxRateID   *string      `json:"tax_rate_id"` // "" quita la tasa propia
+	StationID   *string      `json:"station_id"`  // "" quita la estación propia
+	Type        *string      `json:"type" binding:"omitempty,oneof=simple combo"`
 }
 
 func (s *ProductService) Create(ctx context.Context, restaurantID uuid.UUID, input CreateProductInput) (*models.Product, error) {
@@ -55,6 +77,24 @@ func (s *ProductService) Create(ctx context.Context, restaurantID uuid.UUID, inp
 		categoryID = &id
 	}
 
+	var taxRateID *uuid.UUID
+	if input.TaxRateID != nil {
+		id, err := resolveTaxRateID(ctx, s.taxRateRepo, restaurantID, *input.TaxRateID)
+		if err != nil {
+			return nil, err
+		}
+		taxRateID = id
+	}
+
+	var stationID *uuid.UUID
+	if input.StationID != nil {
+		id, err := resolveStationID(ctx, s.kitchenRepo, restaurantID, *input.StationID)
+		if err != nil {
+			return nil, err
+		}
+		stationID = id
+	}
+
 	product := &models.Product{
 		ID:           uuid.New(),
 		RestaurantID: restaurantID,
@@ -64,20 +104,63 @@ func (s *ProductService) Create(ctx context.Context, restaurantID uuid.UUID, inp
 		Price:        input.Price,
 		ImageURL:     input.ImageURL,
 		Active:       input.Active,
+		TaxRateID:    taxRateID,
+		StationID:    stationID,
+		Type:         input.Type,
+	}
+	if product.Type == "" {
+		product.Type = models.ProductTypeSimple
 	}
 
 	if err := s.productRepo.Create(ctx, product); err != nil {
 		return nil, err
 	}
+	s.publisher.Publish(events.New(events.ProductUpdated, restaurantID, product))
 	return product, nil
 }
 
 func (s *ProductService) GetByID(ctx context.Context, restaurantID, productID uuid.UUID) (*models.Product, error) {
-	return s.productRepo.GetByID(ctx, restaurantID, productID)
+	product, err := s.productRepo.GetByID(ctx, restaurantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachDetails(ctx, restaurantID, []*models.Product{product}); err != nil {
+		return nil, err
+	}
+	return product, nil
 }
 
 func (s *ProductService) List(ctx context.Context, restaurantID uuid.UUID, categoryID *uuid.UUID, activeOnly bool) ([]*models.Product, error) {
-	return s.productRepo.List(ctx, restaurantID, categoryID, activeOnly)
+	products, err := s.productRepo.List(ctx, restaurantID, categoryID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachDetails(ctx, restaurantID, products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// attachDetails carga variantes y espacios de combo de los productos con una
+// consulta por tipo
+func (s *ProductService) attachDetails(ctx context.Context, restaurantID uuid.UUID, products []*models.Product) error {
+	ids := make([]uuid.UUID, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	variants, err := s.variantRepo.ListByProducts(ctx, restaurantID, ids)
+	if err != nil {
+		return err
+	}
+	slots, err := s.comboRepo.ListByCombos(ctx, restaurantID, ids)
+	if err != nil {
+		return err
+	}
+	for _, p := range products {
+		p.Variants = variants[p.ID]
+		p.Slots = slots[p.ID]
+	}
+	return nil
 }
 
 func (s *ProductService) Update(ctx context.Context, restaurantID, productID uuid.UUID, input UpdateProductInput) (*models.Product, error) {
@@ -115,13 +198,230 @@ func (s *ProductService) Update(ctx context.Context, restaurantID, productID uui
 	if input.Active != nil {
 		product.Active = *input.Active
 	}
+	if input.TaxRateID != nil {
+		id, err := resolveTaxRateID(ctx, s.taxRateRepo, restaurantID, *input.TaxRateID)
+		if err != nil {
+			return nil, err
+		}
+		product.TaxRateID = id
+	}
+	if input.StationID != nil {
+		id, err := resolveStationID(ctx, s.kitchenRepo, restaurantID, *input.StationID)
+		if err != nil {
+			return nil, err
+		}
+		product.StationID = id
+	}
+	if input.Type != nil && *input.Type != "" {
+		product.Type = *input.Type
+	}
 
 	if err := s.productRepo.Update(ctx, product); err != nil {
 		return nil, err
 	}
+	s.publisher.Publish(events.New(events.ProductUpdated, restaurantID, product))
 	return product, nil
 }
 
 func (s *ProductService) Delete(ctx context.Context, restaurantID, productID uuid.UUID) error {
-	return s.productRepo.Delete(ctx, restaurantID, productID)
+	if err := s.productRepo.Delete(ctx, restaurantID, productID); err != nil {
+		return err
+	}
+	s.publisher.Publish(events.New(events.ProductDeleted, restaurantID, map[string]uuid.UUID{"id": productID}))
+	return nil
+}
+
+// publishProduct avisa del producto con sus variantes y combo tal como lo
+// devuelve GET /products/:id. Si no se puede leer no se avisa: las pantallas
+// lo verán al volver a consultar.
+func (s *ProductService) publishProduct(ctx context.Context, restaurantID, productID uuid.UUID) {
+	product, err := s.GetByID(ctx, restaurantID, productID)
+	if err != nil {
+		return
+	}
+	s.publisher.Publish(events.New(events.ProductUpdated, restaurantID, product))
+}
+
+type ProductVariantInput struct {
+	Name      string      `json:"name" binding:"required"`
+	SKU       string      `json:"sku"`
+	Price     money.Money `json:"price" binding:"gte=0"`
+	Active    *bool       `json:"active"` // por defecto true
+	SortOrder int         `json:"sort_order"`
+}
+
+func (s *ProductService) ListVariants(ctx context.Context, restaurantID, productID uuid.UUID) ([]*models.ProductVariant, error) {
+	if _, err := s.productRepo.GetByID(ctx, restaurantID, productID); err != nil {
+		return nil, err
+	}
+	variants, err := s.variantRepo.ListByProducts(ctx, restaurantID, []uuid.UUID{productID})
+	if err != nil {
+		return nil, err
+	}
+	if variants[productID] == nil {
+		return []*models.ProductVariant{}, nil
+	}
+	return variants[productID], nil
+}
+
+func (s *ProductService) CreateVariant(ctx context.Context, restaurantID, productID uuid.UUID, input ProductVariantInput) (*models.ProductVariant, error) {
+	if _, err := s.productRepo.GetByID(ctx, restaurantID, productID); err != nil {
+		return nil, err
+	}
+	variant := &models.ProductVariant{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		ProductID:    productID,
+		Name:         input.Name,
+		SKU:          input.SKU,
+		Price:        input.Price,
+		Active:       input.Active == nil || *input.Active,
+		SortOrder:    input.SortOrder,
+	}
+	if err := s.variantRepo.Create(ctx, variant); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe una variante con ese SKU")
+		}
+		return nil, err
+	}
+	s.publishProduct(ctx, restaurantID, productID)
+	return variant, nil
+}
+
+func (s *ProductService) UpdateVariant(ctx context.Context, restaurantID, productID, variantID uuid.UUID, input ProductVariantInput) (*models.ProductVariant, error) {
+	variant, err := s.variantRepo.GetByID(ctx, restaurantID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant.ProductID != productID {
+		return nil, errors.ErrNotFound
+	}
+	variant.Name = input.Name
+	variant.SKU = input.SKU
+	variant.Price = input.Price
+	if input.Active != nil {
+		variant.Active = *input.Active
+	}
+	variant.SortOrder = input.SortOrder
+	if err := s.variantRepo.Update(ctx, variant); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe una variante con ese SKU")
+		}
+		return nil, err
+	}
+	s.publishProduct(ctx, restaurantID, productID)
+	return variant, nil
+}
+
+func (s *ProductService) DeleteVariant(ctx context.Context, restaurantID, productID, variantID uuid.UUID) error {
+	variant, err := s.variantRepo.GetByID(ctx, restaurantID, variantID)
+	if err != nil {
+		return err
+	}
+	if variant.ProductID != productID {
+		return errors.ErrNotFound
+	}
+	if err := s.variantRepo.Delete(ctx, restaurantID, variantID); err != nil {
+		return err
+	}
+	s.publishProduct(ctx, restaurantID, productID)
+	return nil
+}
+
+// ComboSlotInput: quantity es cuántas unidades del componente lleva cada combo
+// (0 cuenta como 1). required es true por defecto.
+type ComboSlotInput struct {
+	Name     string                 `json:"name" binding:"required"`
+	Quantity int                    `json:"quantity" binding:"gte=0"`
+	Required *bool                  `json:"required"`
+	Options  []ComboSlotOptionInput `json:"options" binding:"required,min=1,dive"`
+}
+
+type ComboSlotOptionInput struct {
+	ProductID string      `json:"product_id" binding:"required"`
+	Upcharge  money.Money `json:"upcharge" binding:"gte=0"`
+}
+
+type SetComboSlotsInput struct {
+	Slots []ComboSlotInput `json:"slots" binding:"dive"`
+}
+
+func (s *ProductService) ListComboSlots(ctx context.Context, restaurantID, productID uuid.UUID) ([]*models.ComboSlot, error) {
+	if _, err := s.productRepo.GetByID(ctx, restaurantID, productID); err != nil {
+		return nil, err
+	}
+	slots, err := s.comboRepo.ListByCombos(ctx, restaurantID, []uuid.UUID{productID})
+	if err != nil {
+		return nil, err
+	}
+	if slots[productID] == nil {
+		return []*models.ComboSlot{}, nil
+	}
+	return slots[productID], nil
+}
+
+// SetComboSlots reemplaza los espacios del combo; el orden recibido es el orden
+// en que se muestran. Los componentes deben ser productos simples del restaurante.
+func (s *ProductService) SetComboSlots(ctx context.Context, restaurantID, productID uuid.UUID, input SetComboSlotsInput) ([]*models.ComboSlot, error) {
+	combo, err := s.productRepo.GetByID(ctx, restaurantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if combo.Type != models.ProductTypeCombo {
+		return nil, NewValidationError("type", "el producto no es un combo")
+	}
+
+	slots := make([]*models.ComboSlot, len(input.Slots))
+	for i, in := range input.Slots {
+		slot := &models.ComboSlot{
+			ID:        uuid.New(),
+			ComboID:   productID,
+			Name:      in.Name,
+			Quantity:  in.Quantity,
+			Required:  in.Required == nil || *in.Required,
+			SortOrder: i,
+		}
+		if slot.Quantity == 0 {
+			slot.Quantity = 1
+		}
+
+		seen := make(map[uuid.UUID]bool, len(in.Options))
+		for j, opt := range in.Options {
+			id, err := uuid.Parse(opt.ProductID)
+			if err != nil {
+				return nil, NewValidationError("options.product_id", "UUID inválido")
+			}
+			if seen[id] {
+				return nil, NewValidationError("options.product_id", fmt.Sprintf("producto repetido en %s", in.Name))
+			}
+			component, err := s.productRepo.GetByID(ctx, restaurantID, id)
+			if err != nil {
+				if errors.Is(err, errors.ErrNotFound) {
+					return nil, NewValidationError("options.product_id", "producto no encontrado")
+				}
+				return nil, err
+			}
+			if component.Type == models.ProductTypeCombo {
+				return nil, NewValidationError("options.product_id", "un combo no puede contener otro combo")
+			}
+			seen[id] = true
+			slot.Options = append(slot.Options, &models.ComboSlotOption{
+				SlotID:      slot.ID,
+				ProductID:   id,
+				ProductName: component.Name,
+				Upcharge:    opt.Upcharge,
+				SortOrder:   j,
+			})
+		}
+		slots[i] = slot
+	}
+
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		return s.comboRepo.WithTx(tx).SetSlots(ctx, restaurantID, productID, slots)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publishProduct(ctx, restaurantID, productID)
+	return s.ListComboSlots(ctx, restaurantID, productID)
 }
@@ -0,0 +1,587 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+	"github.com/pos-saas/restaurant-pos/internal/quantity"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+type PurchasingService struct {
+	txManager      *repository.TxManager
+	purchasingRepo *repository.PurchasingRepository
+	inventoryRepo  *repository.InventoryRepository
+	productRepo    *repository.ProductRepository
+	variantRepo    *repository.ProductVariantRepository
+	comboRepo      *repository.ComboRepository
+	categoryRepo   *repository.CategoryRepository
+	taxRateRepo    *repository.TaxRateRepository
+	authRepo       *repository.AuthRepository
+}
+
+func NewPurchasingService(txManager *repository.TxManager, purchasingRepo *repository.PurchasingRepository, inventoryRepo *repository.InventoryRepository, productRepo *repository.ProductRepository, variantRepo *repository.ProductVariantRepository, comboRepo *repository.ComboRepository, categoryRepo *repository.CategoryRepository, taxRateRepo *repository.TaxRateRepository, authRepo *repository.AuthRepository) *PurchasingService {
+	return &PurchasingService{
+		txManager:      txManager,
+		purchasingRepo: purchasingRepo,
+		inventoryRepo:  inventoryRepo,
+		productRepo:    productRepo,
+		variantRepo:    variantRepo,
+		comboRepo:      comboRepo,
+		categoryRepo:   categoryRepo,
+		taxRateRepo:    taxRateRepo,
+		authRepo:       authRepo,
+	}
+}
+
+type SupplierInput struct {
+	Name        string `json:"name" binding:"required"`
+	ContactName string `json:"contact_name"`
+	Phone       string `json:"phone"`
+	Email       string `json:"email" binding:"omitempty,email"`
+	Notes       string `json:"notes"`
+	Active      *bool  `json:"active"` // por defecto true
+}
+
+type PurchaseOrderItemInput struct {
+	IngredientID string       `json:"ingredient_id" binding:"required"`
+	Quantity     quantity.Qty `json:"quantity" binding:"gt=0"`
+	UnitCost     money.Money  `json:"unit_cost" binding:"gte=0"` // por unidad del insumo
+}
+
+type CreatePurchaseOrderInput struct {
+	SupplierID string                   `json:"supplier_id" binding:"required"`
+	Items      []PurchaseOrderItemInput `json:"items" binding:"required,min=1,dive"`
+	Notes      string                   `json:"notes"`
+	ExpectedAt string                   `json:"expected_at"` // YYYY-MM-DD
+}
+
+type ListPurchaseOrdersInput struct {
+	Status     string `form:"status" binding:"omitempty,oneof=open partial received cancelled"`
+	SupplierID string `form:"supplier_id"`
+	Limit      int    `form:"limit" binding:"omitempty,gt=0"`
+}
+
+// ReceiveItemInput: unit_cost vacío toma el costo pactado en la orden
+type ReceiveItemInput struct {
+	PurchaseOrderItemID string       `json:"purchase_order_item_id" binding:"required"`
+	Quantity            quantity.Qty `json:"quantity" binding:"gt=0"`
+	UnitCost            *money.Money `json:"unit_cost" binding:"omitempty,gte=0"`
+}
+
+// ReceiveInput: sin items se recibe todo lo pendiente de la orden
+type ReceiveInput struct {
+	Items []ReceiveItemInput `json:"items" binding:"dive"`
+	Notes string             `json:"notes"`
+}
+
+type PurchaseLedgerInput struct {
+	IngredientID string `form:"ingredient_id"`
+	SupplierID   string `form:"supplier_id"`
+	From         string `form:"from"` // RFC3339 o YYYY-MM-DD
+	To           string `form:"to"`   // RFC3339 o YYYY-MM-DD (día inclusivo)
+	Limit        int    `form:"limit" binding:"omitempty,gt=0"`
+}
+
+const (
+	defaultPurchaseListLimit = 50
+	maxPurchaseListLimit     = 200
+)
+
+func purchaseListLimit(limit int) int {
+	if limit <= 0 {
+		return defaultPurchaseListLimit
+	}
+	if limit > maxPurchaseListLimit {
+		return maxPurchaseListLimit
+	}
+	return limit
+}
+
+func (s *PurchasingService) CreateSupplier(ctx context.Context, restaurantID uuid.UUID, input SupplierInput) (*models.Supplier, error) {
+	supplier := &models.Supplier{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		Active:       input.Active == nil || *input.Active,
+	}
+	applySupplierInput(supplier, input)
+	if err := s.purchasingRepo.CreateSupplier(ctx, supplier); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe un proveedor con ese nombre")
+		}
+		return nil, err
+	}
+	return supplier, nil
+}
+
+func applySupplierInput(supplier *models.Supplier, input SupplierInput) {
+	supplier.Name = strings.TrimSpace(input.Name)
+	supplier.ContactName = strings.TrimSpace(input.ContactName)
+	supplier.Phone = strings.TrimSpace(input.Phone)
+	supplier.Email = strings.TrimSpace(input.Email)
+	supplier.Notes = strings.TrimSpace(input.Notes)
+}
+
+func (s *PurchasingService) ListSuppliers(ctx context.Context, restaurantID uuid.UUID, activeOnly bool) ([]*models.Supplier, error) {
+	return s.purchasingRepo.ListSuppliers(ctx, restaurantID, activeOnly)
+}
+
+func (s *PurchasingService) GetSupplier(ctx context.Context, restaurantID, supplierID uuid.UUID) (*models.Supplier, error) {
+	return s.purchasingRepo.GetSupplier(ctx, restaurantID, supplierID)
+}
+
+func (s *PurchasingService) UpdateSupplier(ctx context.Context, restaurantID, supplierID uuid.UUID, input SupplierInput) (*models.Supplier, error) {
+	supplier, err := s.purchasingRepo.GetSupplier(ctx, restaurantID, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	applySupplierInput(supplier, input)
+	if input.Active != nil {
+		supplier.Active = *input.Active
+	}
+	if err := s.purchasingRepo.UpdateSupplier(ctx, supplier); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe un proveedor con ese nombre")
+		}
+		return nil, err
+	}
+	return supplier, nil
+}
+
+func (s *PurchasingService) DeleteSupplier(ctx context.Context, restaurantID, supplierID uuid.UUID) error {
+	err := s.purchasingRepo.DeleteSupplier(ctx, restaurantID, supplierID)
+	if errors.Is(err, errors.ErrConflict) {
+		return NewAppError(errors.ErrConflict, 409, "el proveedor tiene órdenes de compra; desactívalo en su lugar")
+	}
+	return err
+}
+
+func (s *PurchasingService) CreateOrder(ctx context.Context, restaurantID, userID uuid.UUID, input CreatePurchaseOrderInput) (*models.PurchaseOrder, error) {
+	supplierID, err := uuid.Parse(input.SupplierID)
+	if err != nil {
+		return nil, NewValidationError("supplier_id", "UUID inválido")
+	}
+	supplier, err := s.purchasingRepo.GetSupplier(ctx, restaurantID, supplierID)
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil, NewValidationError("supplier_id", "proveedor no encontrado")
+		}
+		return nil, err
+	}
+	if !supplier.Active {
+		return nil, NewValidationError("supplier_id", "el proveedor está inactivo")
+	}
+
+	po := &models.PurchaseOrder{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		SupplierID:   supplierID,
+		SupplierName: supplier.Name,
+		Status:       models.PurchaseOrderOpen,
+		Notes:        strings.TrimSpace(input.Notes),
+		CreatedBy:    userID,
+	}
+	if input.ExpectedAt != "" {
+		expected, err := time.Parse("2006-01-02", input.ExpectedAt)
+		if err != nil {
+			return nil, NewValidationError("expected_at", "fecha inválida, usa YYYY-MM-DD")
+		}
+		po.ExpectedAt = &expected
+	}
+
+	seen := make(map[uuid.UUID]bool, len(input.Items))
+	for _, in := range input.Items {
+		ingredientID, err := uuid.Parse(in.IngredientID)
+		if err != nil {
+			return nil, NewValidationError("items.ingredient_id", "UUID inválido")
+		}
+		if seen[ingredientID] {
+			return nil, NewValidationError("items.ingredient_id", "insumo repetido en la orden")
+		}
+		seen[ingredientID] = true
+		ingredient, err := s.inventoryRepo.GetIngredient(ctx, restaurantID, ingredientID)
+		if err != nil {
+			if errors.Is(err, errors.ErrNotFound) {
+				return nil, NewValidationError("items.ingredient_id", "insumo no encontrado")
+			}
+			return nil, err
+		}
+
+		po.Items = append(po.Items, &models.PurchaseOrderItem{
+			ID:              uuid.New(),
+			PurchaseOrderID: po.ID,
+			IngredientID:    ingredientID,
+			IngredientName:  ingredient.Name,
+			Unit:            ingredient.Unit,
+			Quantity:        in.Quantity,
+			UnitCost:        in.UnitCost,
+		})
+		po.Total += lineCost(in.UnitCost, in.Quantity)
+	}
+
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		return s.purchasingRepo.WithTx(tx).CreateOrder(ctx, po)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrder(ctx, restaurantID, po.ID)
+}
+
+// lineCost es el costo de una cantidad de insumo; unitCost es por unidad entera
+func lineCost(unitCost money.Money, qty quantity.Qty) money.Money {
+	return unitCost.MulDiv(qty.Milli(), 1000)
+}
+
+func (s *PurchasingService) ListOrders(ctx context.Context, restaurantID uuid.UUID, input ListPurchaseOrdersInput) ([]*models.PurchaseOrder, error) {
+	filter := repository.PurchaseOrderListFilter{
+		Status: input.Status,
+		Limit:  purchaseListLimit(input.Limit),
+	}
+	if input.SupplierID != "" {
+		id, err := uuid.Parse(input.SupplierID)
+		if err != nil {
+			return nil, NewValidationError("supplier_id", "UUID inválido")
+		}
+		filter.SupplierID = &id
+	}
+	return s.purchasingRepo.ListOrders(ctx, restaurantID, filter)
+}
+
+// GetOrder devuelve la orden con sus líneas y las entregas recibidas
+func (s *PurchasingService) GetOrder(ctx context.Context, restaurantID, orderID uuid.UUID) (*models.PurchaseOrder, error) {
+	po, err := s.purchasingRepo.GetOrder(ctx, restaurantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if po.Items, err = s.purchasingRepo.ListOrderItems(ctx, po.ID); err != nil {
+		return nil, err
+	}
+	if po.Receipts, err = s.purchasingRepo.ListReceipts(ctx, po.ID); err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+// Receive registra una entrega (parcial o total) de la orden: suma al stock,
+// deja el costo real en el historial y actualiza el estado de la orden.
+func (s *PurchasingService) Receive(ctx context.Context, restaurantID, orderID, userID uuid.UUID, input ReceiveInput) (*models.PurchaseOrder, error) {
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.purchasingRepo.WithTx(tx)
+		po, err := repo.GetOrderForUpdate(ctx, restaurantID, orderID)
+		if err != nil {
+			return err
+		}
+		if po.Status != models.PurchaseOrderOpen && po.Status != models.PurchaseOrderPartial {
+			return NewAppError(errors.ErrConflict, 409, "la orden ya fue recibida o está cancelada")
+		}
+		items, err := repo.ListOrderItems(ctx, po.ID)
+		if err != nil {
+			return err
+		}
+
+		receipt := &models.GoodsReceipt{
+			ID:              uuid.New(),
+			RestaurantID:    restaurantID,
+			PurchaseOrderID: po.ID,
+			ReceivedBy:      userID,
+			Notes:           strings.TrimSpace(input.Notes),
+		}
+		addLine := func(item *models.PurchaseOrderItem, qty quantity.Qty, unitCost money.Money) {
+			line := &models.GoodsReceiptItem{
+				ID:                  uuid.New(),
+				RestaurantID:        restaurantID,
+				GoodsReceiptID:      receipt.ID,
+				PurchaseOrderItemID: item.ID,
+				IngredientID:        item.IngredientID,
+				IngredientName:      item.IngredientName,
+				Unit:                item.Unit,
+				Quantity:            qty,
+				UnitCost:            unitCost,
+				TotalCost:           lineCost(unitCost, qty),
+			}
+			receipt.Items = append(receipt.Items, line)
+			receipt.Total += line.TotalCost
+			item.ReceivedQuantity += qty
+		}
+
+		if len(input.Items) == 0 {
+			for _, item := range items {
+				if pending := item.Quantity - item.ReceivedQuantity; pending > 0 {
+					addLine(item, pending, item.UnitCost)
+				}
+			}
+		} else {
+			byID := make(map[uuid.UUID]*models.PurchaseOrderItem, len(items))
+			for _, item := range items {
+				byID[item.ID] = item
+			}
+			seen := make(map[uuid.UUID]bool, len(input.Items))
+			for _, in := range input.Items {
+				id, err := uuid.Parse(in.PurchaseOrderItemID)
+				if err != nil {
+					return NewValidationError("items.purchase_order_item_id", "UUID inválido")
+				}
+				item, ok := byID[id]
+				if !ok {
+					return NewValidationError("items.purchase_order_item_id", "la línea no pertenece a la orden")
+				}
+				if seen[id] {
+					return NewValidationError("items.purchase_order_item_id", "línea repetida en la recepción")
+				}
+				seen[id] = true
+				if in.Quantity > item.Quantity-item.ReceivedQuantity {
+					return NewValidationError("items.quantity", "se recibe más de lo pendiente de "+item.IngredientName)
+				}
+				unitCost := item.UnitCost
+				if in.UnitCost != nil {
+					unitCost = *in.UnitCost
+				}
+				addLine(item, in.Quantity, unitCost)
+			}
+		}
+		if len(receipt.Items) == 0 {
+			return NewValidationError("items", "no hay nada pendiente por recibir")
+		}
+
+		if err := repo.CreateReceipt(ctx, receipt); err != nil {
+			return err
+		}
+		deltas := make(map[uuid.UUID]quantity.Qty, len(receipt.Items))
+		for _, line := range receipt.Items {
+			if err := repo.AddReceived(ctx, line.PurchaseOrderItemID, line.Quantity); err != nil {
+				return err
+			}
+			deltas[line.IngredientID] += line.Quantity
+		}
+
+		status := models.PurchaseOrderReceived
+		for _, item := range items {
+			if item.ReceivedQuantity < item.Quantity {
+				status = models.PurchaseOrderPartial
+				break
+			}
+		}
+		if err := repo.UpdateOrderStatus(ctx, restaurantID, po.ID, status); err != nil {
+			return err
+		}
+
+		return applyStockMovements(ctx, s.inventoryRepo.WithTx(tx), models.StockMovement{
+			RestaurantID:   restaurantID,
+			Type:           models.StockMovementPurchase,
+			GoodsReceiptID: &receipt.ID,
+			UserID:         &userID,
+		}, deltas)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrder(ctx, restaurantID, orderID)
+}
+
+// CancelOrder cierra una orden que no se va a recibir (o no por completo). Lo
+// ya recibido queda en el stock y en el historial.
+func (s *PurchasingService) CancelOrder(ctx context.Context, restaurantID, orderID uuid.UUID) (*models.PurchaseOrder, error) {
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.purchasingRepo.WithTx(tx)
+		po, err := repo.GetOrderForUpdate(ctx, restaurantID, orderID)
+		if err != nil {
+			return err
+		}
+		if po.Status != models.PurchaseOrderOpen && po.Status != models.PurchaseOrderPartial {
+			return NewAppError(errors.ErrConflict, 409, "la orden ya fue recibida o está cancelada")
+		}
+		return repo.UpdateOrderStatus(ctx, restaurantID, po.ID, models.PurchaseOrderCancelled)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrder(ctx, restaurantID, orderID)
+}
+
+// Ledger devuelve el historial de cantidades recibidas y costos unitarios
+func (s *PurchasingService) Ledger(ctx context.Context, restaurantID uuid.UUID, input PurchaseLedgerInput) ([]*models.GoodsReceiptItem, error) {
+	filter := repository.PurchaseLedgerFilter{Limit: purchaseListLimit(input.Limit)}
+	if input.IngredientID != "" {
+		id, err := uuid.Parse(input.IngredientID)
+		if err != nil {
+			return nil, NewValidationError("ingredient_id", "UUID inválido")
+		}
+		filter.IngredientID = &id
+	}
+	if input.SupplierID != "" {
+		id, err := uuid.Parse(input.SupplierID)
+		if err != nil {
+			return nil, NewValidationError("supplier_id", "UUID inválido")
+		}
+		filter.SupplierID = &id
+	}
+
+	// Las fechas sin hora se interpretan en la zona horaria del restaurante
+	if input.From != "" || input.To != "" {
+		restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+		if err != nil {
+			return nil, err
+		}
+		loc := loadLocation(restaurant.Timezone)
+		if input.From != "" {
+			from, _, err := parseDateParam(input.From, loc)
+			if err != nil {
+				return nil, NewValidationError("from", "fecha inválida")
+			}
+			filter.From = &from
+		}
+		if input.To != "" {
+			to, dateOnly, err := parseDateParam(input.To, loc)
+			if err != nil {
+				return nil, NewValidationError("to", "fecha inválida")
+			}
+			if dateOnly {
+				to = to.AddDate(0, 0, 1)
+			}
+			filter.To = &to
+		}
+	}
+	return s.purchasingRepo.ListLedger(ctx, restaurantID, filter)
+}
+
+// FoodCost calcula el costo de receta de cada producto activo (y de cada
+// variante activa) con el último costo recibido de cada insumo, y el margen
+// frente al precio sin impuesto. Un combo cuesta su propia receta más la del
+// primer producto de cada espacio obligatorio.
+func (s *PurchasingService) FoodCost(ctx context.Context, restaurantID uuid.UUID) ([]*models.ProductCost, error) {
+	restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	taxes, err := newTaxResolver(ctx, restaurant, s.taxRateRepo, s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.productRepo.List(ctx, restaurantID, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(products))
+	var comboIDs []uuid.UUID
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+		if p.Type == models.ProductTypeCombo {
+			comboIDs = append(comboIDs, p.ID)
+		}
+	}
+	variants, err := s.variantRepo.ListByProducts(ctx, restaurantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	slots, err := s.comboRepo.ListByCombos(ctx, restaurantID, comboIDs)
+	if err != nil {
+		return nil, err
+	}
+	// Los componentes de combo pueden ser productos inactivos en el catálogo
+	for _, comboSlots := range slots {
+		for _, sl := range comboSlots {
+			for _, o := range sl.Options {
+				productIDs = append(productIDs, o.ProductID)
+			}
+		}
+	}
+	recipes, err := s.inventoryRepo.ListRecipesFor(ctx, restaurantID, productIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+	unitCosts, err := s.purchasingRepo.LatestUnitCosts(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	byProduct := make(map[uuid.UUID][]*models.RecipeItem)
+	byVariant := make(map[uuid.UUID][]*models.RecipeItem)
+	for _, ri := range recipes {
+		if ri.VariantID != nil {
+			byVariant[*ri.VariantID] = append(byVariant[*ri.VariantID], ri)
+		} else if ri.ProductID != nil {
+			byProduct[*ri.ProductID] = append(byProduct[*ri.ProductID], ri)
+		}
+	}
+
+	// costOf suma la receta; los insumos sin compras quedan en missing
+	costOf := func(pc *models.ProductCost, recipe []*models.RecipeItem, times int) {
+		for _, ri := range recipe {
+			pc.HasRecipe = true
+			unitCost, ok := unitCosts[ri.IngredientID]
+			if !ok {
+				pc.MissingCosts = appendUnique(pc.MissingCosts, ri.IngredientName)
+				continue
+			}
+			pc.Cost += lineCost(unitCost, ri.Quantity.Times(times))
+		}
+	}
+	finish := func(pc *models.ProductCost, product *models.Product, price money.Money) *models.ProductCost {
+		var bps int64
+		if rate := taxes.rateFor(product); rate != nil {
+			bps = rate.RateBps
+		}
+		pc.Price, _, _ = lineTax(price, bps, taxes.inclusive)
+		pc.Margin = pc.Price - pc.Cost
+		if pc.Price > 0 {
+			pc.FoodCostBps = pc.Cost.MulDiv(10000, pc.Price.Cents()).Cents()
+			pc.MarginBps = pc.Margin.MulDiv(10000, pc.Price.Cents()).Cents()
+		}
+		return pc
+	}
+
+	// recipeCost arma el costo de una unidad: su receta más la de los componentes
+	recipeCost := func(product *models.Product, recipe []*models.RecipeItem) *models.ProductCost {
+		pc := &models.ProductCost{ProductID: product.ID, Name: product.Name, Type: product.Type}
+		costOf(pc, recipe, 1)
+		for _, sl := range slots[product.ID] {
+			if sl.Required && len(sl.Options) > 0 {
+				costOf(pc, byProduct[sl.Options[0].ProductID], sl.Quantity)
+			}
+		}
+		return pc
+	}
+
+	result := []*models.ProductCost{}
+	for _, p := range products {
+		var active []*models.ProductVariant
+		for _, v := range variants[p.ID] {
+			if v.Active {
+				active = append(active, v)
+			}
+		}
+		if len(active) == 0 {
+			result = append(result, finish(recipeCost(p, byProduct[p.ID]), p, p.Price))
+			continue
+		}
+		for _, v := range active {
+			recipe := byProduct[p.ID]
+			if len(byVariant[v.ID]) > 0 {
+				recipe = byVariant[v.ID]
+			}
+			pc := recipeCost(p, recipe)
+			variantID := v.ID
+			pc.VariantID = &variantID
+			pc.Name = p.Name + " " + v.Name
+			result = append(result, finish(pc, p, v.Price))
+		}
+	}
+	return result, nil
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, s := range list {
+		if s == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
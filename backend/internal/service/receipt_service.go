@@ -0,0 +1,451 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/escpos"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/permissions"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+// ReceiptService genera los tickets de venta y las comandas para impresoras
+// térmicas: en ESC/POS para enviar directo a la impresora o en PDF del ancho
+// del papel. Ambos formatos comparten el mismo diseño.
+type ReceiptService struct {
+	saleRepo    *repository.SaleRepository
+	productRepo *repository.ProductRepository
+	authRepo    *repository.AuthRepository
+	kitchenRepo *repository.KitchenRepository
+}
+
+func NewReceiptService(saleRepo *repository.SaleRepository, productRepo *repository.ProductRepository, authRepo *repository.AuthRepository, kitchenRepo *repository.KitchenRepository) *ReceiptService {
+	return &ReceiptService{
+		saleRepo:    saleRepo,
+		productRepo: productRepo,
+		authRepo:    authRepo,
+		kitchenRepo: kitchenRepo,
+	}
+}
+
+type ReceiptOptions struct {
+	Width  int  `form:"width" binding:"omitempty,oneof=58 80"` // mm de papel, por defecto 80
+	Drawer bool `form:"drawer"`                                // abre el cajón de dinero (solo ESC/POS; permiso cash:operate)
+}
+
+func (o ReceiptOptions) columns() int {
+	if o.Width == 58 {
+		return escpos.Width58
+	}
+	return escpos.Width80
+}
+
+// ticketWriter es lo que comparten la impresora ESC/POS y el PDF térmico
+type ticketWriter interface {
+	Width() int
+	Align(a escpos.Align)
+	Bold(on bool)
+	Size(doubleWidth, doubleHeight bool)
+	Line(text string)
+	Columns(left, right string)
+	Separator()
+	Feed(n int)
+}
+
+// SaleESCPOS arma el ticket de la venta. Abrir el cajón exige cash:operate:
+// reimprimir un ticket solo requiere poder consultar ventas.
+func (s *ReceiptService) SaleESCPOS(ctx context.Context, restaurantID, saleID uuid.UUID, perms permissions.Set, opts ReceiptOptions) ([]byte, error) {
+	if opts.Drawer && !perms.Has(permissions.CashOperate) {
+		return nil, NewAppError(errors.ErrForbidden, 403, "acceso denegado: abrir el cajón requiere el permiso "+permissions.CashOperate)
+	}
+	p := escpos.New(opts.columns())
+	if err := s.writeSale(ctx, p, restaurantID, saleID); err != nil {
+		return nil, err
+	}
+	p.Cut()
+	if opts.Drawer {
+		p.OpenDrawer()
+	}
+	return p.Bytes(), nil
+}
+
+func (s *ReceiptService) SalePDF(ctx context.Context, restaurantID, saleID uuid.UUID, opts ReceiptOptions) ([]byte, error) {
+	w := newThermalPDF(opts.columns())
+	if err := s.writeSale(ctx, w, restaurantID, saleID); err != nil {
+		return nil, err
+	}
+	return w.Output()
+}
+
+func (s *ReceiptService) KitchenTicketESCPOS(ctx context.Context, restaurantID, ticketID uuid.UUID, opts ReceiptOptions) ([]byte, error) {
+	ticket, err := s.loadKitchenTicket(ctx, restaurantID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	p := escpos.New(opts.columns())
+	writeKitchenTicket(p, ticket)
+	p.Cut()
+	return p.Bytes(), nil
+}
+
+func (s *ReceiptService) KitchenTicketPDF(ctx context.Context, restaurantID, ticketID uuid.UUID, opts ReceiptOptions) ([]byte, error) {
+	ticket, err := s.loadKitchenTicket(ctx, restaurantID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	w := newThermalPDF(opts.columns())
+	writeKitchenTicket(w, ticket)
+	return w.Output()
+}
+
+// writeSale escribe el ticket de venta con el mismo contenido que la factura
+func (s *ReceiptService) writeSale(ctx context.Context, w ticketWriter, restaurantID, saleID uuid.UUID) error {
+	sale, err := s.saleRepo.GetByID(ctx, restaurantID, saleID)
+	if err != nil {
+		return err
+	}
+	items, err := s.saleRepo.GetItems(ctx, saleID)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		toppings, err := s.saleRepo.GetItemToppings(ctx, item.ID)
+		if err != nil {
+			return err
+		}
+		item.Toppings = toppings
+	}
+	items = nestComponents(items)
+	payments, err := s.saleRepo.GetPayments(ctx, saleID)
+	if err != nil {
+		return err
+	}
+	taxes, err := s.saleRepo.GetTaxes(ctx, saleID)
+	if err != nil {
+		return err
+	}
+	restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	// Encabezado
+	w.Align(escpos.AlignCenter)
+	w.Bold(true)
+	w.Size(true, true)
+	w.Line(restaurant.Name)
+	w.Size(false, false)
+	w.Bold(false)
+	if restaurant.Address != "" {
+		w.Line(restaurant.Address)
+	}
+	if restaurant.TaxID != "" {
+		w.Line("RFC/NIT: " + restaurant.TaxID)
+	}
+	if restaurant.Phone != "" {
+		w.Line("Tel: " + restaurant.Phone)
+	}
+	w.Feed(1)
+	w.Align(escpos.AlignLeft)
+	w.Line(fmt.Sprintf("Venta #%s", saleID.String()[:8]))
+	w.Line(fmt.Sprintf("Fecha: %s", sale.CreatedAt.Format("02/01/2006 15:04")))
+
+	if sale.Status == models.SaleStatusCancelled {
+		w.Feed(1)
+		w.Align(escpos.AlignCenter)
+		w.Bold(true)
+		w.Size(false, true)
+		w.Line("VENTA ANULADA")
+		w.Size(false, false)
+		w.Bold(false)
+		w.Align(escpos.AlignLeft)
+		if sale.CancelReason != "" {
+			w.Line("Motivo: " + sale.CancelReason)
+		}
+	}
+	w.Separator()
+
+	// Líneas
+	for _, item := range items {
+		w.Columns(fmt.Sprintf("%d x %s", item.Quantity, s.productName(ctx, restaurantID, item)), "$"+item.Subtotal.String())
+		for _, tp := range item.Toppings {
+			w.Columns(fmt.Sprintf("  + %s x%d", tp.Name, tp.Quantity), "$"+tp.Price.Times(tp.Quantity).String())
+		}
+		for _, c := range item.Components {
+			line := fmt.Sprintf("  > %s: %s x%d", c.ComboSlotName, s.productName(ctx, restaurantID, c), c.Quantity)
+			if c.UnitPrice > 0 {
+				w.Columns(line, "+$"+c.UnitPrice.Times(c.Quantity).String())
+			} else {
+				w.Line(line)
+			}
+			for _, tp := range c.Toppings {
+				w.Columns(fmt.Sprintf("      + %s x%d", tp.Name, tp.Quantity), "$"+tp.Price.Times(tp.Quantity).String())
+			}
+		}
+		if item.DiscountAmount > 0 {
+			w.Columns("  "+discountLabel(item.DiscountType, item.DiscountValue, item.DiscountReason), "-$"+item.DiscountAmount.String())
+		}
+	}
+	w.Separator()
+
+	// Totales
+	if sale.DiscountAmount > 0 {
+		w.Columns(discountLabel(sale.DiscountType, sale.DiscountValue, sale.DiscountReason), "-$"+sale.DiscountAmount.String())
+	}
+	if sale.DiscountTotal > 0 {
+		w.Columns("Ahorro total", "$"+sale.DiscountTotal.String())
+	}
+	w.Columns("Subtotal", "$"+sale.Subtotal.String())
+	for _, t := range taxes {
+		w.Columns(taxLabel(t), "$"+t.Amount.String())
+	}
+	w.Bold(true)
+	w.Size(false, true)
+	w.Columns("TOTAL", "$"+sale.Total.String())
+	w.Size(false, false)
+	w.Bold(false)
+	if sale.TipTotal > 0 {
+		w.Columns("Propina", "$"+sale.TipTotal.String())
+		w.Columns("Total con propina", "$"+(sale.Total+sale.TipTotal).String())
+	}
+	if restaurant.PricesIncludeTax && sale.TaxTotal > 0 {
+		w.Line("Precios con impuestos incluidos")
+	}
+
+	if len(payments) > 0 {
+		w.Feed(1)
+		for _, p := range payments {
+			w.Columns(paymentMethodLabel(p.Method), "$"+p.Amount.String())
+			if p.Tip > 0 {
+				w.Columns("  Propina", "$"+p.Tip.String())
+			}
+			if p.Reference != "" {
+				w.Line("  Ref: " + p.Reference)
+			}
+		}
+	}
+
+	w.Feed(1)
+	w.Align(escpos.AlignCenter)
+	w.Line("¡Gracias por su visita!")
+	w.Align(escpos.AlignLeft)
+	return nil
+}
+
+func (s *ReceiptService) productName(ctx context.Context, restaurantID uuid.UUID, item *models.SaleItem) string {
+	name := "Producto"
+	if product, _ := s.productRepo.GetByID(ctx, restaurantID, item.ProductID); product != nil {
+		name = product.Name
+	}
+	if item.VariantName != "" {
+		name += " (" + item.VariantName + ")"
+	}
+	return name
+}
+
+func (s *ReceiptService) loadKitchenTicket(ctx context.Context, restaurantID, ticketID uuid.UUID) (*models.KitchenTicket, error) {
+	ticket, err := s.kitchenRepo.GetTicket(ctx, restaurantID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.kitchenRepo.ListTicketItems(ctx, []uuid.UUID{ticket.ID})
+	if err != nil {
+		return nil, err
+	}
+	ticket.Items = items[ticket.ID]
+	return ticket, nil
+}
+
+// writeKitchenTicket escribe la comanda en letra grande para leerla de lejos;
+// los componentes de combo van agrupados bajo el nombre del combo
+func writeKitchenTicket(w ticketWriter, ticket *models.KitchenTicket) {
+	w.Align(escpos.AlignCenter)
+	w.Bold(true)
+	w.Size(true, true)
+	w.Line(ticket.StationName)
+	w.Size(false, true)
+	if ticket.TableName != "" {
+		w.Line(ticket.TableName)
+	} else {
+		w.Line(fmt.Sprintf("Venta #%s", ticket.SaleID.String()[:8]))
+	}
+	w.Size(false, false)
+	w.Bold(false)
+	w.Line(fmt.Sprintf("Comanda #%s  %s", ticket.ID.String()[:8], ticket.CreatedAt.Format("15:04")))
+	w.Align(escpos.AlignLeft)
+	w.Separator()
+
+	combo := ""
+	for _, it := range ticket.Items {
+		if it.ComboName != combo {
+			combo = it.ComboName
+			if combo != "" {
+				w.Bold(true)
+				w.Line("[" + combo + "]")
+				w.Bold(false)
+			}
+		}
+		w.Bold(true)
+		w.Size(false, true)
+		w.Line(fmt.Sprintf("%d x %s", it.Quantity, it.Name))
+		w.Size(false, false)
+		w.Bold(false)
+		for _, m := range it.Modifiers {
+			w.Line("   + " + m)
+		}
+		if it.Notes != "" {
+			w.Bold(true)
+			w.Line("   ** " + it.Notes)
+			w.Bold(false)
+		}
+	}
+	w.Separator()
+}
+
+// Medidas del PDF térmico: 1.5 mm por columna da 72 mm útiles en papel de
+// 80 mm (48 columnas) y 48 mm en papel de 58 mm (32 columnas)
+const (
+	thermalColumnWidth = 1.5
+	thermalMargin      = 4.0
+	thermalLineHeight  = 3.6
+	// Courier mide 0.6 em de ancho; el tamaño en puntos que da una columna de thermalColumnWidth
+	thermalFontSize = thermalColumnWidth / 0.6 * 72 / 25.4
+)
+
+type thermalLine struct {
+	text                      string
+	align                     escpos.Align
+	bold                      bool
+	doubleWidth, doubleHeight bool
+}
+
+// thermalPDF dibuja el ticket en una tira del ancho del papel con letra
+// monoespaciada, así el texto se acomoda igual que en la impresora
+type thermalPDF struct {
+	columns int
+	style   thermalLine
+	lines   []thermalLine
+}
+
+func newThermalPDF(columns int) *thermalPDF {
+	return &thermalPDF{columns: columns}
+}
+
+func (t *thermalPDF) Width() int {
+	if t.style.doubleWidth {
+		return t.columns / 2
+	}
+	return t.columns
+}
+
+func (t *thermalPDF) Align(a escpos.Align) { t.style.align = a }
+
+func (t *thermalPDF) Bold(on bool) { t.style.bold = on }
+
+func (t *thermalPDF) Size(doubleWidth, doubleHeight bool) {
+	t.style.doubleWidth = doubleWidth
+	t.style.doubleHeight = doubleHeight
+}
+
+func (t *thermalPDF) Line(text string) {
+	t.add(escpos.Wrap(text, t.Width()))
+}
+
+func (t *thermalPDF) Columns(left, right string) {
+	t.add(escpos.Columns(left, right, t.Width()))
+}
+
+func (t *thermalPDF) Separator() {
+	t.add([]string{strings.Repeat("-", t.Width())})
+}
+
+func (t *thermalPDF) Feed(n int) {
+	for i := 0; i < n; i++ {
+		t.lines = append(t.lines, thermalLine{})
+	}
+}
+
+func (t *thermalPDF) add(texts []string) {
+	for _, text := range texts {
+		line := t.style
+		line.text = text
+		t.lines = append(t.lines, line)
+	}
+}
+
+// Output arma el PDF con el alto justo para las líneas escritas
+func (t *thermalPDF) Output() ([]byte, error) {
+	printable := float64(t.columns) * thermalColumnWidth
+	height := 2 * thermalMargin
+	for _, l := range t.lines {
+		height += l.height()
+	}
+
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr: "mm",
+		Size:    gofpdf.SizeType{Wd: printable + 2*thermalMargin, Ht: height},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	y := thermalMargin
+	for _, l := range t.lines {
+		y += l.height()
+		if l.text == "" {
+			continue
+		}
+		style := ""
+		if l.bold {
+			style = "B"
+		}
+		pdf.SetFont("Courier", style, thermalFontSize)
+
+		scaleX, scaleY := 1.0, 1.0
+		if l.doubleWidth {
+			scaleX = 2
+		}
+		if l.doubleHeight {
+			scaleY = 2
+		}
+		textWidth := float64(utf8.RuneCountInString(l.text)) * thermalColumnWidth * scaleX
+		x := thermalMargin
+		switch l.align {
+		case escpos.AlignCenter:
+			x += (printable - textWidth) / 2
+		case escpos.AlignRight:
+			x += printable - textWidth
+		}
+		// La línea base deja lugar a los descendentes
+		baseline := y - 0.9*scaleY
+		if scaleX != 1 || scaleY != 1 {
+			pdf.TransformBegin()
+			pdf.TransformScale(scaleX*100, scaleY*100, x, baseline)
+			pdf.Text(x, baseline, tr(l.text))
+			pdf.TransformEnd()
+		} else {
+			pdf.Text(x, baseline, tr(l.text))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (l thermalLine) height() float64 {
+	if l.doubleHeight {
+		return 2 * thermalLineHeight
+	}
+	return thermalLineHeight
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+type RefundService struct {
+	txManager       *repository.TxManager
+	refundRepo      *repository.RefundRepository
+	saleRepo        *repository.SaleRepository
+	cashSessionRepo *repository.CashSessionRepository
+}
+
+func NewRefundService(txManager *repository.TxManager, refundRepo *repository.RefundRepository, saleRepo *repository.SaleRepository, cashSessionRepo *repository.CashSessionRepository) *RefundService {
+	return &RefundService{
+		txManager:       txManager,
+		refundRepo:      refundRepo,
+		saleRepo:        saleRepo,
+		cashSessionRepo: cashSessionRepo,
+	}
+}
+
+type RefundItemInput struct {
+	SaleItemID string `json:"sale_item_id" binding:"required"`
+	Quantity   int    `json:"quantity" binding:"required,gt=0"`
+}
+
+type RefundPaymentInput struct {
+	Method    string      `json:"method" binding:"required,oneof=cash card transfer"`
+	Amount    money.Money `json:"amount" binding:"required,gt=0"`
+	Reference string      `json:"reference"`
+}
+
+type CreateRefundInput struct {
+	Items    []RefundItemInput    `json:"items" binding:"required,min=1,dive"`
+	Payments []RefundPaymentInput `json:"payments" binding:"required,min=1,dive"`
+	Reason   string               `json:"reason"`
+}
+
+// Create registra una devolución sobre una venta completada. El monto de cada
+// línea es proporcional al subtotal del sale_item (que ya incluye toppings) y
+// nunca se puede devolver más cantidad de la vendida.
+func (s *RefundService) Create(ctx context.Context, restaurantID, saleID, userID uuid.UUID, input CreateRefundInput) (*models.Refund, error) {
+	refund := &models.Refund{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		SaleID:       saleID,
+		UserID:       userID,
+		Reason:       strings.TrimSpace(input.Reason),
+	}
+
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+		refundRepo := s.refundRepo.WithTx(tx)
+
+		// Bloquear la venta para que dos devoluciones simultáneas no excedan lo vendido
+		sale, err := saleRepo.GetByIDForUpdate(ctx, restaurantID, saleID)
+		if err != nil {
+			return err
+		}
+		if sale.Status != models.SaleStatusCompleted {
+			return NewAppError(errors.ErrConflict, 409, "solo se pueden devolver ventas completadas")
+		}
+
+		saleItems, err := saleRepo.GetItems(ctx, saleID)
+		if err != nil {
+			return err
+		}
+		byID := make(map[uuid.UUID]*models.SaleItem, len(saleItems))
+		for _, it := range saleItems {
+			byID[it.ID] = it
+		}
+
+		refundedQty, refundedAmount, err := refundRepo.RefundedBySaleItem(ctx, saleID)
+		if err != nil {
+			return err
+		}
+
+		// Agrupar por línea por si el cliente repite un sale_item_id
+		requested := make(map[uuid.UUID]int)
+		var order []uuid.UUID
+		for _, it := range input.Items {
+			id, err := uuid.Parse(it.SaleItemID)
+			if err != nil {
+				return NewValidationError("sale_item_id", "UUID inválido")
+			}
+			saleItem, ok := byID[id]
+			if !ok {
+				return NewValidationError("sale_item_id", "la línea no pertenece a la venta")
+			}
+			if saleItem.ParentItemID != nil {
+				return NewValidationError("sale_item_id", "los componentes de un combo se devuelven con la línea del combo")
+			}
+			if _, seen := requested[id]; !seen {
+				order = append(order, id)
+			}
+			requested[id] += it.Quantity
+		}
+
+		for _, id := range order {
+			saleItem := byID[id]
+			qty := requested[id]
+			remaining := saleItem.Quantity - refundedQty[id]
+			if qty > remaining {
+				return NewValidationError("quantity", "no se puede devolver más de lo vendido")
+			}
+
+			// Se devuelve lo cobrado (con impuesto). Si se devuelve lo que queda, se
+			// toma el saldo exacto para no arrastrar redondeos
+			amount := saleItem.Total.MulDiv(int64(qty), int64(saleItem.Quantity))
+			if qty == remaining {
+				amount = saleItem.Total - refundedAmount[id]
+			}
+
+			refund.Items = append(refund.Items, &models.RefundItem{
+				ID:         uuid.New(),
+				RefundID:   refund.ID,
+				SaleItemID: id,
+				Quantity:   qty,
+				Amount:     amount,
+			})
+			refund.Total += amount
+		}
+		if refund.Total <= 0 {
+			return NewValidationError("items", "el monto a devolver debe ser mayor a cero")
+		}
+
+		var paymentsTotal money.Money
+		for _, p := range input.Payments {
+			paymentsTotal += p.Amount
+		}
+		if paymentsTotal != refund.Total {
+			return NewValidationError("payments", "la suma de pagos debe coincidir con el monto a devolver")
+		}
+
+		// Si quien devuelve tiene caja abierta, el reembolso se descuenta de su turno
+		session, err := s.cashSessionRepo.WithTx(tx).GetOpenByUserForShare(ctx, restaurantID, userID)
+		if err != nil && !errors.Is(err, errors.ErrNotFound) {
+			return err
+		}
+		if session != nil {
+			refund.CashSessionID = &session.ID
+		}
+
+		if err := refundRepo.Create(ctx, refund); err != nil {
+			return err
+		}
+		for _, item := range refund.Items {
+			if err := refundRepo.CreateItem(ctx, item); err != nil {
+				return err
+			}
+		}
+		for _, p := range input.Payments {
+			payment := &models.RefundPayment{
+				ID:        uuid.New(),
+				RefundID:  refund.ID,
+				Method:    p.Method,
+				Amount:    p.Amount,
+				Reference: p.Reference,
+			}
+			if err := refundRepo.CreatePayment(ctx, payment); err != nil {
+				return err
+			}
+			refund.Payments = append(refund.Payments, payment)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// GetByID devuelve la devolución de la venta indicada con sus líneas y pagos
+func (s *RefundService) GetByID(ctx context.Context, restaurantID, saleID, refundID uuid.UUID) (*models.Refund, error) {
+	refund, err := s.refundRepo.GetByID(ctx, restaurantID, refundID)
+	if err != nil {
+		return nil, err
+	}
+	if refund.SaleID != saleID {
+		return nil, errors.ErrNotFound
+	}
+	if refund.Items, err = s.refundRepo.GetItems(ctx, refundID); err != nil {
+		return nil, err
+	}
+	if refund.Payments, err = s.refundRepo.GetPayments(ctx, refundID); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (s *RefundService) ListBySale(ctx context.Context, restaurantID, saleID uuid.UUID) ([]*models.Refund, error) {
+	if _, err := s.saleRepo.GetByID(ctx, restaurantID, saleID); err != nil {
+		return nil, err
+	}
+	return s.refundRepo.ListBySale(ctx, restaurantID, saleID)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+type ReportService struct {
+	reportRepo *repository.ReportRepository
+	authRepo   *repository.AuthRepository
+}
+
+func NewReportService(reportRepo *repository.ReportRepository, authRepo *repository.AuthRepository) *ReportService {
+	return &ReportService{
+		reportRepo: reportRepo,
+		authRepo:   authRepo,
+	}
+}
+
+// ReportRangeInput es el rango de fechas de un reporte. Sin fechas se usa el día
+// de hoy en la zona horaria del restaurante; To es inclusivo.
+type ReportRangeInput struct {
+	From  string `form:"from"` // YYYY-MM-DD o RFC3339
+	To    string `form:"to"`   // YYYY-MM-DD o RFC3339
+	Limit int    `form:"limit" binding:"omitempty,gt=0,lte=100"`
+}
+
+// reportRange es el rango ya resuelto [From, To) junto con la zona horaria
+type reportRange struct {
+	From     time.Time
+	To       time.Time
+	Timezone string
+}
+
+const (
+	defaultTopProductsLimit = 10
+	maxReportDays           = 366
+)
+
+func (s *ReportService) resolveRange(ctx context.Context, restaurantID uuid.UUID, input ReportRangeInput) (*reportRange, error) {
+	restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	loc := loadLocation(restaurant.Timezone)
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	rng := &reportRange{From: today, To: today.AddDate(0, 0, 1), Timezone: loc.String()}
+
+	if input.From != "" {
+		from, _, err := parseDateParam(input.From, loc)
+		if err != nil {
+			return nil, NewValidationError("from", "fecha inválida")
+		}
+		rng.From = from
+	}
+	if input.To != "" {
+		to, dateOnly, err := parseDateParam(input.To, loc)
+		if err != nil {
+			return nil, NewValidationError("to", "fecha inválida")
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		rng.To = to
+	} else if input.From != "" {
+		rng.To = today.AddDate(0, 0, 1)
+	}
+
+	if !rng.To.After(rng.From) {
+		return nil, NewValidationError("to", "debe ser posterior a from")
+	}
+	if rng.To.Sub(rng.From) > maxReportDays*24*time.Hour {
+		return nil, NewValidationError("from", "el rango no puede superar un año")
+	}
+	return rng, nil
+}
+
+func (s *ReportService) Summary(ctx context.Context, restaurantID uuid.UUID, input ReportRangeInput) (*models.ReportSummary, error) {
+	rng, err := s.resolveRange(ctx, restaurantID, input)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := s.reportRepo.Summary(ctx, restaurantID, rng.From, rng.To)
+	if err != nil {
+		return nil, err
+	}
+	if summary.SalesCount > 0 {
+		summary.AverageTicket = summary.Revenue.MulDiv(1, int64(summary.SalesCount))
+	}
+	summary.NetRevenue = summary.Revenue - summary.Refunds
+	return summary, nil
+}
+
+func (s *ReportService) RevenueByDay(ctx context.Context, restaurantID uuid.UUID, input ReportRangeInput) ([]*models.RevenueByDay, error) {
+	rng, err := s.resolveRange(ctx, restaurantID, input)
+	if err != nil {
+		return nil, err
+	}
+	return s.reportRepo.RevenueByDay(ctx, restaurantID, rng.From, rng.To, rng.Timezone)
+}
+
+func (s *ReportService) RevenueByHour(ctx context.Context, restaurantID uuid.UUID, input ReportRangeInput) ([]*models.RevenueByHour, error) {
+	rng, err := s.resolveRange(ctx, restaurantID, input)
+	if err != nil {
+		return nil, err
+	}
+	return s.reportRepo.RevenueByHour(ctx, restaurantID, rng.From, rng.To, rng.Timezone)
+}
+
+func (s *ReportService) TopProducts(ctx context.Context, restaurantID uuid.UUID, input ReportRangeInput) ([]*models.ProductSales, error) {
+	rng, err := s.resolveRange(ctx, restaurantID, input)
+	if err != nil {
+		return nil, err
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultTopProductsLimit
+	}
+	return s.reportRepo.TopProducts(ctx, restaurantID, rng.From, rng.To, limit)
+}
+
+func (s *ReportService) RevenueByCategory(ctx context.Context, restaurantID uuid.UUID, input ReportRangeInput) ([]*models.CategorySales, error) {
+	rng, err := s.resolveRange(ctx, restaurantID, input)
+	if err != nil {
+		return nil, err
+	}
+	return s.reportRepo.RevenueByCategory(ctx, restaurantID, rng.From, rng.To)
+}
+
+func (s *ReportService) SalesByCashier(ctx context.Context, restaurantID uuid.UUID, input ReportRangeInput) ([]*models.CashierSales, error) {
+	rng, err := s.resolveRange(ctx, restaurantID, input)
+	if err != nil {
+		return nil, err
+	}
+	cashiers, err := s.reportRepo.SalesByCashier(ctx, restaurantID, rng.From, rng.To)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cashiers {
+		if c.SalesCount > 0 {
+			c.AverageTicket = c.Revenue.MulDiv(1, int64(c.SalesCount))
+		}
+	}
+	return cashiers, nil
+}
+
+func (s *ReportService) TipsByCashier(ctx context.Context, restaurantID uuid.UUID, input ReportRangeInput) ([]*models.CashierTips, error) {
+	rng, err := s.resolveRange(ctx, restaurantID, input)
+	if err != nil {
+		return nil, err
+	}
+	return s.reportRepo.TipsByCashier(ctx, restaurantID, rng.From, rng.To)
+}
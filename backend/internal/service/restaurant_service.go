@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+type RestaurantService struct {
+	authRepo    *repository.AuthRepository
+	taxRateRepo *repository.TaxRateRepository
+}
+
+func NewRestaurantService(authRepo *repository.AuthRepository, taxRateRepo *repository.TaxRateRepository) *RestaurantService {
+	return &RestaurantService{
+		authRepo:    authRepo,
+		taxRateRepo: taxRateRepo,
+	}
+}
+
+// UpdateRestaurantInput: solo se modifican los campos enviados
+type UpdateRestaurantInput struct {
+	Name             *string `json:"name"`
+	Slug             *string `json:"slug"`
+	Phone            *string `json:"phone"`
+	Address          *string `json:"address"`
+	TaxID            *string `json:"tax_id"`
+	LogoURL          *string `json:"logo_url"`
+	Timezone         *string `json:"timezone"`
+	PricesIncludeTax *bool   `json:"prices_include_tax"`
+	DefaultTaxRateID *string `json:"default_tax_rate_id"` // "" quita la tasa predeterminada
+	MaxDiscountBps   *int64  `json:"max_discount_bps" binding:"omitempty,min=0,max=10000"`
+}
+
+func (s *RestaurantService) Get(ctx context.Context, restaurantID uuid.UUID) (*models.Restaurant, error) {
+	return s.authRepo.GetRestaurantByID(ctx, restaurantID)
+}
+
+func (s *RestaurantService) Update(ctx context.Context, restaurantID uuid.UUID, input UpdateRestaurantInput) (*models.Restaurant, error) {
+	rest, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		if *input.Name == "" {
+			return nil, NewValidationError("name", "requerido")
+		}
+		rest.Name = *input.Name
+	}
+	if input.Slug != nil {
+		slug := strings.ToLower(strings.TrimSpace(*input.Slug))
+		if len(slug) < 3 || len(slug) > maxSlugLength || !slugPattern.MatchString(slug) {
+			return nil, NewValidationError("slug", "de 3 a 60 letras minúsculas, dígitos o guiones")
+		}
+		rest.Slug = slug
+	}
+	if input.Phone != nil {
+		rest.Phone = *input.Phone
+	}
+	if input.Address != nil {
+		rest.Address = *input.Address
+	}
+	if input.TaxID != nil {
+		rest.TaxID = *input.TaxID
+	}
+	if input.LogoURL != nil {
+		rest.LogoURL = *input.LogoURL
+	}
+	if input.Timezone != nil {
+		if _, err := time.LoadLocation(*input.Timezone); err != nil || *input.Timezone == "" {
+			return nil, NewValidationError("timezone", "zona horaria inválida")
+		}
+		rest.Timezone = *input.Timezone
+	}
+	if input.PricesIncludeTax != nil {
+		rest.PricesIncludeTax = *input.PricesIncludeTax
+	}
+	if input.MaxDiscountBps != nil {
+		rest.MaxDiscountBps = *input.MaxDiscountBps
+	}
+	if input.DefaultTaxRateID != nil {
+		id, err := resolveTaxRateID(ctx, s.taxRateRepo, restaurantID, *input.DefaultTaxRateID)
+		if err != nil {
+			return nil, err
+		}
+		rest.DefaultTaxRateID = id
+	}
+
+	if err := s.authRepo.UpdateRestaurant(ctx, rest); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ese slug ya está en uso")
+		}
+		return nil, err
+	}
+	return rest, nil
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/permissions"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+// RoleService administra los roles propios del restaurante
+type RoleService struct {
+	txManager *repository.TxManager
+	roleRepo  *repository.RoleRepository
+}
+
+func NewRoleService(txManager *repository.TxManager, roleRepo *repository.RoleRepository) *RoleService {
+	return &RoleService{txManager: txManager, roleRepo: roleRepo}
+}
+
+type RoleInput struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required,min=1"`
+}
+
+type BuiltinRole struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+// RolesResponse son los roles que se pueden asignar a un usuario
+type RolesResponse struct {
+	Builtin []BuiltinRole  `json:"builtin"`
+	Custom  []*models.Role `json:"custom"`
+}
+
+func (s *RoleService) List(ctx context.Context, restaurantID uuid.UUID) (*RolesResponse, error) {
+	custom, err := s.roleRepo.List(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &RolesResponse{Custom: custom}
+	for _, name := range permissions.BuiltinRoles {
+		perms, _ := permissions.Builtin(name)
+		resp.Builtin = append(resp.Builtin, BuiltinRole{Name: name, Permissions: perms})
+	}
+	return resp, nil
+}
+
+// Permissions devuelve todos los permisos que se pueden asignar a un rol
+func (s *RoleService) Permissions() []string {
+	return permissions.All
+}
+
+func (s *RoleService) Get(ctx context.Context, restaurantID, roleID uuid.UUID) (*models.Role, error) {
+	return s.roleRepo.Get(ctx, restaurantID, roleID)
+}
+
+func (s *RoleService) Create(ctx context.Context, restaurantID uuid.UUID, input RoleInput) (*models.Role, error) {
+	role := &models.Role{ID: uuid.New(), RestaurantID: restaurantID}
+	if err := applyRoleInput(role, input); err != nil {
+		return nil, err
+	}
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe un rol con ese nombre")
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// Update cambia el rol; si cambia el nombre, sus usuarios pasan al nombre nuevo
+func (s *RoleService) Update(ctx context.Context, restaurantID, roleID uuid.UUID, input RoleInput) (*models.Role, error) {
+	var role *models.Role
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.roleRepo.WithTx(tx)
+		var err error
+		role, err = repo.Get(ctx, restaurantID, roleID)
+		if err != nil {
+			return err
+		}
+		oldName := role.Name
+		if err := applyRoleInput(role, input); err != nil {
+			return err
+		}
+		if err := repo.Update(ctx, role); err != nil {
+			if errors.Is(err, errors.ErrConflict) {
+				return NewAppError(errors.ErrConflict, 409, "ya existe un rol con ese nombre")
+			}
+			return err
+		}
+		if role.Name != oldName {
+			return repo.RenameUsers(ctx, restaurantID, oldName, role.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// Delete borra un rol que ningún usuario tiene asignado
+func (s *RoleService) Delete(ctx context.Context, restaurantID, roleID uuid.UUID) error {
+	return s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.roleRepo.WithTx(tx)
+		role, err := repo.Get(ctx, restaurantID, roleID)
+		if err != nil {
+			return err
+		}
+		n, err := repo.CountUsers(ctx, restaurantID, role.Name)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return NewAppError(errors.ErrConflict, 409, "hay usuarios con este rol; asígnales otro antes de borrarlo")
+		}
+		return repo.Delete(ctx, restaurantID, roleID)
+	})
+}
+
+func applyRoleInput(role *models.Role, input RoleInput) error {
+	name := strings.ToLower(strings.TrimSpace(input.Name))
+	if name == "" {
+		return NewValidationError("name", "requerido")
+	}
+	if permissions.IsBuiltin(name) {
+		return NewValidationError("name", "es un rol predefinido")
+	}
+	for _, p := range input.Permissions {
+		if !permissions.Valid(p) {
+			return NewValidationError("permissions", "permiso desconocido: "+p)
+		}
+	}
+	role.Name = name
+	role.Description = strings.TrimSpace(input.Description)
+	role.Permissions = permissions.NewSet(input.Permissions...).List()
+	return nil
+}
+
+// rolePermissions devuelve los permisos de un rol predefinido o propio del
+// restaurante. Un rol que ya no existe no tiene permisos.
+func rolePermissions(ctx context.Context, roleRepo *repository.RoleRepository, restaurantID uuid.UUID, name string) ([]string, error) {
+	if perms, ok := permissions.Builtin(name); ok {
+		return perms, nil
+	}
+	role, err := roleRepo.GetByName(ctx, restaurantID, name)
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	return role.Permissions, nil
+}
+
+// validateRole comprueba que name sea un rol predefinido o propio del restaurante
+func validateRole(ctx context.Context, roleRepo *repository.RoleRepository, restaurantID uuid.UUID, name string) error {
+	if permissions.IsBuiltin(name) {
+		return nil
+	}
+	if _, err := roleRepo.GetByName(ctx, restaurantID, name); err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return NewValidationError("role", "rol inexistente")
+		}
+		return err
+	}
+	return nil
+}
@@ -2,148 +2,758 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/events"
 	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+	"github.com/pos-saas/restaurant-pos/internal/permissions"
 	"github.com/pos-saas/restaurant-pos/internal/repository"
 )
 
 type SaleService struct {
-	saleRepo    *repository.SaleRepository
-	productRepo *repository.ProductRepository
-	authRepo    *repository.AuthRepository
+	txManager       *repository.TxManager
+	saleRepo        *repository.SaleRepository
+	productRepo     *repository.ProductRepository
+	variantRepo     *repository.ProductVariantRepository
+	comboRepo       *repository.ComboRepository
+	categoryRepo    *repository.CategoryRepository
+	taxRateRepo     *repository.TaxRateRepository
+	modifierRepo    *repository.ModifierRepository
+	inventoryRepo   *repository.InventoryRepository
+	authRepo        *repository.AuthRepository
+	cashSessionRepo *repository.CashSessionRepository
+	kitchenRepo     *repository.KitchenRepository
+	publisher       events.Publisher
 }
 
-func NewSaleService(saleRepo *repository.SaleRepository, productRepo *repository.ProductRepository, authRepo *repository.AuthRepository) *SaleService {
+func NewSaleService(txManager *repository.TxManager, saleRepo *repository.SaleRepository, productRepo *repository.ProductRepository, variantRepo *repository.ProductVariantRepository, comboRepo *repository.ComboRepository, categoryRepo *repository.CategoryRepository, taxRateRepo *repository.TaxRateRepository, modifierRepo *repository.ModifierRepository, inventoryRepo *repository.InventoryRepository, authRepo *repository.AuthRepository, cashSessionRepo *repository.CashSessionRepository, kitchenRepo *repository.KitchenRepository, publisher events.Publisher) *SaleService {
 	return &SaleService{
-		saleRepo:    saleRepo,
-		productRepo: productRepo,
-		authRepo:    authRepo,
+		txManager:       txManager,
+		saleRepo:        saleRepo,
+		productRepo:     productRepo,
+		variantRepo:     variantRepo,
+		comboRepo:       comboRepo,
+		categoryRepo:    categoryRepo,
+		taxRateRepo:     taxRateRepo,
+		modifierRepo:    modifierRepo,
+		inventoryRepo:   inventoryRepo,
+		authRepo:        authRepo,
+		cashSessionRepo: cashSessionRepo,
+		kitchenRepo:     kitchenRepo,
+		publisher:       publisher,
 	}
 }
 
 type SaleItemInput struct {
-	ProductID string   `json:"product_id" binding:"required"`
-	Quantity  int      `json:"quantity" binding:"required,gt=0"`
-	Notes     string   `json:"notes"`
-	Toppings  []ToppingInput `json:"toppings"`
+	ProductID  string                   `json:"product_id" binding:"required"`
+	VariantID  string                   `json:"variant_id"` // obligatorio si el producto tiene variantes activas
+	Quantity   int                      `json:"quantity" binding:"required,gt=0"`
+	Notes      string                   `json:"notes"`
+	Modifiers  []ModifierSelectionInput `json:"modifiers" binding:"dive"`
+	Components []ComboComponentInput    `json:"components" binding:"dive"` // solo para combos
+	Discount   *DiscountInput           `json:"discount"`
 }
 
-type ToppingInput struct {
-	Name     string  `json:"name" binding:"required"`
-	Price    float64 `json:"price" binding:"gte=0"`
-	Quantity int     `json:"quantity" binding:"gte=0"`
+// ComboComponentInput es el producto elegido para un espacio del combo
+type ComboComponentInput struct {
+	SlotID    string                   `json:"slot_id" binding:"required"`
+	ProductID string                   `json:"product_id" binding:"required"`
+	VariantID string                   `json:"variant_id"`
+	Notes     string                   `json:"notes"`
+	Modifiers []ModifierSelectionInput `json:"modifiers" binding:"dive"`
 }
 
 type SalePaymentInput struct {
-	Method    string  `json:"method" binding:"required,oneof=cash card transfer"`
-	Amount    float64 `json:"amount" binding:"required,gt=0"`
-	Reference string  `json:"reference"`
+	Method    string      `json:"method" binding:"required,oneof=cash card transfer"`
+	Amount    money.Money `json:"amount" binding:"required,gt=0"`
+	Tip       money.Money `json:"tip" binding:"gte=0"` // propina cobrada con este pago, no cuenta en amount
+	Reference string      `json:"reference"`
+}
+
+// TipInput: con type=percent, percent_bps es el porcentaje sobre el total en
+// puntos básicos (1500 = 15%); con type=fixed, amount es el importe de la
+// propina.
+type TipInput struct {
+	Type       string      `json:"type" binding:"required,oneof=percent fixed"`
+	PercentBps int64       `json:"percent_bps" binding:"min=0,max=10000"`
+	Amount     money.Money `json:"amount" binding:"gte=0"`
+}
+
+// amount calcula la propina sobre total. Cada tipo lleva solo su campo, para
+// que un importe no se tome por un porcentaje ni al revés.
+func (t *TipInput) amount(total money.Money) (money.Money, error) {
+	if t.Type == "percent" {
+		if t.Amount != 0 {
+			return 0, NewValidationError("tip.amount", "con type=percent envía percent_bps")
+		}
+		return total.Percent(t.PercentBps), nil
+	}
+	if t.PercentBps != 0 {
+		return 0, NewValidationError("tip.percent_bps", "con type=fixed envía amount")
+	}
+	return t.Amount, nil
 }
 
 type CreateSaleInput struct {
 	Items    []SaleItemInput    `json:"items" binding:"required,min=1,dive"`
 	Payments []SalePaymentInput `json:"payments" binding:"required,min=1,dive"`
+	Discount *DiscountInput     `json:"discount"` // descuento sobre todo el ticket
+	Tip      *TipInput          `json:"tip"`
+}
+
+type ListSalesInput struct {
+	From          string       `form:"from"` // RFC3339 o YYYY-MM-DD
+	To            string       `form:"to"`   // RFC3339 o YYYY-MM-DD (día inclusivo)
+	Status        string       `form:"status" binding:"omitempty,oneof=pending completed cancelled"`
+	UserID        string       `form:"user_id"`
+	PaymentMethod string       `form:"payment_method" binding:"omitempty,oneof=cash card transfer"`
+	MinTotal      *money.Money `form:"min_total" binding:"omitempty,gte=0"`
+	MaxTotal      *money.Money `form:"max_total" binding:"omitempty,gte=0"`
+	Cursor        string       `form:"cursor"`
+	Limit         int          `form:"limit" binding:"omitempty,gt=0"`
 }
 
-func (s *SaleService) Create(ctx context.Context, restaurantID, userID uuid.UUID, input CreateSaleInput) (*models.Sale, error) {
-	var total float64
+type SaleListResult struct {
+	Sales      []*models.Sale `json:"sales"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+const (
+	defaultSaleListLimit = 50
+	maxSaleListLimit     = 200
+)
+
+type CancelSaleInput struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// Create registra la venta. Los descuentos de quien no es admin quedan limitados
+// por el tope del restaurante.
+func (s *SaleService) Create(ctx context.Context, restaurantID, userID uuid.UUID, perms permissions.Set, input CreateSaleInput) (*models.Sale, error) {
 	saleID := uuid.New()
 
-	// Validar productos y calcular total
-	for _, it := range input.Items {
-		productID, err := uuid.Parse(it.ProductID)
+	restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	taxes, err := newTaxResolver(ctx, restaurant, s.taxRateRepo, s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	sale := &models.Sale{
+		ID:           saleID,
+		RestaurantID: restaurantID,
+		UserID:       userID,
+		Status:       models.SaleStatusCompleted,
+	}
+	items := make([]*models.SaleItem, len(input.Items))
+	rates := make([]*models.TaxRate, len(input.Items))
+
+	// Validar productos y calcular importe de lista y descuento de cada línea
+	for i, it := range input.Items {
+		item, product, err := s.buildLine(ctx, restaurantID, saleID, it)
 		if err != nil {
-			return nil, NewValidationError("product_id", "UUID inválido")
+			return nil, err
 		}
+		// Los toppings tributan con la tasa del producto
+		rates[i] = taxes.rateFor(product)
+		items[i] = item
+	}
 
-		product, err := s.productRepo.GetByID(ctx, restaurantID, productID)
+	breakdown, listTotal, err := priceSale(sale, items, rates, taxes.inclusive, input.Discount)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDiscountLimit(perms, restaurant.MaxDiscountBps, sale.DiscountTotal, listTotal); err != nil {
+		return nil, err
+	}
+
+	payments, err := buildPayments(sale, input.Payments, input.Tip)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lo que la venta descuenta del inventario según las recetas
+	consumption, err := saleConsumption(ctx, s.inventoryRepo, restaurantID, items)
+	if err != nil {
+		return nil, err
+	}
+	for id, q := range consumption {
+		consumption[id] = -q
+	}
+
+	tickets, err := kitchenTickets(ctx, s.kitchenRepo, sale, items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cabecera, items, toppings, impuestos, pagos, comandas y stock se guardan en una sola transacción
+	err = s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+
+		// Toda venta queda asociada al turno abierto del cajero
+		session, err := s.openSession(ctx, tx, restaurantID, userID)
 		if err != nil {
-			return nil, NewValidationError("product_id", "producto no encontrado")
+			return err
+		}
+		sale.CashSessionID = &session.ID
+
+		if err := saleRepo.Create(ctx, sale); err != nil {
+			return err
 		}
-		if !product.Active {
-			return nil, NewValidationError("product_id", "producto inactivo")
+		if err := insertLines(ctx, saleRepo, items); err != nil {
+			return err
+		}
+		if err := insertKitchenTickets(ctx, s.kitchenRepo.WithTx(tx), tickets); err != nil {
+			return err
+		}
+
+		for _, tax := range breakdown.list() {
+			tax.ID = uuid.New()
+			tax.SaleID = saleID
+			if err := saleRepo.CreateTax(ctx, tax); err != nil {
+				return err
+			}
 		}
 
-		itemTotal := product.Price * float64(it.Quantity)
-		for _, tp := range it.Toppings {
-			itemTotal += tp.Price * float64(tp.Quantity)
+		for _, payment := range payments {
+			payment.CashSessionID = &session.ID
+			if err := saleRepo.CreatePayment(ctx, payment); err != nil {
+				return err
+			}
 		}
-		total += itemTotal
+
+		return applyStockMovements(ctx, s.inventoryRepo.WithTx(tx), models.StockMovement{
+			RestaurantID: restaurantID,
+			Type:         models.StockMovementSale,
+			SaleID:       &saleID,
+			UserID:       &userID,
+		}, consumption)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Validar que la suma de pagos coincida con el total
-	var paymentsTotal float64
-	for _, p := range input.Payments {
-		paymentsTotal += p.Amount
+	s.publisher.Publish(events.New(events.SaleCreated, restaurantID, sale))
+	publishKitchenTickets(ctx, s.kitchenRepo, s.publisher, restaurantID, tickets)
+	return sale, nil
+}
+
+// openSession devuelve el turno abierto del cajero, al que queda asociada la venta
+func (s *SaleService) openSession(ctx context.Context, tx pgx.Tx, restaurantID, userID uuid.UUID) (*models.CashSession, error) {
+	session, err := s.cashSessionRepo.WithTx(tx).GetOpenByUserForShare(ctx, restaurantID, userID)
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil, NewAppError(errors.ErrConflict, 409, "abre un turno de caja antes de registrar ventas")
+		}
+		return nil, err
 	}
-	if paymentsTotal < total-0.01 || paymentsTotal > total+0.01 { // tolerancia por decimales
-		return nil, NewValidationError("payments", "la suma de pagos debe coincidir con el total")
+	return session, nil
+}
+
+// buildLine valida producto, variante, modificadores, componentes y descuento
+// de una línea y calcula su importe de lista. Devuelve también el producto
+// para resolver su impuesto.
+func (s *SaleService) buildLine(ctx context.Context, restaurantID, saleID uuid.UUID, it SaleItemInput) (*models.SaleItem, *models.Product, error) {
+	productID, err := uuid.Parse(it.ProductID)
+	if err != nil {
+		return nil, nil, NewValidationError("product_id", "UUID inválido")
 	}
 
-	sale := &models.Sale{
-		ID:           saleID,
-		RestaurantID: restaurantID,
-		UserID:       userID,
-		Total:        total,
-		Status:       "completed",
+	product, err := s.productRepo.GetByID(ctx, restaurantID, productID)
+	if err != nil {
+		return nil, nil, NewValidationError("product_id", "producto no encontrado")
 	}
-	if err := s.saleRepo.Create(ctx, sale); err != nil {
-		return nil, err
+	if !product.Active {
+		return nil, nil, NewValidationError("product_id", "producto inactivo")
 	}
 
-	for _, it := range input.Items {
-		productID, _ := uuid.Parse(it.ProductID)
-		product, _ := s.productRepo.GetByID(ctx, restaurantID, productID)
+	variant, err := s.resolveVariant(ctx, restaurantID, product.ID, it.VariantID)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		itemTotal := product.Price * float64(it.Quantity)
-		for _, tp := range it.Toppings {
-			itemTotal += tp.Price * float64(tp.Quantity)
+	item := &models.SaleItem{
+		ID:        uuid.New(),
+		SaleID:    saleID,
+		ProductID: product.ID,
+		Quantity:  it.Quantity,
+		UnitPrice: product.Price,
+		Notes:     it.Notes,
+	}
+	if variant != nil {
+		item.VariantID = &variant.ID
+		item.VariantName = variant.Name
+		item.UnitPrice = variant.Price
+	}
+	item.Subtotal = item.UnitPrice.Times(it.Quantity)
+
+	// Opciones y precios salen del catálogo, no del cliente
+	groups, err := s.modifierRepo.ListByProduct(ctx, restaurantID, product.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	item.Toppings, err = resolveModifiers(groups, it.Modifiers, it.Quantity, item.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, tp := range item.Toppings {
+		item.Subtotal += tp.Price.Times(tp.Quantity)
+	}
+
+	// Recargos y extras de los componentes se cobran en la línea del combo
+	if product.Type == models.ProductTypeCombo {
+		item.Components, err = s.resolveComboComponents(ctx, restaurantID, item, it.Components)
+		if err != nil {
+			return nil, nil, err
 		}
+		for _, component := range item.Components {
+			item.Subtotal += component.Subtotal
+		}
+	} else if len(it.Components) > 0 {
+		return nil, nil, NewValidationError("components", "el producto no es un combo")
+	}
+
+	if it.Discount != nil {
+		amount, err := it.Discount.amount("items.discount", item.Subtotal)
+		if err != nil {
+			return nil, nil, err
+		}
+		item.DiscountType = it.Discount.Type
+		item.DiscountValue = it.Discount.Value
+		item.DiscountAmount = amount
+		item.DiscountReason = it.Discount.Reason
+	}
+	return item, product, nil
+}
 
-		item := &models.SaleItem{
+// priceSale calcula descuento del ticket, impuestos y totales de la venta a
+// partir de sus líneas principales ya armadas; rates[i] es la tasa de items[i].
+// Devuelve el desglose de impuestos y el importe de lista para el tope de descuento.
+func priceSale(sale *models.Sale, items []*models.SaleItem, rates []*models.TaxRate, inclusive bool, discount *DiscountInput) (*taxBreakdown, money.Money, error) {
+	nets := make([]money.Money, len(items))
+	var listTotal, net money.Money
+	for i, item := range items {
+		listTotal += item.Subtotal
+		nets[i] = item.Subtotal - item.DiscountAmount
+		net += nets[i]
+	}
+
+	// El descuento del ticket se calcula sobre lo que queda tras los descuentos de
+	// línea y se prorratea para que cada tasa tribute sobre su importe real
+	sale.DiscountType, sale.DiscountValue, sale.DiscountAmount, sale.DiscountReason = "", 0, 0, ""
+	if discount != nil {
+		amount, err := discount.amount("discount", net)
+		if err != nil {
+			return nil, 0, err
+		}
+		sale.DiscountType = discount.Type
+		sale.DiscountValue = discount.Value
+		sale.DiscountAmount = amount
+		sale.DiscountReason = discount.Reason
+	}
+	shares := allocateDiscount(sale.DiscountAmount, nets)
+
+	sale.Subtotal, sale.TaxTotal, sale.Total, sale.DiscountTotal = 0, 0, 0, 0
+	breakdown := newTaxBreakdown()
+	for i, item := range items {
+		item.TicketDiscount = shares[i]
+		sale.DiscountTotal += item.DiscountAmount + item.TicketDiscount
+
+		var base money.Money
+		item.TaxRateID, item.TaxRateBps = nil, 0
+		if rate := rates[i]; rate != nil {
+			item.TaxRateID = &rate.ID
+			item.TaxRateBps = rate.RateBps
+		}
+		base, item.TaxAmount, item.Total = lineTax(nets[i]-item.TicketDiscount, item.TaxRateBps, inclusive)
+		breakdown.add(rates[i], base, item.TaxAmount)
+
+		sale.Subtotal += base
+		sale.TaxTotal += item.TaxAmount
+		sale.Total += item.Total
+	}
+	return breakdown, listTotal, nil
+}
+
+// buildPayments valida que la suma de pagos coincida con el total (las propinas
+// van aparte) y asigna la propina de la venta a los pagos
+func buildPayments(sale *models.Sale, inputs []SalePaymentInput, tip *TipInput) ([]*models.SalePayment, error) {
+	var paymentsTotal, paymentTips money.Money
+	payments := make([]*models.SalePayment, len(inputs))
+	for i, p := range inputs {
+		paymentsTotal += p.Amount
+		paymentTips += p.Tip
+		payments[i] = &models.SalePayment{
 			ID:        uuid.New(),
-			SaleID:    saleID,
-			ProductID: productID,
-			Quantity:  it.Quantity,
-			UnitPrice: product.Price,
-			Subtotal:  itemTotal,
-			Notes:     it.Notes,
-		}
-		if err := s.saleRepo.CreateItem(ctx, item); err != nil {
+			SaleID:    sale.ID,
+			Method:    p.Method,
+			Amount:    p.Amount,
+			Tip:       p.Tip,
+			Reference: p.Reference,
+		}
+	}
+	if paymentsTotal != sale.Total {
+		return nil, NewValidationError("payments", "la suma de pagos debe coincidir con el total")
+	}
+
+	// La propina de la venta debe quedar asignada a los pagos. Con un solo pago
+	// se le asigna directamente.
+	sale.TipTotal = paymentTips
+	if tip != nil {
+		amount, err := tip.amount(sale.Total)
+		if err != nil {
 			return nil, err
 		}
+		switch {
+		case paymentTips == 0 && len(payments) == 1:
+			payments[0].Tip = amount
+		case paymentTips != amount:
+			return nil, NewValidationError("payments", "la suma de propinas de los pagos debe coincidir con la propina de la venta")
+		}
+		sale.TipTotal = amount
+	}
+	return payments, nil
+}
+
+// insertLines guarda las líneas con sus componentes y toppings. Usar con un
+// repositorio dentro de la transacción.
+func insertLines(ctx context.Context, saleRepo *repository.SaleRepository, items []*models.SaleItem) error {
+	for _, item := range items {
+		// Los componentes van después de su combo por la referencia a la línea padre
+		lines := append([]*models.SaleItem{item}, item.Components...)
+		for _, line := range lines {
+			if err := saleRepo.CreateItem(ctx, line); err != nil {
+				return err
+			}
+			for _, topping := range line.Toppings {
+				if err := saleRepo.CreateItemTopping(ctx, topping); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
 
-		for _, tp := range it.Toppings {
-			if tp.Quantity <= 0 {
-				continue
+// resolveVariant valida la variante elegida. Un producto con variantes activas
+// se vende siempre por variante; uno sin variantes no acepta variant_id.
+func (s *SaleService) resolveVariant(ctx context.Context, restaurantID, productID uuid.UUID, raw string) (*models.ProductVariant, error) {
+	byProduct, err := s.variantRepo.ListByProducts(ctx, restaurantID, []uuid.UUID{productID})
+	if err != nil {
+		return nil, err
+	}
+	variants := byProduct[productID]
+
+	if raw == "" {
+		for _, v := range variants {
+			if v.Active {
+				return nil, NewValidationError("variant_id", "elige una variante del producto")
 			}
-			topping := &models.Topping{
-				ID:         uuid.New(),
-				SaleItemID: item.ID,
-				Name:       tp.Name,
-				Price:      tp.Price,
-				Quantity:   tp.Quantity,
+		}
+		return nil, nil
+	}
+
+	variantID, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, NewValidationError("variant_id", "UUID inválido")
+	}
+	for _, v := range variants {
+		if v.ID == variantID {
+			if !v.Active {
+				return nil, NewValidationError("variant_id", "variante inactiva")
 			}
-			if err := s.saleRepo.CreateItemTopping(ctx, topping); err != nil {
-				return nil, err
+			return v, nil
+		}
+	}
+	return nil, NewValidationError("variant_id", "variante no encontrada para el producto")
+}
+
+// resolveComboComponents valida la elección de cada espacio del combo y arma
+// las líneas hijas. Cada componente lleva combo × cantidad del espacio unidades;
+// su recargo y sus extras se suman a la línea del combo, que es la que cobra.
+func (s *SaleService) resolveComboComponents(ctx context.Context, restaurantID uuid.UUID, combo *models.SaleItem, selections []ComboComponentInput) ([]*models.SaleItem, error) {
+	bySlot, err := s.comboRepo.ListByCombos(ctx, restaurantID, []uuid.UUID{combo.ProductID})
+	if err != nil {
+		return nil, err
+	}
+	slots := bySlot[combo.ProductID]
+	if len(slots) == 0 {
+		return nil, NewValidationError("components", "el combo no tiene espacios configurados")
+	}
+
+	chosen := make(map[uuid.UUID]ComboComponentInput, len(selections))
+	for _, sel := range selections {
+		slotID, err := uuid.Parse(sel.SlotID)
+		if err != nil {
+			return nil, NewValidationError("components.slot_id", "UUID inválido")
+		}
+		if _, dup := chosen[slotID]; dup {
+			return nil, NewValidationError("components.slot_id", "elige un solo producto por espacio")
+		}
+		chosen[slotID] = sel
+	}
+
+	var components []*models.SaleItem
+	for _, slot := range slots {
+		sel, ok := chosen[slot.ID]
+		if !ok {
+			if slot.Required {
+				return nil, NewValidationError("components", fmt.Sprintf("elige una opción en %s", slot.Name))
+			}
+			continue
+		}
+		delete(chosen, slot.ID)
+
+		productID, err := uuid.Parse(sel.ProductID)
+		if err != nil {
+			return nil, NewValidationError("components.product_id", "UUID inválido")
+		}
+		var option *models.ComboSlotOption
+		for _, o := range slot.Options {
+			if o.ProductID == productID {
+				option = o
+				break
 			}
 		}
+		if option == nil {
+			return nil, NewValidationError("components.product_id", fmt.Sprintf("producto no permitido en %s", slot.Name))
+		}
+		product, err := s.productRepo.GetByID(ctx, restaurantID, productID)
+		if err != nil {
+			return nil, err
+		}
+		if !product.Active || product.Type == models.ProductTypeCombo {
+			return nil, NewValidationError("components.product_id", fmt.Sprintf("%s no está disponible", product.Name))
+		}
+		variant, err := s.resolveVariant(ctx, restaurantID, product.ID, sel.VariantID)
+		if err != nil {
+			return nil, err
+		}
+
+		qty := combo.Quantity * slot.Quantity
+		component := &models.SaleItem{
+			ID:            uuid.New(),
+			SaleID:        combo.SaleID,
+			ProductID:     product.ID,
+			Quantity:      qty,
+			UnitPrice:     option.Upcharge,
+			Subtotal:      option.Upcharge.Times(qty),
+			Notes:         sel.Notes,
+			ParentItemID:  &combo.ID,
+			ComboSlotName: slot.Name,
+		}
+		if variant != nil {
+			component.VariantID = &variant.ID
+			component.VariantName = variant.Name
+		}
+
+		groups, err := s.modifierRepo.ListByProduct(ctx, restaurantID, product.ID)
+		if err != nil {
+			return nil, err
+		}
+		component.Toppings, err = resolveModifiers(groups, sel.Modifiers, qty, component.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, tp := range component.Toppings {
+			component.Subtotal += tp.Price.Times(tp.Quantity)
+		}
+		components = append(components, component)
 	}
+	if len(chosen) > 0 {
+		return nil, NewValidationError("components.slot_id", "el espacio no pertenece al combo")
+	}
+	return components, nil
+}
 
-	for _, p := range input.Payments {
-		payment := &models.SalePayment{
-			ID:        uuid.New(),
-			SaleID:    saleID,
-			Method:    p.Method,
-			Amount:    p.Amount,
-			Reference: p.Reference,
+// nestComponents cuelga los componentes de combo de su línea y devuelve solo
+// las líneas principales
+func nestComponents(items []*models.SaleItem) []*models.SaleItem {
+	byID := make(map[uuid.UUID]*models.SaleItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	top := make([]*models.SaleItem, 0, len(items))
+	for _, item := range items {
+		if item.ParentItemID != nil {
+			if parent, ok := byID[*item.ParentItemID]; ok {
+				parent.Components = append(parent.Components, item)
+				continue
+			}
 		}
-		if err := s.saleRepo.CreatePayment(ctx, payment); err != nil {
+		top = append(top, item)
+	}
+	return top
+}
+
+// List devuelve las ventas más recientes primero, paginadas por cursor sobre
+// (created_at, id). NextCursor viene vacío cuando no hay más páginas.
+func (s *SaleService) List(ctx context.Context, restaurantID uuid.UUID, input ListSalesInput) (*SaleListResult, error) {
+	filter := repository.SaleListFilter{
+		Status:        input.Status,
+		PaymentMethod: input.PaymentMethod,
+		MinTotal:      input.MinTotal,
+		MaxTotal:      input.MaxTotal,
+		Limit:         input.Limit,
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSaleListLimit
+	}
+	if filter.Limit > maxSaleListLimit {
+		filter.Limit = maxSaleListLimit
+	}
+
+	// Las fechas sin hora se interpretan en la zona horaria del restaurante
+	loc := time.UTC
+	if input.From != "" || input.To != "" {
+		var err error
+		if loc, err = s.restaurantLocation(ctx, restaurantID); err != nil {
 			return nil, err
 		}
 	}
+	if input.From != "" {
+		from, _, err := parseDateParam(input.From, loc)
+		if err != nil {
+			return nil, NewValidationError("from", "fecha inválida")
+		}
+		filter.From = &from
+	}
+	if input.To != "" {
+		to, dateOnly, err := parseDateParam(input.To, loc)
+		if err != nil {
+			return nil, NewValidationError("to", "fecha inválida")
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = &to
+	}
+	if input.UserID != "" {
+		id, err := uuid.Parse(input.UserID)
+		if err != nil {
+			return nil, NewValidationError("user_id", "UUID inválido")
+		}
+		filter.UserID = &id
+	}
+	if input.Cursor != "" {
+		after, afterID, err := decodeSaleCursor(input.Cursor)
+		if err != nil {
+			return nil, NewValidationError("cursor", "cursor inválido")
+		}
+		filter.After = &after
+		filter.AfterID = &afterID
+	}
+
+	// Se pide un registro extra para saber si hay página siguiente
+	limit := filter.Limit
+	filter.Limit++
+	sales, err := s.saleRepo.List(ctx, restaurantID, filter)
+	if err != nil {
+		return nil, err
+	}
 
+	result := &SaleListResult{Sales: sales}
+	if len(sales) > limit {
+		result.Sales = sales[:limit]
+		last := result.Sales[limit-1]
+		result.NextCursor = encodeSaleCursor(last.CreatedAt, last.ID)
+	}
+	if result.Sales == nil {
+		result.Sales = []*models.Sale{}
+	}
+	return result, nil
+}
+
+func (s *SaleService) restaurantLocation(ctx context.Context, restaurantID uuid.UUID) (*time.Location, error) {
+	restaurant, err := s.authRepo.GetRestaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	return loadLocation(restaurant.Timezone), nil
+}
+
+// Cancel anula una venta. El motivo es obligatorio y queda registrado junto
+// con el usuario que anuló y la fecha. Lo descontado del inventario vuelve al
+// stock y sus comandas aún no servidas se anulan. Solo se anulan ventas
+// cobradas, sin devoluciones y cuyos turnos de caja sigan abiertos.
+func (s *SaleService) Cancel(ctx context.Context, restaurantID, saleID, userID uuid.UUID, input CancelSaleInput) (*models.Sale, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, NewValidationError("reason", "el motivo de anulación es obligatorio")
+	}
+
+	var tickets []uuid.UUID
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		saleRepo := s.saleRepo.WithTx(tx)
+		// Bloquea la venta para que no se registre una devolución a la vez
+		sale, err := saleRepo.GetByIDForUpdate(ctx, restaurantID, saleID)
+		if err != nil {
+			return err
+		}
+		switch sale.Status {
+		case models.SaleStatusCancelled:
+			return NewAppError(errors.ErrConflict, 409, "la venta ya está anulada")
+		case models.SaleStatusPending:
+			// Una cuenta abierta puede tener subcuentas cobradas: se anula por /orders/:id/void
+			return NewAppError(errors.ErrConflict, 409, "la cuenta sigue abierta; anúlala desde la cuenta")
+		}
+		// El turno descontaría la devolución de una venta que ya no suma
+		hasRefunds, err := saleRepo.HasRefunds(ctx, saleID)
+		if err != nil {
+			return err
+		}
+		if hasRefunds {
+			return NewAppError(errors.ErrConflict, 409, "la venta tiene devoluciones registradas; no se puede anular")
+		}
+		// El cierre de un turno no cambia después: su reporte Z ya se emitió
+		closed, err := s.cashSessionRepo.WithTx(tx).HasClosedForSale(ctx, saleID)
+		if err != nil {
+			return err
+		}
+		if closed {
+			return NewAppError(errors.ErrConflict, 409, "el turno de caja de la venta ya está cerrado; registra una devolución")
+		}
+		if err := saleRepo.Cancel(ctx, restaurantID, saleID, userID, reason); err != nil {
+			return err
+		}
+		tickets, err = s.kitchenRepo.WithTx(tx).VoidSaleTickets(ctx, saleID)
+		if err != nil {
+			return err
+		}
+
+		inventoryRepo := s.inventoryRepo.WithTx(tx)
+		net, err := inventoryRepo.NetBySale(ctx, saleID)
+		if err != nil {
+			return err
+		}
+		for id, q := range net {
+			net[id] = -q
+		}
+		return applyStockMovements(ctx, inventoryRepo, models.StockMovement{
+			RestaurantID: restaurantID,
+			Type:         models.StockMovementSaleCancel,
+			SaleID:       &saleID,
+			UserID:       &userID,
+		}, net)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sale, err := s.saleRepo.GetByID(ctx, restaurantID, saleID)
+	if err != nil {
+		return nil, err
+	}
+	s.publisher.Publish(events.New(events.SaleCancelled, restaurantID, sale))
+	publishKitchenChanges(ctx, s.kitchenRepo, s.publisher, restaurantID, tickets)
 	return sale, nil
 }
 
@@ -162,6 +772,7 @@ func (s *SaleService) GetByID(ctx context.Context, restaurantID, saleID uuid.UUI
 		toppings, _ := s.saleRepo.GetItemToppings(ctx, item.ID)
 		item.Toppings = toppings
 	}
+	items = nestComponents(items)
 
 	payments, err := s.saleRepo.GetPayments(ctx, saleID)
 	if err != nil {
@@ -175,3 +786,33 @@ func (s *SaleService) GetByID(ctx context.Context, restaurantID, saleID uuid.UUI
 
 	return sale, items, payments, restaurant, nil
 }
+
+// GetTaxes devuelve el desglose de impuestos de la venta por tasa
+func (s *SaleService) GetTaxes(ctx context.Context, saleID uuid.UUID) ([]*models.SaleTax, error) {
+	return s.saleRepo.GetTaxes(ctx, saleID)
+}
+
+func encodeSaleCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeSaleCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	ts, idStr, found := strings.Cut(string(raw), "|")
+	if !found {
+		return time.Time{}, uuid.Nil, fmt.Errorf("cursor sin separador")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	return createdAt, id, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/pos-saas/restaurant-pos/internal/money"
+)
+
+func TestTipAmount(t *testing.T) {
+	tests := []struct {
+		tip     TipInput
+		total   money.Money
+		want    money.Money
+		wantErr bool
+	}{
+		{TipInput{Type: "percent", PercentBps: 1500}, 10000, 1500, false},
+		{TipInput{Type: "percent", PercentBps: 1250}, 999, 125, false},
+		{TipInput{Type: "percent"}, 10000, 0, false},
+		{TipInput{Type: "fixed", Amount: 1500}, 10000, 1500, false},
+		// Cada tipo solo acepta su campo
+		{TipInput{Type: "percent", Amount: 1500}, 10000, 0, true},
+		{TipInput{Type: "fixed", PercentBps: 1500}, 10000, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := tt.tip.amount(tt.total)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%+v.amount(%d): err = %v", tt.tip, tt.total, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%+v.amount(%d) = %d, want %d", tt.tip, tt.total, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+type TableService struct {
+	tableRepo *repository.TableRepository
+}
+
+func NewTableService(tableRepo *repository.TableRepository) *TableService {
+	return &TableService{tableRepo: tableRepo}
+}
+
+type TableAreaInput struct {
+	Name      string `json:"name" binding:"required"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type TableInput struct {
+	Name      string `json:"name" binding:"required"`
+	AreaID    string `json:"area_id"`
+	Seats     int    `json:"seats" binding:"omitempty,gt=0"` // por defecto 4
+	Active    *bool  `json:"active"`                         // por defecto true
+	SortOrder int    `json:"sort_order"`
+}
+
+type ListTablesInput struct {
+	AreaID string `form:"area_id"`
+}
+
+const defaultTableSeats = 4
+
+func (s *TableService) ListAreas(ctx context.Context, restaurantID uuid.UUID) ([]*models.TableArea, error) {
+	return s.tableRepo.ListAreas(ctx, restaurantID)
+}
+
+func (s *TableService) CreateArea(ctx context.Context, restaurantID uuid.UUID, input TableAreaInput) (*models.TableArea, error) {
+	area := &models.TableArea{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		Name:         strings.TrimSpace(input.Name),
+		SortOrder:    input.SortOrder,
+	}
+	if err := s.tableRepo.CreateArea(ctx, area); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe un área con ese nombre")
+		}
+		return nil, err
+	}
+	return area, nil
+}
+
+func (s *TableService) UpdateArea(ctx context.Context, restaurantID, areaID uuid.UUID, input TableAreaInput) (*models.TableArea, error) {
+	area, err := s.tableRepo.GetArea(ctx, restaurantID, areaID)
+	if err != nil {
+		return nil, err
+	}
+	area.Name = strings.TrimSpace(input.Name)
+	area.SortOrder = input.SortOrder
+	if err := s.tableRepo.UpdateArea(ctx, area); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe un área con ese nombre")
+		}
+		return nil, err
+	}
+	return area, nil
+}
+
+// DeleteArea borra el área; sus mesas quedan sin área
+func (s *TableService) DeleteArea(ctx context.Context, restaurantID, areaID uuid.UUID) error {
+	return s.tableRepo.DeleteArea(ctx, restaurantID, areaID)
+}
+
+// ListTables devuelve las mesas con el resumen de sus cuentas abiertas
+func (s *TableService) ListTables(ctx context.Context, restaurantID uuid.UUID, input ListTablesInput, activeOnly bool) ([]*models.Table, error) {
+	var areaID *uuid.UUID
+	if input.AreaID != "" {
+		id, err := uuid.Parse(input.AreaID)
+		if err != nil {
+			return nil, NewValidationError("area_id", "UUID inválido")
+		}
+		areaID = &id
+	}
+	return s.tableRepo.ListTables(ctx, restaurantID, areaID, activeOnly)
+}
+
+func (s *TableService) GetTable(ctx context.Context, restaurantID, tableID uuid.UUID) (*models.Table, error) {
+	return s.tableRepo.GetTable(ctx, restaurantID, tableID)
+}
+
+func (s *TableService) CreateTable(ctx context.Context, restaurantID uuid.UUID, input TableInput) (*models.Table, error) {
+	table := &models.Table{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		Active:       input.Active == nil || *input.Active,
+	}
+	if err := s.applyTableInput(ctx, table, input); err != nil {
+		return nil, err
+	}
+	if err := s.tableRepo.CreateTable(ctx, table); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe una mesa con ese nombre")
+		}
+		return nil, err
+	}
+	return table, nil
+}
+
+func (s *TableService) UpdateTable(ctx context.Context, restaurantID, tableID uuid.UUID, input TableInput) (*models.Table, error) {
+	table, err := s.tableRepo.GetTable(ctx, restaurantID, tableID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyTableInput(ctx, table, input); err != nil {
+		return nil, err
+	}
+	if input.Active != nil {
+		table.Active = *input.Active
+	}
+	if err := s.tableRepo.UpdateTable(ctx, table); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe una mesa con ese nombre")
+		}
+		return nil, err
+	}
+	return table, nil
+}
+
+func (s *TableService) applyTableInput(ctx context.Context, table *models.Table, input TableInput) error {
+	table.Name = strings.TrimSpace(input.Name)
+	table.SortOrder = input.SortOrder
+	table.Seats = input.Seats
+	if table.Seats == 0 {
+		table.Seats = defaultTableSeats
+	}
+	table.AreaID = nil
+	if input.AreaID != "" {
+		id, err := uuid.Parse(input.AreaID)
+		if err != nil {
+			return NewValidationError("area_id", "UUID inválido")
+		}
+		if _, err := s.tableRepo.GetArea(ctx, table.RestaurantID, id); err != nil {
+			if errors.Is(err, errors.ErrNotFound) {
+				return NewValidationError("area_id", "área no encontrada")
+			}
+			return err
+		}
+		table.AreaID = &id
+	}
+	return nil
+}
+
+func (s *TableService) DeleteTable(ctx context.Context, restaurantID, tableID uuid.UUID) error {
+	err := s.tableRepo.DeleteTable(ctx, restaurantID, tableID)
+	if errors.Is(err, errors.ErrConflict) {
+		return NewAppError(errors.ErrConflict, 409, "la mesa tiene ventas registradas; desactívala en su lugar")
+	}
+	return err
+}
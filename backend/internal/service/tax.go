@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/money"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+// lineTax calcula base, impuesto y total de un importe. Con precios que ya
+// incluyen impuesto el total no cambia y el impuesto se extrae de él; en caso
+// contrario se agrega encima. El impuesto se redondea por línea.
+func lineTax(amount money.Money, rateBps int64, inclusive bool) (base, tax, total money.Money) {
+	if rateBps == 0 {
+		return amount, 0, amount
+	}
+	if inclusive {
+		tax = amount.MulDiv(rateBps, 10000+rateBps)
+		return amount - tax, tax, amount
+	}
+	tax = amount.Percent(rateBps)
+	return amount, tax, amount + tax
+}
+
+// taxResolver decide la tasa de cada producto: la del producto, si no la de su
+// categoría y si no la predeterminada del restaurante. Puede no haber tasa.
+type taxResolver struct {
+	inclusive   bool
+	defaultRate *uuid.UUID
+	rates       map[uuid.UUID]*models.TaxRate
+	categories  map[uuid.UUID]*models.Category
+}
+
+func newTaxResolver(ctx context.Context, restaurant *models.Restaurant, taxRateRepo *repository.TaxRateRepository, categoryRepo *repository.CategoryRepository) (*taxResolver, error) {
+	rates, err := taxRateRepo.List(ctx, restaurant.ID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := categoryRepo.List(ctx, restaurant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &taxResolver{
+		inclusive:   restaurant.PricesIncludeTax,
+		defaultRate: restaurant.DefaultTaxRateID,
+		rates:       make(map[uuid.UUID]*models.TaxRate, len(rates)),
+		categories:  make(map[uuid.UUID]*models.Category, len(categories)),
+	}
+	for _, r := range rates {
+		res.rates[r.ID] = r
+	}
+	for _, c := range categories {
+		res.categories[c.ID] = c
+	}
+	return res, nil
+}
+
+func (t *taxResolver) rateFor(product *models.Product) *models.TaxRate {
+	if product.TaxRateID != nil {
+		if r, ok := t.rates[*product.TaxRateID]; ok {
+			return r
+		}
+	}
+	if product.CategoryID != nil {
+		if c, ok := t.categories[*product.CategoryID]; ok && c.TaxRateID != nil {
+			if r, ok := t.rates[*c.TaxRateID]; ok {
+				return r
+			}
+		}
+	}
+	if t.defaultRate != nil {
+		if r, ok := t.rates[*t.defaultRate]; ok {
+			return r
+		}
+	}
+	return nil
+}
+
+// taxBreakdown acumula base e impuesto por tasa manteniendo el orden de aparición
+type taxBreakdown struct {
+	order  []uuid.UUID
+	byRate map[uuid.UUID]*models.SaleTax
+}
+
+func newTaxBreakdown() *taxBreakdown {
+	return &taxBreakdown{byRate: make(map[uuid.UUID]*models.SaleTax)}
+}
+
+func (b *taxBreakdown) add(rate *models.TaxRate, base, tax money.Money) {
+	if rate == nil {
+		return
+	}
+	entry, ok := b.byRate[rate.ID]
+	if !ok {
+		rateID := rate.ID
+		entry = &models.SaleTax{
+			TaxRateID: &rateID,
+			Name:      rate.Name,
+			RateBps:   rate.RateBps,
+			Exempt:    rate.Exempt,
+		}
+		b.byRate[rate.ID] = entry
+		b.order = append(b.order, rate.ID)
+	}
+	entry.Base += base
+	entry.Amount += tax
+}
+
+func (b *taxBreakdown) list() []*models.SaleTax {
+	taxes := make([]*models.SaleTax, 0, len(b.order))
+	for _, id := range b.order {
+		taxes = append(taxes, b.byRate[id])
+	}
+	return taxes
+}
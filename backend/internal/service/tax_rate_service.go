@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+type TaxRateService struct {
+	taxRateRepo *repository.TaxRateRepository
+}
+
+func NewTaxRateService(taxRateRepo *repository.TaxRateRepository) *TaxRateService {
+	return &TaxRateService{taxRateRepo: taxRateRepo}
+}
+
+// TaxRateInput: la tasa se expresa en puntos básicos (1600 = 16%)
+type TaxRateInput struct {
+	Name    string `json:"name" binding:"required"`
+	RateBps int64  `json:"rate_bps" binding:"min=0,max=10000"`
+	Exempt  bool   `json:"exempt"`
+}
+
+func (in TaxRateInput) validate() error {
+	if in.Exempt && in.RateBps != 0 {
+		return NewValidationError("rate_bps", "una tasa exenta debe ser 0")
+	}
+	return nil
+}
+
+func (s *TaxRateService) Create(ctx context.Context, restaurantID uuid.UUID, input TaxRateInput) (*models.TaxRate, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	rate := &models.TaxRate{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		Name:         input.Name,
+		RateBps:      input.RateBps,
+		Exempt:       input.Exempt,
+	}
+	if err := s.taxRateRepo.Create(ctx, rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+func (s *TaxRateService) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.TaxRate, error) {
+	return s.taxRateRepo.List(ctx, restaurantID)
+}
+
+// Update cambia la tasa; las ventas ya registradas conservan su copia
+func (s *TaxRateService) Update(ctx context.Context, restaurantID, taxRateID uuid.UUID, input TaxRateInput) (*models.TaxRate, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	rate, err := s.taxRateRepo.GetByID(ctx, restaurantID, taxRateID)
+	if err != nil {
+		return nil, err
+	}
+	rate.Name = input.Name
+	rate.RateBps = input.RateBps
+	rate.Exempt = input.Exempt
+	if err := s.taxRateRepo.Update(ctx, rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+func (s *TaxRateService) Delete(ctx context.Context, restaurantID, taxRateID uuid.UUID) error {
+	err := s.taxRateRepo.Delete(ctx, restaurantID, taxRateID)
+	if errors.Is(err, errors.ErrConflict) {
+		return NewAppError(errors.ErrConflict, 409, "la tasa está asignada a productos, categorías o al restaurante")
+	}
+	return err
+}
+
+// resolveTaxRateID valida un tax_rate_id opcional del restaurante. Una cadena
+// vacía significa quitar la tasa.
+func resolveTaxRateID(ctx context.Context, taxRateRepo *repository.TaxRateRepository, restaurantID uuid.UUID, raw string) (*uuid.UUID, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, NewValidationError("tax_rate_id", "UUID inválido")
+	}
+	if _, err := taxRateRepo.GetByID(ctx, restaurantID, id); err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil, NewValidationError("tax_rate_id", "tasa de impuesto no encontrada")
+		}
+		return nil, err
+	}
+	return &id, nil
+}
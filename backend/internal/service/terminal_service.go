@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+)
+
+// TerminalService administra las terminales compartidas donde los usuarios
+// entran con PIN. La terminal se identifica con su token (cabecera
+// X-Terminal-Token), no con la sesión de quien la está usando.
+type TerminalService struct {
+	txManager    *repository.TxManager
+	terminalRepo *repository.TerminalRepository
+	authRepo     *repository.AuthRepository
+	sessionRepo  *repository.SessionRepository
+}
+
+func NewTerminalService(txManager *repository.TxManager, terminalRepo *repository.TerminalRepository, authRepo *repository.AuthRepository, sessionRepo *repository.SessionRepository) *TerminalService {
+	return &TerminalService{txManager: txManager, terminalRepo: terminalRepo, authRepo: authRepo, sessionRepo: sessionRepo}
+}
+
+type TerminalInput struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// TerminalCreated lleva el token de la terminal; solo se muestra al crearla
+type TerminalCreated struct {
+	Terminal *models.Terminal `json:"terminal"`
+	Token    string           `json:"token"`
+}
+
+// TerminalUser es un usuario que se puede elegir en la pantalla de PIN
+type TerminalUser struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+func (s *TerminalService) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.Terminal, error) {
+	return s.terminalRepo.List(ctx, restaurantID)
+}
+
+func (s *TerminalService) Get(ctx context.Context, restaurantID, terminalID uuid.UUID) (*models.Terminal, error) {
+	return s.terminalRepo.Get(ctx, restaurantID, terminalID)
+}
+
+// Create registra la terminal y genera su token; en la base solo queda el hash
+func (s *TerminalService) Create(ctx context.Context, restaurantID uuid.UUID, input TerminalInput) (*TerminalCreated, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	terminal := &models.Terminal{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		Name:         strings.TrimSpace(input.Name),
+		TokenHash:    hashToken(token),
+	}
+	if err := s.terminalRepo.Create(ctx, terminal); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe una terminal con ese nombre")
+		}
+		return nil, err
+	}
+	return &TerminalCreated{Terminal: terminal, Token: token}, nil
+}
+
+func (s *TerminalService) Update(ctx context.Context, restaurantID, terminalID uuid.UUID, input TerminalInput) (*models.Terminal, error) {
+	terminal, err := s.terminalRepo.Get(ctx, restaurantID, terminalID)
+	if err != nil {
+		return nil, err
+	}
+	terminal.Name = strings.TrimSpace(input.Name)
+	if err := s.terminalRepo.Update(ctx, terminal); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe una terminal con ese nombre")
+		}
+		return nil, err
+	}
+	return terminal, nil
+}
+
+// Delete da de baja la terminal: su token deja de valer y se cierra la sesión
+// abierta en ella
+func (s *TerminalService) Delete(ctx context.Context, restaurantID, terminalID uuid.UUID) error {
+	return s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.terminalRepo.WithTx(tx)
+		if _, err := repo.Get(ctx, restaurantID, terminalID); err != nil {
+			return err
+		}
+		if err := s.sessionRepo.WithTx(tx).RevokeTerminalSessions(ctx, terminalID, "terminal_deleted"); err != nil {
+			return err
+		}
+		return repo.Delete(ctx, restaurantID, terminalID)
+	})
+}
+
+// Users devuelve los usuarios que pueden entrar con PIN en la terminal
+func (s *TerminalService) Users(ctx context.Context, terminalToken string) ([]TerminalUser, error) {
+	terminal, err := terminalByToken(ctx, s.terminalRepo, terminalToken)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.authRepo.ListPINUsers(ctx, terminal.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]TerminalUser, 0, len(users))
+	for _, u := range users {
+		list = append(list, TerminalUser{ID: u.ID.String(), Email: u.Email, Role: u.Role})
+	}
+	return list, nil
+}
+
+// terminalByToken identifica la terminal que hace la petición
+func terminalByToken(ctx context.Context, repo *repository.TerminalRepository, token string) (*models.Terminal, error) {
+	if token == "" {
+		return nil, NewAppError(errors.ErrUnauthorized, 401, "falta el token de la terminal")
+	}
+	terminal, err := repo.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil, NewAppError(errors.ErrUnauthorized, 401, "terminal no registrada")
+		}
+		return nil, err
+	}
+	return terminal, nil
+}
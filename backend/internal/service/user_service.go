@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pos-saas/restaurant-pos/internal/errors"
+	"github.com/pos-saas/restaurant-pos/internal/models"
+	"github.com/pos-saas/restaurant-pos/internal/permissions"
+	"github.com/pos-saas/restaurant-pos/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UserService administra los usuarios del restaurante (solo admin)
+type UserService struct {
+	txManager   *repository.TxManager
+	authRepo    *repository.AuthRepository
+	roleRepo    *repository.RoleRepository
+	sessionRepo *repository.SessionRepository
+}
+
+func NewUserService(txManager *repository.TxManager, authRepo *repository.AuthRepository, roleRepo *repository.RoleRepository, sessionRepo *repository.SessionRepository) *UserService {
+	return &UserService{txManager: txManager, authRepo: authRepo, roleRepo: roleRepo, sessionRepo: sessionRepo}
+}
+
+type CreateUserInput struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6"`
+	Role     string `json:"role" binding:"required"` // rol predefinido o propio del restaurante
+}
+
+// UpdateUserInput cambia solo lo que se envía
+type UpdateUserInput struct {
+	Role   string `json:"role"`
+	Active *bool  `json:"active"`
+}
+
+type ResetPasswordInput struct {
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type SetPINInput struct {
+	PIN string `json:"pin" binding:"required"`
+}
+
+// pinPattern: el PIN son solo dígitos, para teclearlo en la pantalla de la terminal
+var pinPattern = regexp.MustCompile(`^[0-9]{4,8}$`)
+
+func (s *UserService) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.User, error) {
+	return s.authRepo.ListUsers(ctx, restaurantID)
+}
+
+func (s *UserService) Get(ctx context.Context, restaurantID, userID uuid.UUID) (*models.User, error) {
+	return s.authRepo.GetUserByID(ctx, restaurantID, userID)
+}
+
+func (s *UserService) Create(ctx context.Context, restaurantID uuid.UUID, input CreateUserInput) (*models.User, error) {
+	role := strings.ToLower(strings.TrimSpace(input.Role))
+	if err := validateRole(ctx, s.roleRepo, restaurantID, role); err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		Email:        normalizeEmail(input.Email),
+		PasswordHash: string(hash),
+		Role:         role,
+		Active:       true,
+	}
+	if err := s.authRepo.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, errors.ErrConflict) {
+			return nil, NewAppError(errors.ErrConflict, 409, "ya existe un usuario con ese email")
+		}
+		return nil, err
+	}
+	// Se relee para devolver las fechas que asigna la base
+	return s.authRepo.GetUserByID(ctx, restaurantID, user.ID)
+}
+
+func (s *UserService) Update(ctx context.Context, restaurantID, userID uuid.UUID, input UpdateUserInput) (*models.User, error) {
+	role := strings.ToLower(strings.TrimSpace(input.Role))
+	if role != "" {
+		if err := validateRole(ctx, s.roleRepo, restaurantID, role); err != nil {
+			return nil, err
+		}
+	}
+	var user *models.User
+	err := s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.authRepo.WithTx(tx)
+		var err error
+		user, err = repo.GetUserByID(ctx, restaurantID, userID)
+		if err != nil {
+			return err
+		}
+
+		wasAdmin := user.Role == permissions.RoleAdmin && user.Active
+		if role != "" {
+			user.Role = role
+		}
+		if input.Active != nil {
+			user.Active = *input.Active
+		}
+		if wasAdmin && (user.Role != permissions.RoleAdmin || !user.Active) {
+			if err := keepOneAdmin(ctx, repo, restaurantID, userID); err != nil {
+				return err
+			}
+		}
+		if err := repo.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+		// Reactivarlo no revive las sesiones que tenía
+		if !user.Active {
+			return s.sessionRepo.WithTx(tx).RevokeUserSessions(ctx, restaurantID, userID, "user_disabled")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ResetPassword asigna la contraseña y cierra las sesiones abiertas del usuario
+func (s *UserService) ResetPassword(ctx context.Context, restaurantID, userID uuid.UUID, input ResetPasswordInput) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := s.authRepo.WithTx(tx).UpdateUserPassword(ctx, restaurantID, userID, string(hash)); err != nil {
+			return err
+		}
+		return s.sessionRepo.WithTx(tx).RevokeUserSessions(ctx, restaurantID, userID, "password_reset")
+	})
+}
+
+// SetPIN asigna el PIN con el que el usuario entra en las terminales
+// compartidas; también levanta un bloqueo por intentos fallidos
+func (s *UserService) SetPIN(ctx context.Context, restaurantID, userID uuid.UUID, input SetPINInput) error {
+	if !pinPattern.MatchString(input.PIN) {
+		return NewValidationError("pin", "el PIN debe tener de 4 a 8 dígitos")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.PIN), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	pinHash := string(hash)
+	return s.authRepo.SetUserPIN(ctx, restaurantID, userID, &pinHash)
+}
+
+// ClearPIN quita el PIN: el usuario ya no puede entrar en las terminales
+func (s *UserService) ClearPIN(ctx context.Context, restaurantID, userID uuid.UUID) error {
+	return s.authRepo.SetUserPIN(ctx, restaurantID, userID, nil)
+}
+
+// Delete da de baja al usuario. Sus ventas y turnos se conservan
+func (s *UserService) Delete(ctx context.Context, restaurantID, userID uuid.UUID) error {
+	return s.txManager.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.authRepo.WithTx(tx)
+		user, err := repo.GetUserByID(ctx, restaurantID, userID)
+		if err != nil {
+			return err
+		}
+		if user.Role == permissions.RoleAdmin && user.Active {
+			if err := keepOneAdmin(ctx, repo, restaurantID, userID); err != nil {
+				return err
+			}
+		}
+		if err := repo.DeleteUser(ctx, restaurantID, userID); err != nil {
+			return err
+		}
+		return s.sessionRepo.WithTx(tx).RevokeUserSessions(ctx, restaurantID, userID, "user_disabled")
+	})
+}
+
+// keepOneAdmin impide quitar al último admin activo; bloquea a los admin
+// para que dos cambios simultáneos no dejen al restaurante sin ninguno
+// normalizeEmail guarda el email en minúsculas: el login y la unicidad por
+// restaurante no distinguen mayúsculas
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func keepOneAdmin(ctx context.Context, repo *repository.AuthRepository, restaurantID, userID uuid.UUID) error {
+	admins, err := repo.LockActiveAdmins(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+	for _, id := range admins {
+		if id != userID {
+			return nil
+		}
+	}
+	return NewAppError(errors.ErrConflict, 409, "el restaurante debe conservar al menos un admin activo")
+}
@@ -0,0 +1,8 @@
+-- Anulación de ventas: quién, cuándo y por qué
+
+ALTER TABLE sales
+    ADD COLUMN cancelled_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN cancelled_by UUID REFERENCES users(id),
+    ADD COLUMN cancel_reason TEXT;
+
+CREATE INDEX idx_sales_status ON sales(restaurant_id, status);
@@ -0,0 +1,37 @@
+-- Devoluciones (parciales o totales) sobre ventas completadas
+
+CREATE TABLE refunds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    sale_id UUID NOT NULL REFERENCES sales(id),
+    user_id UUID NOT NULL REFERENCES users(id),
+    total DECIMAL(12, 2) NOT NULL CHECK (total > 0),
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_refunds_restaurant ON refunds(restaurant_id, created_at DESC);
+CREATE INDEX idx_refunds_sale ON refunds(sale_id);
+
+-- Líneas devueltas: cantidad de cada sale_item y monto (incluye toppings)
+CREATE TABLE refund_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    refund_id UUID NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+    sale_item_id UUID NOT NULL REFERENCES sale_items(id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount >= 0)
+);
+
+CREATE INDEX idx_refund_items_refund ON refund_items(refund_id);
+CREATE INDEX idx_refund_items_sale_item ON refund_items(sale_item_id);
+
+-- Dinero devuelto por método
+CREATE TABLE refund_payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    refund_id UUID NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+    method VARCHAR(50) NOT NULL, -- cash, card, transfer
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    reference VARCHAR(255)
+);
+
+CREATE INDEX idx_refund_payments_refund ON refund_payments(refund_id);
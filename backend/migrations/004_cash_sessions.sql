@@ -0,0 +1,27 @@
+-- Turnos de caja (apertura, corte y cierre / reporte Z)
+
+CREATE TABLE cash_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    user_id UUID NOT NULL REFERENCES users(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'open', -- open, closed
+    opening_float DECIMAL(12, 2) NOT NULL DEFAULT 0 CHECK (opening_float >= 0),
+    expected_cash DECIMAL(12, 2),
+    counted_cash DECIMAL(12, 2) CHECK (counted_cash >= 0),
+    difference DECIMAL(12, 2),
+    notes TEXT,
+    opened_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    closed_at TIMESTAMP WITH TIME ZONE,
+    closed_by UUID REFERENCES users(id)
+);
+
+CREATE INDEX idx_cash_sessions_restaurant ON cash_sessions(restaurant_id, opened_at DESC);
+-- Un cajero solo puede tener un turno abierto a la vez
+CREATE UNIQUE INDEX idx_cash_sessions_open_user ON cash_sessions(user_id) WHERE status = 'open';
+
+ALTER TABLE sales ADD COLUMN cash_session_id UUID REFERENCES cash_sessions(id);
+CREATE INDEX idx_sales_cash_session ON sales(cash_session_id);
+
+-- Las devoluciones hechas con caja abierta también se descuentan del turno
+ALTER TABLE refunds ADD COLUMN cash_session_id UUID REFERENCES cash_sessions(id);
+CREATE INDEX idx_refunds_cash_session ON refunds(cash_session_id);
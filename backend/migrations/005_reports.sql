@@ -0,0 +1,5 @@
+-- Zona horaria del restaurante para agrupar reportes por día/hora local
+
+ALTER TABLE restaurants ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'America/Mexico_City';
+
+CREATE INDEX idx_sale_items_product ON sale_items(product_id);
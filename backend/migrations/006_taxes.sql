@@ -0,0 +1,51 @@
+-- Impuestos: tasas por restaurante asignables a productos o categorías
+
+CREATE TABLE tax_rates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    name VARCHAR(100) NOT NULL,
+    rate_bps INT NOT NULL DEFAULT 0 CHECK (rate_bps >= 0 AND rate_bps <= 10000), -- 1600 = 16%
+    exempt BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (NOT exempt OR rate_bps = 0)
+);
+
+CREATE INDEX idx_tax_rates_restaurant ON tax_rates(restaurant_id);
+
+-- Precios con impuesto incluido (true) o impuesto agregado al cobrar (false)
+ALTER TABLE restaurants
+    ADD COLUMN prices_include_tax BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN default_tax_rate_id UUID REFERENCES tax_rates(id);
+
+-- La tasa del producto tiene prioridad sobre la de su categoría y la del restaurante
+ALTER TABLE categories ADD COLUMN tax_rate_id UUID REFERENCES tax_rates(id);
+ALTER TABLE products ADD COLUMN tax_rate_id UUID REFERENCES tax_rates(id);
+
+-- total = subtotal + tax_total (precios sin impuesto) o subtotal ya neto (con impuesto)
+ALTER TABLE sales
+    ADD COLUMN subtotal DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN tax_total DECIMAL(12, 2) NOT NULL DEFAULT 0;
+UPDATE sales SET subtotal = total;
+
+-- subtotal = precio de lista de la línea; total = lo cobrado por la línea con impuesto
+ALTER TABLE sale_items
+    ADD COLUMN tax_rate_id UUID REFERENCES tax_rates(id) ON DELETE SET NULL,
+    ADD COLUMN tax_rate_bps INT NOT NULL DEFAULT 0,
+    ADD COLUMN tax_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN total DECIMAL(12, 2);
+UPDATE sale_items SET total = subtotal;
+ALTER TABLE sale_items ALTER COLUMN total SET NOT NULL;
+
+-- Desglose de impuestos por tasa (copia del nombre/tasa al momento de la venta)
+CREATE TABLE sale_taxes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    sale_id UUID NOT NULL REFERENCES sales(id) ON DELETE CASCADE,
+    tax_rate_id UUID REFERENCES tax_rates(id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    rate_bps INT NOT NULL,
+    exempt BOOLEAN NOT NULL DEFAULT false,
+    base DECIMAL(12, 2) NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL
+);
+
+CREATE INDEX idx_sale_taxes_sale ON sale_taxes(sale_id);
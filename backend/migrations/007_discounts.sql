@@ -0,0 +1,22 @@
+-- Descuentos por línea y por ticket
+
+-- Descuento máximo que puede aplicar un cajero (1000 = 10%); los admin no tienen tope
+ALTER TABLE restaurants ADD COLUMN max_discount_bps INT NOT NULL DEFAULT 1000 CHECK (max_discount_bps >= 0 AND max_discount_bps <= 10000);
+
+-- discount_value: porcentaje (10.00 = 10%) o importe fijo según discount_type.
+-- discount_amount es el descuento propio de la línea y ticket_discount la parte
+-- prorrateada del descuento del ticket; total ya los descuenta.
+ALTER TABLE sale_items
+    ADD COLUMN discount_type VARCHAR(10) CHECK (discount_type IN ('percent', 'fixed')),
+    ADD COLUMN discount_value DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN discount_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN discount_reason TEXT,
+    ADD COLUMN ticket_discount DECIMAL(12, 2) NOT NULL DEFAULT 0;
+
+-- discount_total = descuentos de líneas + descuento del ticket
+ALTER TABLE sales
+    ADD COLUMN discount_type VARCHAR(10) CHECK (discount_type IN ('percent', 'fixed')),
+    ADD COLUMN discount_value DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN discount_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN discount_reason TEXT,
+    ADD COLUMN discount_total DECIMAL(12, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- Propinas: se registran aparte del total de la venta para no contarlas como ingreso
+
+-- tip es la propina cobrada junto con el pago (p. ej. la agregada a la tarjeta)
+ALTER TABLE sale_payments ADD COLUMN tip DECIMAL(12, 2) NOT NULL DEFAULT 0 CHECK (tip >= 0);
+
+-- tip_total = suma de las propinas de los pagos
+ALTER TABLE sales ADD COLUMN tip_total DECIMAL(12, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,42 @@
+-- Catálogo de modificadores (extras, salsas, término de la carne...) por producto
+
+CREATE TABLE modifier_groups (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    name VARCHAR(100) NOT NULL,
+    min_select INT NOT NULL DEFAULT 0 CHECK (min_select >= 0),
+    max_select INT NOT NULL DEFAULT 0 CHECK (max_select >= 0), -- 0 = sin límite
+    required BOOLEAN NOT NULL DEFAULT false,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (max_select = 0 OR max_select >= min_select)
+);
+
+CREATE TABLE modifier_options (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    group_id UUID NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (price >= 0),
+    active BOOLEAN DEFAULT true,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE product_modifier_groups (
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    group_id UUID NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    sort_order INT DEFAULT 0,
+    PRIMARY KEY (product_id, group_id)
+);
+
+CREATE INDEX idx_modifier_groups_restaurant ON modifier_groups(restaurant_id);
+CREATE INDEX idx_modifier_options_group ON modifier_options(group_id);
+CREATE INDEX idx_product_modifier_groups_group ON product_modifier_groups(group_id);
+
+CREATE TRIGGER update_modifier_groups_updated_at BEFORE UPDATE ON modifier_groups
+    FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+-- sale_item_toppings sigue guardando nombre y precio; la opción queda como referencia
+ALTER TABLE sale_item_toppings
+    ADD COLUMN modifier_option_id UUID REFERENCES modifier_options(id) ON DELETE SET NULL;
@@ -0,0 +1,25 @@
+-- Variantes y tamaños de producto (chico/mediano/grande) con precio propio
+
+CREATE TABLE product_variants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    sku VARCHAR(64),
+    price DECIMAL(10, 2) NOT NULL CHECK (price >= 0),
+    active BOOLEAN DEFAULT true,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_product_variants_product ON product_variants(product_id);
+CREATE UNIQUE INDEX idx_product_variants_sku ON product_variants(restaurant_id, sku) WHERE sku IS NOT NULL;
+
+CREATE TRIGGER update_product_variants_updated_at BEFORE UPDATE ON product_variants
+    FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+-- El nombre de la variante se copia al vender
+ALTER TABLE sale_items
+    ADD COLUMN variant_id UUID REFERENCES product_variants(id) ON DELETE SET NULL,
+    ADD COLUMN variant_name VARCHAR(100);
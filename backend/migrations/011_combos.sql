@@ -0,0 +1,35 @@
+-- Combos: un producto tipo combo define espacios (hamburguesa, acompañamiento,
+-- bebida) con los productos permitidos y su recargo
+
+ALTER TABLE products
+    ADD COLUMN product_type VARCHAR(20) NOT NULL DEFAULT 'simple' CHECK (product_type IN ('simple', 'combo'));
+
+CREATE TABLE combo_slots (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    combo_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    quantity INT NOT NULL DEFAULT 1 CHECK (quantity > 0), -- unidades del componente por combo
+    required BOOLEAN NOT NULL DEFAULT true,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE combo_slot_options (
+    slot_id UUID NOT NULL REFERENCES combo_slots(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    upcharge DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (upcharge >= 0),
+    sort_order INT DEFAULT 0,
+    PRIMARY KEY (slot_id, product_id)
+);
+
+CREATE INDEX idx_combo_slots_combo ON combo_slots(combo_id);
+CREATE INDEX idx_combo_slot_options_product ON combo_slot_options(product_id);
+
+-- Los componentes elegidos se guardan como líneas hijas de la línea del combo.
+-- Lo cobrado va en la línea del combo; las hijas tienen total 0.
+ALTER TABLE sale_items
+    ADD COLUMN parent_item_id UUID REFERENCES sale_items(id) ON DELETE CASCADE,
+    ADD COLUMN combo_slot_name VARCHAR(100);
+
+CREATE INDEX idx_sale_items_parent ON sale_items(parent_item_id);
@@ -0,0 +1,52 @@
+-- Inventario: insumos con unidad, recetas por producto/variante/opción de
+-- modificador y movimientos de stock (ventas, anulaciones y ajustes manuales)
+
+CREATE TABLE ingredients (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    name VARCHAR(100) NOT NULL,
+    unit VARCHAR(10) NOT NULL CHECK (unit IN ('unit', 'g', 'kg', 'ml', 'l')),
+    stock DECIMAL(12, 3) NOT NULL DEFAULT 0, -- puede quedar negativo: no se bloquean ventas
+    low_stock_threshold DECIMAL(12, 3) NOT NULL DEFAULT 0 CHECK (low_stock_threshold >= 0),
+    active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (restaurant_id, name)
+);
+
+CREATE TRIGGER update_ingredients_updated_at BEFORE UPDATE ON ingredients
+    FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+-- Una línea de receta pertenece a un producto (opcionalmente a una de sus
+-- variantes) o a una opción de modificador. Si una variante tiene receta propia
+-- se usa esa; si no, la del producto.
+CREATE TABLE recipe_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    product_id UUID REFERENCES products(id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES product_variants(id) ON DELETE CASCADE,
+    modifier_option_id UUID REFERENCES modifier_options(id) ON DELETE CASCADE,
+    ingredient_id UUID NOT NULL REFERENCES ingredients(id),
+    quantity DECIMAL(12, 3) NOT NULL CHECK (quantity > 0),
+    CHECK ((product_id IS NOT NULL) <> (modifier_option_id IS NOT NULL)),
+    CHECK (variant_id IS NULL OR product_id IS NOT NULL)
+);
+
+CREATE INDEX idx_recipe_items_product ON recipe_items(product_id);
+CREATE INDEX idx_recipe_items_option ON recipe_items(modifier_option_id);
+CREATE INDEX idx_recipe_items_ingredient ON recipe_items(ingredient_id);
+
+CREATE TABLE stock_movements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    ingredient_id UUID NOT NULL REFERENCES ingredients(id),
+    quantity DECIMAL(12, 3) NOT NULL, -- positivo entra, negativo sale
+    type VARCHAR(20) NOT NULL CHECK (type IN ('sale', 'sale_cancel', 'adjustment')),
+    reason TEXT,
+    sale_id UUID REFERENCES sales(id) ON DELETE SET NULL,
+    user_id UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_stock_movements_ingredient ON stock_movements(ingredient_id, created_at);
+CREATE INDEX idx_stock_movements_sale ON stock_movements(sale_id);
@@ -0,0 +1,84 @@
+-- Compras: proveedores, órdenes de compra y recepciones de mercancía. Cada
+-- recepción entra al stock y queda en el historial de costos por insumo.
+
+CREATE TABLE suppliers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    name VARCHAR(150) NOT NULL,
+    contact_name VARCHAR(150),
+    phone VARCHAR(50),
+    email VARCHAR(255),
+    notes TEXT,
+    active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (restaurant_id, name)
+);
+
+CREATE TRIGGER update_suppliers_updated_at BEFORE UPDATE ON suppliers
+    FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+CREATE TABLE purchase_orders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    supplier_id UUID NOT NULL REFERENCES suppliers(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'partial', 'received', 'cancelled')),
+    total DECIMAL(12, 2) NOT NULL DEFAULT 0, -- total pedido
+    notes TEXT,
+    expected_at DATE,
+    created_by UUID NOT NULL REFERENCES users(id),
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_purchase_orders_restaurant ON purchase_orders(restaurant_id, created_at);
+
+CREATE TRIGGER update_purchase_orders_updated_at BEFORE UPDATE ON purchase_orders
+    FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+CREATE TABLE purchase_order_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    purchase_order_id UUID NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    ingredient_id UUID NOT NULL REFERENCES ingredients(id),
+    quantity DECIMAL(12, 3) NOT NULL CHECK (quantity > 0),
+    unit_cost DECIMAL(12, 2) NOT NULL CHECK (unit_cost >= 0),
+    received_quantity DECIMAL(12, 3) NOT NULL DEFAULT 0,
+    CHECK (received_quantity <= quantity)
+);
+
+CREATE INDEX idx_purchase_order_items_order ON purchase_order_items(purchase_order_id);
+
+CREATE TABLE goods_receipts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    purchase_order_id UUID NOT NULL REFERENCES purchase_orders(id),
+    received_by UUID NOT NULL REFERENCES users(id),
+    total DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_goods_receipts_order ON goods_receipts(purchase_order_id);
+
+-- Historial de costos: cantidad recibida y costo real de cada insumo
+CREATE TABLE goods_receipt_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    goods_receipt_id UUID NOT NULL REFERENCES goods_receipts(id) ON DELETE CASCADE,
+    purchase_order_item_id UUID NOT NULL REFERENCES purchase_order_items(id),
+    ingredient_id UUID NOT NULL REFERENCES ingredients(id),
+    quantity DECIMAL(12, 3) NOT NULL CHECK (quantity > 0),
+    unit_cost DECIMAL(12, 2) NOT NULL CHECK (unit_cost >= 0),
+    total_cost DECIMAL(12, 2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_goods_receipt_items_receipt ON goods_receipt_items(goods_receipt_id);
+CREATE INDEX idx_goods_receipt_items_ingredient ON goods_receipt_items(restaurant_id, ingredient_id, created_at);
+
+-- Las recepciones entran al stock como movimientos de tipo purchase
+ALTER TABLE stock_movements DROP CONSTRAINT stock_movements_type_check;
+ALTER TABLE stock_movements
+    ADD CONSTRAINT stock_movements_type_check CHECK (type IN ('sale', 'sale_cancel', 'adjustment', 'purchase')),
+    ADD COLUMN goods_receipt_id UUID REFERENCES goods_receipts(id) ON DELETE SET NULL;
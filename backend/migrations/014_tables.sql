@@ -0,0 +1,41 @@
+-- Servicio en mesa: áreas, mesas y cuentas abiertas. Una cuenta abierta es una
+-- venta en estado pending que se completa al cobrarla.
+
+CREATE TABLE table_areas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    name VARCHAR(100) NOT NULL,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (restaurant_id, name)
+);
+
+CREATE TRIGGER update_table_areas_updated_at BEFORE UPDATE ON table_areas
+    FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+CREATE TABLE restaurant_tables (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    area_id UUID REFERENCES table_areas(id) ON DELETE SET NULL,
+    name VARCHAR(50) NOT NULL,
+    seats INT NOT NULL DEFAULT 4 CHECK (seats > 0),
+    active BOOLEAN DEFAULT true,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (restaurant_id, name)
+);
+
+CREATE INDEX idx_restaurant_tables_area ON restaurant_tables(area_id);
+
+CREATE TRIGGER update_restaurant_tables_updated_at BEFORE UPDATE ON restaurant_tables
+    FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+-- opened_at es cuándo se abrió la cuenta; al cobrarla created_at pasa a ser la
+-- fecha de cobro para que la venta cuente en el día y turno en que se pagó
+ALTER TABLE sales
+    ADD COLUMN table_id UUID REFERENCES restaurant_tables(id),
+    ADD COLUMN opened_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_sales_open_table ON sales(table_id) WHERE status = 'pending';
@@ -0,0 +1,36 @@
+-- Cuenta dividida: una venta pendiente se reparte en subcuentas que se cobran
+-- por separado. Cada línea se asigna a una o varias subcuentas como fracción
+-- (share_num/share_den de la línea); la venta se completa al cobrar la última.
+
+CREATE TABLE sale_checks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    sale_id UUID NOT NULL REFERENCES sales(id) ON DELETE CASCADE,
+    number INT NOT NULL CHECK (number > 0),
+    tax_total DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    total DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    tip_total DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'paid')),
+    paid_at TIMESTAMP WITH TIME ZONE,
+    paid_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (sale_id, number)
+);
+
+CREATE TRIGGER update_sale_checks_updated_at BEFORE UPDATE ON sale_checks
+    FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+CREATE TABLE sale_check_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    check_id UUID NOT NULL REFERENCES sale_checks(id) ON DELETE CASCADE,
+    sale_item_id UUID NOT NULL REFERENCES sale_items(id) ON DELETE CASCADE,
+    share_num INT NOT NULL CHECK (share_num > 0),
+    share_den INT NOT NULL CHECK (share_den >= share_num),
+    tax_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    total DECIMAL(12, 2) NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_sale_check_items_check ON sale_check_items(check_id);
+
+-- Los pagos de una subcuenta quedan ligados a ella
+ALTER TABLE sale_payments ADD COLUMN check_id UUID REFERENCES sale_checks(id);
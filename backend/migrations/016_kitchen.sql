@@ -0,0 +1,54 @@
+-- Pantalla de cocina (KDS): estaciones, ruteo por producto o categoría y
+-- comandas. Cada venta o cuenta que agrega productos genera una comanda por
+-- estación; el producto sin estación propia usa la de su categoría.
+
+CREATE TABLE kitchen_stations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    name VARCHAR(100) NOT NULL,
+    active BOOLEAN DEFAULT true,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (restaurant_id, name)
+);
+
+CREATE TRIGGER update_kitchen_stations_updated_at BEFORE UPDATE ON kitchen_stations
+    FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+ALTER TABLE products ADD COLUMN station_id UUID REFERENCES kitchen_stations(id) ON DELETE SET NULL;
+ALTER TABLE categories ADD COLUMN station_id UUID REFERENCES kitchen_stations(id) ON DELETE SET NULL;
+
+CREATE TABLE kitchen_tickets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    station_id UUID NOT NULL REFERENCES kitchen_stations(id),
+    sale_id UUID NOT NULL REFERENCES sales(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'in_progress', 'ready', 'served')),
+    started_at TIMESTAMP WITH TIME ZONE,
+    ready_at TIMESTAMP WITH TIME ZONE,
+    served_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- La pantalla consulta por estación y por última modificación
+CREATE INDEX idx_kitchen_tickets_station ON kitchen_tickets(restaurant_id, station_id, updated_at);
+
+CREATE TRIGGER update_kitchen_tickets_updated_at BEFORE UPDATE ON kitchen_tickets
+    FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+-- name, notes y modifiers son copia de la línea al momento de la comanda
+CREATE TABLE kitchen_ticket_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ticket_id UUID NOT NULL REFERENCES kitchen_tickets(id) ON DELETE CASCADE,
+    sale_item_id UUID REFERENCES sale_items(id) ON DELETE SET NULL,
+    position INT NOT NULL DEFAULT 0,
+    name VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    notes TEXT,
+    modifiers TEXT[] NOT NULL DEFAULT '{}',
+    combo_name VARCHAR(255)
+);
+
+CREATE INDEX idx_kitchen_ticket_items_ticket ON kitchen_ticket_items(ticket_id);
@@ -0,0 +1,7 @@
+-- Alta de usuarios por el admin. El email solo es único entre los usuarios
+-- no borrados, para poder volver a dar de alta a alguien que se dio de baja.
+
+ALTER TABLE users DROP CONSTRAINT users_restaurant_id_email_key;
+
+CREATE UNIQUE INDEX idx_users_restaurant_email_active ON users(restaurant_id, LOWER(email))
+    WHERE deleted_at IS NULL;
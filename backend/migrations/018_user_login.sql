@@ -0,0 +1,15 @@
+-- Cada usuario inicia sesión con su propio email. Si el mismo email existe en
+-- varios restaurantes, el slug indica en cuál entrar.
+
+ALTER TABLE restaurants ADD COLUMN slug VARCHAR(100);
+
+-- Los restaurantes existentes reciben su nombre en minúsculas sin acentos más
+-- el inicio del ID, que lo hace único
+UPDATE restaurants SET slug =
+    TRIM(BOTH '-' FROM REGEXP_REPLACE(TRANSLATE(LOWER(name), 'áéíóúüñ', 'aeiouun'), '[^a-z0-9]+', '-', 'g'))
+    || '-' || SUBSTRING(REPLACE(id::text, '-', '') FROM 1 FOR 6);
+
+ALTER TABLE restaurants ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE restaurants ADD CONSTRAINT restaurants_slug_key UNIQUE (slug);
+
+CREATE INDEX idx_users_login_email ON users(LOWER(email)) WHERE deleted_at IS NULL;
@@ -0,0 +1,17 @@
+-- Permisos por rol. Los roles predefinidos (admin, manager, cajero, mesero,
+-- cocina) están en el código; aquí van los roles propios de cada restaurante.
+-- users.role guarda el nombre del rol, predefinido o propio.
+
+CREATE TABLE roles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    name VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (restaurant_id, name)
+);
+
+CREATE TRIGGER update_roles_updated_at BEFORE UPDATE ON roles
+    FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
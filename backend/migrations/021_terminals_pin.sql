@@ -0,0 +1,28 @@
+-- Cambio rápido de cajero en terminales compartidas. Cada usuario puede tener
+-- un PIN numérico (guardado con bcrypt, como la contraseña); una terminal
+-- registrada por el admin cambia el usuario activo con el PIN. Tras varios
+-- PIN incorrectos el usuario queda bloqueado unos minutos.
+
+ALTER TABLE users ADD COLUMN pin_hash VARCHAR(255);
+ALTER TABLE users ADD COLUMN pin_failed_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN pin_locked_until TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE terminals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE, -- SHA-256 en hexadecimal
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (restaurant_id, name)
+);
+
+CREATE TRIGGER update_terminals_updated_at BEFORE UPDATE ON terminals
+    FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+-- Sesión abierta con PIN en una terminal; al cambiar de usuario se revoca
+-- (revoke_reason terminal_switch, o terminal_deleted al dar de baja la terminal)
+ALTER TABLE auth_sessions ADD COLUMN terminal_id UUID REFERENCES terminals(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_auth_sessions_terminal ON auth_sessions(terminal_id) WHERE revoked_at IS NULL;
@@ -0,0 +1,12 @@
+-- Cada pago queda en el turno de quien lo cobró. Con la cuenta dividida, cada
+-- subcuenta puede cobrarla un cajero distinto; el corte suma por el turno del
+-- pago y no por el de la venta, que es el de quien cobró la última subcuenta.
+
+ALTER TABLE sale_payments ADD COLUMN cash_session_id UUID REFERENCES cash_sessions(id);
+
+UPDATE sale_payments sp
+SET cash_session_id = s.cash_session_id
+FROM sales s
+WHERE s.id = sp.sale_id;
+
+CREATE INDEX idx_sale_payments_cash_session ON sale_payments(cash_session_id);
@@ -0,0 +1,9 @@
+-- Comandas anuladas: al quitar una línea de una cuenta abierta se borra de las
+-- comandas ya enviadas, y la comanda que se queda sin líneas, igual que las de
+-- una venta o cuenta anulada, pasa a 'voided' y sale de la pantalla de cocina.
+
+ALTER TABLE kitchen_tickets DROP CONSTRAINT kitchen_tickets_status_check;
+ALTER TABLE kitchen_tickets ADD CONSTRAINT kitchen_tickets_status_check
+    CHECK (status IN ('new', 'in_progress', 'ready', 'served', 'voided'));
+
+ALTER TABLE kitchen_tickets ADD COLUMN voided_at TIMESTAMP WITH TIME ZONE;
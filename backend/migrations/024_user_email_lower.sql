@@ -0,0 +1,11 @@
+-- Los emails de usuario se guardan en minúsculas y sin espacios. La unicidad
+-- por restaurante ya no distingue mayúsculas ni cuenta a los borrados
+-- (idx_users_restaurant_email_active, 017); se recrea por si la base venía de
+-- antes con el UNIQUE(restaurant_id, email) original.
+
+UPDATE users SET email = LOWER(TRIM(email)) WHERE email <> LOWER(TRIM(email));
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_restaurant_id_email_key;
+DROP INDEX IF EXISTS idx_users_restaurant_email_active;
+CREATE UNIQUE INDEX idx_users_restaurant_email_active ON users(restaurant_id, LOWER(email))
+    WHERE deleted_at IS NULL;
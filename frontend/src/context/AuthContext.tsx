@@ -1,16 +1,18 @@
 import { createContext, useContext, useState, useEffect } from 'react';
 import type { ReactNode } from 'react';
-import { authApi } from '../services/api';
+import { authApi, terminalApi } from '../services/api';
 
 interface User {
   id: string;
   email: string;
   role: string;
+  permissions?: string[]; // p. ej. "sales:cancel"; ver GET /permissions
 }
 
 interface Restaurant {
   id: string;
   name: string;
+  slug: string;
   email: string;
 }
 
@@ -18,9 +20,11 @@ interface AuthContextType {
   user: User | null;
   restaurant: Restaurant | null;
   token: string | null;
-  login: (email: string, password: string) => Promise<void>;
+  login: (email: string, password: string, restaurant?: string) => Promise<void>;
   register: (data: RegisterData) => Promise<void>;
+  switchUser: (userId: string, pin: string) => Promise<void>;
   logout: () => void;
+  can: (permission: string) => boolean;
   isAuthenticated: boolean;
 }
 
@@ -55,9 +59,10 @@ export function AuthProvider({ children }: { children: ReactNode }) {
     }
   }, []);
 
-  const login = async (email: string, password: string) => {
-    const { data } = await authApi.login({ email, password });
+  const login = async (email: string, password: string, restaurant?: string) => {
+    const { data } = await authApi.login({ email, password, restaurant });
     localStorage.setItem('token', data.token);
+    localStorage.setItem('refreshToken', data.refresh_token);
     localStorage.setItem('user', JSON.stringify(data.user));
     localStorage.setItem('restaurant', JSON.stringify(data.restaurant));
     setToken(data.token);
@@ -68,6 +73,7 @@ export function AuthProvider({ children }: { children: ReactNode }) {
   const register = async (data: RegisterData) => {
     const { data: res } = await authApi.register(data);
     localStorage.setItem('token', res.token);
+    localStorage.setItem('refreshToken', res.refresh_token);
     localStorage.setItem('user', JSON.stringify(res.user));
     localStorage.setItem('restaurant', JSON.stringify(res.restaurant));
     setToken(res.token);
@@ -75,8 +81,28 @@ export function AuthProvider({ children }: { children: ReactNode }) {
     setRestaurant(res.restaurant);
   };
 
+  // En una terminal compartida (token guardado en terminalToken) el usuario
+  // entra con su PIN y reemplaza al anterior
+  const switchUser = async (userId: string, pin: string) => {
+    const terminalToken = localStorage.getItem('terminalToken') ?? '';
+    const { data } = await terminalApi.switchUser(terminalToken, { user_id: userId, pin });
+    localStorage.setItem('token', data.token);
+    localStorage.setItem('refreshToken', data.refresh_token);
+    localStorage.setItem('user', JSON.stringify(data.user));
+    localStorage.setItem('restaurant', JSON.stringify(data.restaurant));
+    setToken(data.token);
+    setUser(data.user);
+    setRestaurant(data.restaurant);
+  };
+
   const logout = () => {
+    const refreshToken = localStorage.getItem('refreshToken');
+    if (refreshToken) {
+      // Revoca la sesión en el servidor; la salida local no espera la respuesta
+      authApi.logout(refreshToken).catch(() => undefined);
+    }
     localStorage.removeItem('token');
+    localStorage.removeItem('refreshToken');
     localStorage.removeItem('user');
     localStorage.removeItem('restaurant');
     setToken(null);
@@ -84,6 +110,9 @@ export function AuthProvider({ children }: { children: ReactNode }) {
     setRestaurant(null);
   };
 
+  // Solo oculta opciones de la interfaz; la API valida cada permiso
+  const can = (permission: string) => !!user?.permissions?.includes(permission);
+
   return (
     <AuthContext.Provider
       value={{
@@ -92,7 +121,9 @@ export function AuthProvider({ children }: { children: ReactNode }) {
         token,
         login,
         register,
+        switchUser,
         logout,
+        can,
         isAuthenticated: !!token,
       }}
     >
@@ -3,9 +3,17 @@ import { Link, useNavigate } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
 import styles from './Auth.module.css';
 
+interface RestaurantOption {
+  slug: string;
+  name: string;
+}
+
 export default function Login() {
   const [email, setEmail] = useState('');
   const [password, setPassword] = useState('');
+  // Restaurantes a elegir cuando el email existe en más de uno
+  const [restaurants, setRestaurants] = useState<RestaurantOption[]>([]);
+  const [restaurant, setRestaurant] = useState('');
   const [error, setError] = useState('');
   const [loading, setLoading] = useState(false);
   const { login } = useAuth();
@@ -16,9 +24,18 @@ export default function Login() {
     setError('');
     setLoading(true);
     try {
-      await login(email, password);
+      await login(email, password, restaurant || undefined);
       navigate('/');
     } catch (err: unknown) {
+      const data = err && typeof err === 'object' && 'response' in err
+        ? (err as { response?: { data?: { restaurants?: RestaurantOption[] } } }).response?.data
+        : undefined;
+      if (data?.restaurants?.length) {
+        setRestaurants(data.restaurants);
+        setRestaurant(data.restaurants[0].slug);
+        setError('Elige el restaurante');
+        return;
+      }
       const msg = err && typeof err === 'object' && 'response' in err
         ? (err as { response?: { data?: { error?: string } } }).response?.data?.error
         : 'Error al iniciar sesión';
@@ -51,6 +68,13 @@ export default function Login() {
             required
             autoComplete="current-password"
           />
+          {restaurants.length > 0 && (
+            <select value={restaurant} onChange={(e) => setRestaurant(e.target.value)}>
+              {restaurants.map((r) => (
+                <option key={r.slug} value={r.slug}>{r.name}</option>
+              ))}
+            </select>
+          )}
           <button type="submit" disabled={loading}>
             {loading ? 'Ingresando...' : 'Ingresar'}
           </button>
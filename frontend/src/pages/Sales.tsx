@@ -1,12 +1,16 @@
 import { useEffect, useState } from 'react';
-import { productsApi, categoriesApi, salesApi, api } from '../services/api';
-import type { Product, Category } from '../types';
+import { productsApi, categoriesApi, salesApi, cashSessionsApi, api } from '../services/api';
+import type { Product, ProductVariant, Category, CashSession } from '../types';
 import styles from './Sales.module.css';
 
 interface CartItem {
   product: Product;
+  variant?: ProductVariant;
   quantity: number;
-  toppings: { name: string; price: number; quantity: number }[];
+  // Opciones del catálogo de modificadores; quantity es por unidad
+  modifiers: { option_id: string; name: string; price: number; quantity: number }[];
+  // Combos: primera opción de cada espacio obligatorio; upcharge es por combo
+  components: { slot_id: string; product_id: string; upcharge: number }[];
 }
 
 export default function Sales() {
@@ -18,6 +22,8 @@ export default function Sales() {
   const [error, setError] = useState('');
   const [lastSaleId, setLastSaleId] = useState<string | null>(null);
   const [filterCat, setFilterCat] = useState('');
+  const [session, setSession] = useState<CashSession | null>(null);
+  const [openingFloat, setOpeningFloat] = useState('0');
 
   const load = async () => {
     try {
@@ -29,6 +35,10 @@ export default function Sales() {
       ]);
       setProducts(Array.isArray(prodsRes) ? prodsRes : []);
       setCategories(Array.isArray(catsRes) ? catsRes : []);
+      const current = await cashSessionsApi.current()
+        .then((r) => r.data.session as CashSession)
+        .catch(() => null);
+      setSession(current);
     } catch (e) {
       const msg = e && typeof e === 'object' && 'response' in e
         ? (e as { response?: { data?: { error?: string } } }).response?.data?.error
@@ -47,14 +57,32 @@ export default function Sales() {
     ? products.filter((p) => p.category_id === filterCat)
     : products;
 
-  const addToCart = (product: Product) => {
-    const existing = cart.find((c) => c.product.id === product.id && c.toppings.length === 0);
+  // Un producto con variantes activas se muestra como un botón por variante
+  const sellable = filteredProducts.flatMap((p) => {
+    const variants = (p.variants || []).filter((v) => v.active);
+    return variants.length > 0
+      ? variants.map((v) => ({ product: p, variant: v as ProductVariant | undefined }))
+      : [{ product: p, variant: undefined as ProductVariant | undefined }];
+  });
+
+  const unitPrice = (item: CartItem) => (item.variant ? item.variant.price : item.product.price);
+
+  const addToCart = (product: Product, variant?: ProductVariant) => {
+    const existing = cart.find((c) =>
+      c.product.id === product.id && c.variant?.id === variant?.id && c.modifiers.length === 0);
+    const components = (product.slots || [])
+      .filter((slot) => slot.required && slot.options.length > 0)
+      .map((slot) => ({
+        slot_id: slot.id,
+        product_id: slot.options[0].product_id,
+        upcharge: slot.options[0].upcharge * slot.quantity,
+      }));
     if (existing) {
       setCart(cart.map((c) =>
         c === existing ? { ...c, quantity: c.quantity + 1 } : c
       ));
     } else {
-      setCart([...cart, { product, quantity: 1, toppings: [] }]);
+      setCart([...cart, { product, variant, quantity: 1, modifiers: [], components }]);
     }
   };
 
@@ -74,8 +102,9 @@ export default function Sales() {
 
   const total = cart.reduce(
     (sum, item) =>
-      sum + item.product.price * item.quantity +
-      item.toppings.reduce((t, tp) => t + tp.price * tp.quantity, 0),
+      sum + (unitPrice(item) +
+        item.modifiers.reduce((t, m) => t + m.price * m.quantity, 0) +
+        item.components.reduce((t, c) => t + c.upcharge, 0)) * item.quantity,
     0
   );
 
@@ -94,12 +123,13 @@ export default function Sales() {
     try {
       const items = cart.map((item) => ({
         product_id: item.product.id,
+        variant_id: item.variant?.id,
         quantity: item.quantity,
-        toppings: item.toppings.map((t) => ({
-          name: t.name,
-          price: t.price,
-          quantity: t.quantity,
+        modifiers: item.modifiers.map((m) => ({
+          option_id: m.option_id,
+          quantity: m.quantity,
         })),
+        components: item.components.map((c) => ({ slot_id: c.slot_id, product_id: c.product_id })),
       }));
       const payments = [{ method: 'cash', amount: total }];
       const { data } = await salesApi.create({ items, payments });
@@ -115,6 +145,19 @@ export default function Sales() {
     }
   };
 
+  const handleOpenSession = async () => {
+    setError('');
+    try {
+      const { data } = await cashSessionsApi.open({ opening_float: Number(openingFloat) || 0 });
+      setSession(data);
+    } catch (e: unknown) {
+      const msg = e && typeof e === 'object' && 'response' in e
+        ? (e as { response?: { data?: { error?: string } } }).response?.data?.error
+        : 'Error al abrir caja';
+      setError(msg || 'Error');
+    }
+  };
+
   const downloadPdf = async () => {
     if (!lastSaleId) return;
     try {
@@ -138,6 +181,19 @@ export default function Sales() {
     <div className={styles.page}>
       <h1>Nueva Venta</h1>
       {error && <div className={styles.error}>{error}</div>}
+      {!loading && !session && (
+        <div className={styles.success}>
+          No tienes un turno de caja abierto. Fondo inicial: $
+          <input
+            type="number"
+            min="0"
+            step="0.01"
+            value={openingFloat}
+            onChange={(e) => setOpeningFloat(e.target.value)}
+          />
+          <button onClick={handleOpenSession} type="button">Abrir caja</button>
+        </div>
+      )}
       {lastSaleId && (
         <div className={styles.success}>
           Venta registrada. <button onClick={downloadPdf} type="button">Descargar factura PDF</button>
@@ -159,15 +215,15 @@ export default function Sales() {
             <p>Cargando productos...</p>
           ) : (
             <div className={styles.productGrid}>
-              {filteredProducts.map((p) => (
+              {sellable.map(({ product: p, variant: v }) => (
                 <button
-                  key={p.id}
+                  key={v ? v.id : p.id}
                   type="button"
                   className={styles.productBtn}
-                  onClick={() => addToCart(p)}
+                  onClick={() => addToCart(p, v)}
                 >
-                  <span className={styles.productName}>{p.name}</span>
-                  <span className={styles.productPrice}>${Number(p.price).toFixed(2)}</span>
+                  <span className={styles.productName}>{v ? `${p.name} (${v.name})` : p.name}</span>
+                  <span className={styles.productPrice}>${Number(v ? v.price : p.price).toFixed(2)}</span>
                 </button>
               ))}
             </div>
@@ -184,8 +240,8 @@ export default function Sales() {
                 {cart.map((item, i) => (
                   <li key={i} className={styles.cartItem}>
                     <div>
-                      <strong>{item.product.name}</strong> x {item.quantity} = $
-                      {(item.product.price * item.quantity).toFixed(2)}
+                      <strong>{item.product.name}{item.variant && ` (${item.variant.name})`}</strong> x {item.quantity} = $
+                      {(unitPrice(item) * item.quantity).toFixed(2)}
                     </div>
                     <div className={styles.cartActions}>
                       <button onClick={() => updateQty(i, -1)} type="button">-</button>
@@ -201,7 +257,7 @@ export default function Sales() {
               </div>
               <button
                 onClick={handleCompleteSale}
-                disabled={saving || total <= 0}
+                disabled={saving || total <= 0 || !session}
                 className={styles.completeBtn}
                 type="button"
               >
@@ -15,13 +15,58 @@ api.interceptors.request.use((config) => {
   return config;
 });
 
+// Una sola renovación a la vez: cada refresh token sirve una vez y presentar
+// uno ya usado cierra la sesión
+let refreshing: Promise<string> | null = null;
+
+// refreshSession canjea el refresh token guardado por un par nuevo y devuelve
+// el access token
+export function refreshSession(): Promise<string> {
+  if (!refreshing) {
+    const refreshToken = localStorage.getItem('refreshToken') ?? '';
+    refreshing = axios
+      .post(`${API_BASE}/auth/refresh`, { refresh_token: refreshToken })
+      .then(({ data }) => {
+        localStorage.setItem('token', data.token);
+        localStorage.setItem('refreshToken', data.refresh_token);
+        localStorage.setItem('user', JSON.stringify(data.user));
+        return data.token as string;
+      })
+      .finally(() => {
+        refreshing = null;
+      });
+  }
+  return refreshing;
+}
+
+// Las rutas de /auth y /terminal responden 401 por credenciales o PIN
+// incorrectos, no por un access token vencido
+const sessionless = (url?: string) => !!url && (url.startsWith('/auth/') || url.startsWith('/terminal/'));
+
+function endSession() {
+  localStorage.removeItem('token');
+  localStorage.removeItem('refreshToken');
+  localStorage.removeItem('user');
+  window.location.href = '/login';
+}
+
 api.interceptors.response.use(
   (res) => res,
-  (err) => {
-    if (err.response?.status === 401) {
-      localStorage.removeItem('token');
-      localStorage.removeItem('user');
-      window.location.href = '/login';
+  async (err) => {
+    const original = err.config;
+    // Access token vencido: se renueva y se repite la petición una vez
+    if (err.response?.status === 401 && original && !original._retried && !sessionless(original.url)) {
+      original._retried = true;
+      try {
+        await refreshSession();
+      } catch {
+        endSession();
+        return Promise.reject(err);
+      }
+      return api(original);
+    }
+    if (err.response?.status === 401 && !sessionless(original?.url)) {
+      endSession();
     }
     return Promise.reject(err);
   }
@@ -31,13 +76,86 @@ api.interceptors.response.use(
 export const authApi = {
   register: (data: { restaurant_name: string; email: string; password: string; phone?: string; address?: string; tax_id?: string }) =>
     api.post('/auth/register', data),
-  login: (data: { email: string; password: string }) => api.post('/auth/login', data),
+  // restaurant (slug) solo hace falta si el email existe en varios restaurantes;
+  // sin él la respuesta es 409 con la lista `restaurants` para elegir
+  login: (data: { email: string; password: string; restaurant?: string }) => api.post('/auth/login', data),
+  // Las respuestas de login y register traen refresh_token; refresh lo cambia por un par nuevo
+  refresh: (refreshToken: string) => api.post('/auth/refresh', { refresh_token: refreshToken }),
+  logout: (refreshToken: string) => api.post('/auth/logout', { refresh_token: refreshToken }),
+};
+
+// Restaurante (configuración)
+export const restaurantApi = {
+  get: () => api.get('/restaurant'),
+  update: (data: Partial<{
+    name: string;
+    slug: string;
+    phone: string;
+    address: string;
+    tax_id: string;
+    logo_url: string;
+    timezone: string;
+    prices_include_tax: boolean;
+    default_tax_rate_id: string;
+    max_discount_bps: number;
+  }>) => api.put('/restaurant', data),
+};
+
+// Usuarios (permiso users:manage); delete da de baja al usuario sin borrar sus ventas
+export const usersApi = {
+  list: () => api.get('/users'),
+  get: (id: string) => api.get(`/users/${id}`),
+  create: (data: { email: string; password: string; role: string }) => api.post('/users', data),
+  update: (id: string, data: { role?: string; active?: boolean }) => api.put(`/users/${id}`, data),
+  delete: (id: string) => api.delete(`/users/${id}`),
+  resetPassword: (id: string, password: string) => api.post(`/users/${id}/password`, { password }),
+  // PIN de 4 a 8 dígitos para entrar en las terminales compartidas
+  setPin: (id: string, pin: string) => api.put(`/users/${id}/pin`, { pin }),
+  clearPin: (id: string) => api.delete(`/users/${id}/pin`),
+};
+
+// Terminales compartidas (permiso settings:manage); create devuelve
+// { terminal, token } y el token solo se muestra esa vez
+export const terminalsApi = {
+  list: () => api.get('/terminals'),
+  create: (name: string) => api.post('/terminals', { name }),
+  update: (id: string, name: string) => api.put(`/terminals/${id}`, { name }),
+  delete: (id: string) => api.delete(`/terminals/${id}`),
+};
+
+// Pantalla de PIN de una terminal: se identifica con su token, no con la sesión.
+// switchUser responde como login; tras 5 PIN incorrectos el usuario queda
+// bloqueado 15 minutos (429)
+export const terminalApi = {
+  users: (terminalToken: string) =>
+    api.get('/terminal/users', { headers: { 'X-Terminal-Token': terminalToken } }),
+  switchUser: (terminalToken: string, data: { user_id: string; pin: string }) =>
+    api.post('/terminal/switch', data, { headers: { 'X-Terminal-Token': terminalToken } }),
+};
+
+// Roles: list devuelve { builtin, custom }; permissions, el catálogo de permisos
+export const rolesApi = {
+  list: () => api.get('/roles'),
+  permissions: () => api.get('/permissions'),
+  get: (id: string) => api.get(`/roles/${id}`),
+  create: (data: { name: string; description?: string; permissions: string[] }) => api.post('/roles', data),
+  update: (id: string, data: { name: string; description?: string; permissions: string[] }) => api.put(`/roles/${id}`, data),
+  delete: (id: string) => api.delete(`/roles/${id}`),
+};
+
+// Tasas de impuesto (rate_bps: 1600 = 16%)
+export const taxRatesApi = {
+  list: () => api.get('/tax-rates'),
+  create: (data: { name: string; rate_bps: number; exempt?: boolean }) => api.post('/tax-rates', data),
+  update: (id: string, data: { name: string; rate_bps: number; exempt?: boolean }) =>
+    api.put(`/tax-rates/${id}`, data),
+  delete: (id: string) => api.delete(`/tax-rates/${id}`),
 };
 
 // Categories
 export const categoriesApi = {
   list: () => api.get('/categories'),
-  create: (data: { name: string; description?: string; sort_order?: number }) =>
+  create: (data: { name: string; description?: string; sort_order?: number; tax_rate_id?: string; station_id?: string }) =>
     api.post('/categories', data),
 };
 
@@ -46,23 +164,258 @@ export const productsApi = {
   list: (params?: { category_id?: string; active?: string }) =>
     api.get('/products', { params }),
   get: (id: string) => api.get(`/products/${id}`),
-  create: (data: { category_id?: string; name: string; description?: string; price: number; image_url?: string; active?: boolean }) =>
+  create: (data: { category_id?: string; name: string; description?: string; price: number; image_url?: string; active?: boolean; tax_rate_id?: string; station_id?: string; type?: 'simple' | 'combo' }) =>
     api.post('/products', data),
-  update: (id: string, data: Partial<{ category_id: string; name: string; description: string; price: number; image_url: string; active: boolean }>) =>
+  update: (id: string, data: Partial<{ category_id: string; name: string; description: string; price: number; image_url: string; active: boolean; tax_rate_id: string; station_id: string; type: 'simple' | 'combo' }>) =>
     api.put(`/products/${id}`, data),
   delete: (id: string) => api.delete(`/products/${id}`),
+  variants: (id: string) => api.get(`/products/${id}/variants`),
+  createVariant: (id: string, data: { name: string; sku?: string; price: number; active?: boolean; sort_order?: number }) =>
+    api.post(`/products/${id}/variants`, data),
+  updateVariant: (id: string, variantId: string, data: { name: string; sku?: string; price: number; active?: boolean; sort_order?: number }) =>
+    api.put(`/products/${id}/variants/${variantId}`, data),
+  deleteVariant: (id: string, variantId: string) => api.delete(`/products/${id}/variants/${variantId}`),
+  comboSlots: (id: string) => api.get(`/products/${id}/combo-slots`),
+  setComboSlots: (id: string, slots: Array<{
+    name: string;
+    quantity?: number;
+    required?: boolean;
+    options: Array<{ product_id: string; upcharge?: number }>;
+  }>) => api.put(`/products/${id}/combo-slots`, { slots }),
+  modifierGroups: (id: string) => api.get(`/products/${id}/modifier-groups`),
+  setModifierGroups: (id: string, groupIds: string[]) =>
+    api.put(`/products/${id}/modifier-groups`, { group_ids: groupIds }),
+};
+
+// Modifier groups (catálogo de extras/opciones; max_select 0 = sin límite)
+type ModifierGroupData = { name: string; min_select?: number; max_select?: number; required?: boolean; sort_order?: number };
+type ModifierOptionData = { name: string; price: number; active?: boolean; sort_order?: number };
+export const modifiersApi = {
+  list: () => api.get('/modifier-groups'),
+  get: (id: string) => api.get(`/modifier-groups/${id}`),
+  create: (data: ModifierGroupData) => api.post('/modifier-groups', data),
+  update: (id: string, data: ModifierGroupData) => api.put(`/modifier-groups/${id}`, data),
+  delete: (id: string) => api.delete(`/modifier-groups/${id}`),
+  createOption: (groupId: string, data: ModifierOptionData) => api.post(`/modifier-groups/${groupId}/options`, data),
+  updateOption: (groupId: string, optionId: string, data: ModifierOptionData) =>
+    api.put(`/modifier-groups/${groupId}/options/${optionId}`, data),
+  deleteOption: (groupId: string, optionId: string) => api.delete(`/modifier-groups/${groupId}/options/${optionId}`),
+};
+
+// Inventory (cantidades en la unidad del insumo, hasta 3 decimales)
+type IngredientData = { name: string; unit: 'unit' | 'g' | 'kg' | 'ml' | 'l'; initial_stock?: number; low_stock_threshold?: number; active?: boolean };
+type RecipeData = { variant_id?: string; items: Array<{ ingredient_id: string; quantity: number }> };
+export const inventoryApi = {
+  ingredients: () => api.get('/inventory/ingredients'),
+  get: (id: string) => api.get(`/inventory/ingredients/${id}`),
+  create: (data: IngredientData) => api.post('/inventory/ingredients', data),
+  update: (id: string, data: IngredientData) => api.put(`/inventory/ingredients/${id}`, data),
+  delete: (id: string) => api.delete(`/inventory/ingredients/${id}`),
+  // delta suma/resta; count fija el stock contado
+  adjust: (id: string, data: { delta?: number; count?: number; reason: string }) =>
+    api.post(`/inventory/ingredients/${id}/adjustments`, data),
+  movements: (id: string, params?: { limit?: number }) => api.get(`/inventory/ingredients/${id}/movements`, { params }),
+  lowStock: () => api.get('/inventory/low-stock'),
+  productRecipe: (productId: string) => api.get(`/products/${productId}/recipe`),
+  setProductRecipe: (productId: string, data: RecipeData) => api.put(`/products/${productId}/recipe`, data),
+  optionRecipe: (groupId: string, optionId: string) => api.get(`/modifier-groups/${groupId}/options/${optionId}/recipe`),
+  setOptionRecipe: (groupId: string, optionId: string, data: RecipeData) =>
+    api.put(`/modifier-groups/${groupId}/options/${optionId}/recipe`, data),
+};
+
+// Compras
+type SupplierData = { name: string; contact_name?: string; phone?: string; email?: string; notes?: string; active?: boolean };
+export const purchasingApi = {
+  suppliers: (params?: { active?: boolean }) => api.get('/suppliers', { params }),
+  createSupplier: (data: SupplierData) => api.post('/suppliers', data),
+  updateSupplier: (id: string, data: SupplierData) => api.put(`/suppliers/${id}`, data),
+  deleteSupplier: (id: string) => api.delete(`/suppliers/${id}`),
+  orders: (params?: { status?: string; supplier_id?: string; limit?: number }) => api.get('/purchase-orders', { params }),
+  getOrder: (id: string) => api.get(`/purchase-orders/${id}`),
+  createOrder: (data: {
+    supplier_id: string;
+    items: Array<{ ingredient_id: string; quantity: number; unit_cost: number }>;
+    notes?: string;
+    expected_at?: string;
+  }) => api.post('/purchase-orders', data),
+  // Sin items se recibe todo lo pendiente
+  receive: (id: string, data: { items?: Array<{ purchase_order_item_id: string; quantity: number; unit_cost?: number }>; notes?: string }) =>
+    api.post(`/purchase-orders/${id}/receipts`, data),
+  cancelOrder: (id: string) => api.post(`/purchase-orders/${id}/cancel`),
+  ledger: (params?: { ingredient_id?: string; supplier_id?: string; from?: string; to?: string; limit?: number }) =>
+    api.get('/purchase-ledger', { params }),
 };
 
 // Sales
+// Descuento: percent usa value como porcentaje (12.5 = 12.5%), fixed como importe
+type Discount = { type: 'percent' | 'fixed'; value: number; reason?: string };
+type SaleItemData = {
+  product_id: string;
+  variant_id?: string;
+  quantity: number;
+  notes?: string;
+  modifiers?: Array<{ option_id: string; quantity?: number }>;
+  components?: Array<{
+    slot_id: string;
+    product_id: string;
+    variant_id?: string;
+    notes?: string;
+    modifiers?: Array<{ option_id: string; quantity?: number }>;
+  }>;
+  discount?: Discount;
+};
+type PaymentData = {
+  payments: Array<{ method: string; amount: number; tip?: number; reference?: string }>;
+  discount?: Discount;
+  // percent_bps con type=percent (1500 = 15%), amount con type=fixed
+  tip?: { type: 'percent'; percent_bps: number } | { type: 'fixed'; amount: number };
+};
 export const salesApi = {
-  create: (data: {
-    items: Array<{
-      product_id: string;
-      quantity: number;
-      notes?: string;
-      toppings?: Array<{ name: string; price: number; quantity: number }>;
-    }>;
-    payments: Array<{ method: string; amount: number; reference?: string }>;
-  }) => api.post('/sales', data),
+  create: (data: { items: SaleItemData[] } & PaymentData) => api.post('/sales', data),
+  list: (params?: {
+    from?: string;
+    to?: string;
+    status?: string;
+    user_id?: string;
+    payment_method?: string;
+    min_total?: number;
+    max_total?: number;
+    cursor?: string;
+    limit?: number;
+  }) => api.get('/sales', { params }),
   get: (id: string) => api.get(`/sales/${id}`),
+  cancel: (id: string, reason: string) => api.post(`/sales/${id}/cancel`, { reason }),
+  // Ticket térmico: ESC/POS crudo para la impresora (drawer abre el cajón; requiere cash:operate) o PDF del ancho del papel
+  receiptEscpos: (id: string, params?: { width?: 58 | 80; drawer?: boolean }) =>
+    api.get(`/sales/${id}/receipt.escpos`, { params, responseType: 'arraybuffer' }),
+  receiptPdf: (id: string, params?: { width?: 58 | 80 }) =>
+    api.get(`/sales/${id}/receipt.pdf`, { params, responseType: 'blob' }),
+  refunds: (id: string) => api.get(`/sales/${id}/refunds`),
+  refund: (id: string, data: {
+    items: Array<{ sale_item_id: string; quantity: number }>;
+    payments: Array<{ method: string; amount: number; reference?: string }>;
+    reason?: string;
+  }) => api.post(`/sales/${id}/refunds`, data),
+};
+
+// Mesas y cuentas abiertas
+type TableData = { name: string; area_id?: string; seats?: number; active?: boolean; sort_order?: number };
+export const tablesApi = {
+  areas: () => api.get('/table-areas'),
+  createArea: (data: { name: string; sort_order?: number }) => api.post('/table-areas', data),
+  updateArea: (id: string, data: { name: string; sort_order?: number }) => api.put(`/table-areas/${id}`, data),
+  deleteArea: (id: string) => api.delete(`/table-areas/${id}`),
+  list: (params?: { area_id?: string; active?: boolean }) => api.get('/tables', { params }),
+  get: (id: string) => api.get(`/tables/${id}`),
+  create: (data: TableData) => api.post('/tables', data),
+  update: (id: string, data: TableData) => api.put(`/tables/${id}`, data),
+  delete: (id: string) => api.delete(`/tables/${id}`),
+};
+
+export const ordersApi = {
+  list: (params?: { table_id?: string }) => api.get('/orders', { params }),
+  open: (data: { table_id?: string; items?: SaleItemData[] }) => api.post('/orders', data),
+  get: (id: string) => api.get(`/orders/${id}`),
+  addItems: (id: string, items: SaleItemData[]) => api.post(`/orders/${id}/items`, { items }),
+  removeItem: (id: string, itemId: string) => api.delete(`/orders/${id}/items/${itemId}`),
+  transfer: (id: string, tableId: string) => api.post(`/orders/${id}/transfer`, { table_id: tableId }),
+  // Sin quantity se mueve la línea completa; devuelve la cuenta nueva
+  split: (id: string, data: { items: Array<{ item_id: string; quantity?: number }>; table_id?: string }) =>
+    api.post(`/orders/${id}/split`, data),
+  merge: (id: string, orderId: string) => api.post(`/orders/${id}/merge`, { order_id: orderId }),
+  close: (id: string, data: PaymentData) => api.post(`/orders/${id}/close`, data),
+  // Anula una cuenta que no se cobrará; no se puede con subcuentas ya cobradas
+  void: (id: string, reason: string) => api.post(`/orders/${id}/void`, { reason }),
+  // Subcuentas: por líneas (quantity unidades en parts partes) o en parts partes iguales
+  checks: (id: string) => api.get(`/orders/${id}/checks`),
+  splitChecks: (id: string, data: {
+    checks?: Array<{ items: Array<{ item_id: string; quantity?: number; parts?: number }> }>;
+    parts?: number;
+    discount?: Discount;
+  }) => api.post(`/orders/${id}/checks`, data),
+  payCheck: (id: string, checkId: string, data: Omit<PaymentData, 'discount'>) =>
+    api.post(`/orders/${id}/checks/${checkId}/pay`, data),
+  checkPdf: (id: string, checkId: string) =>
+    api.get(`/orders/${id}/checks/${checkId}/pdf`, { responseType: 'blob' }),
+};
+
+// Cocina: la pantalla consulta con updated_since para recibir solo lo que cambió
+export const kitchenApi = {
+  stations: () => api.get('/kitchen/stations'),
+  createStation: (data: { name: string; active?: boolean; sort_order?: number }) => api.post('/kitchen/stations', data),
+  updateStation: (id: string, data: { name: string; active?: boolean; sort_order?: number }) =>
+    api.put(`/kitchen/stations/${id}`, data),
+  deleteStation: (id: string) => api.delete(`/kitchen/stations/${id}`),
+  tickets: (params?: { station_id?: string; status?: string; updated_since?: string; limit?: number }) =>
+    api.get('/kitchen/tickets', { params }),
+  ticket: (id: string) => api.get(`/kitchen/tickets/${id}`),
+  setStatus: (id: string, status: 'new' | 'in_progress' | 'ready' | 'served') => api.post(`/kitchen/tickets/${id}/status`, { status }),
+  ticketEscpos: (id: string, params?: { width?: 58 | 80 }) =>
+    api.get(`/kitchen/tickets/${id}/ticket.escpos`, { params, responseType: 'arraybuffer' }),
+  ticketPdf: (id: string, params?: { width?: 58 | 80 }) =>
+    api.get(`/kitchen/tickets/${id}/ticket.pdf`, { params, responseType: 'blob' }),
+};
+
+// Cash sessions (turnos de caja)
+export const cashSessionsApi = {
+  list: () => api.get('/cash-sessions'),
+  current: () => api.get('/cash-sessions/current'),
+  open: (data: { opening_float: number; notes?: string }) => api.post('/cash-sessions', data),
+  get: (id: string) => api.get(`/cash-sessions/${id}`),
+  close: (id: string, data: { counted_cash: number; notes?: string }) =>
+    api.post(`/cash-sessions/${id}/close`, data),
+};
+
+// Reports (solo admin)
+type ReportParams = { from?: string; to?: string; limit?: number };
+export const reportsApi = {
+  summary: (params?: ReportParams) => api.get('/reports/summary', { params }),
+  byDay: (params?: ReportParams) => api.get('/reports/sales-by-day', { params }),
+  byHour: (params?: ReportParams) => api.get('/reports/sales-by-hour', { params }),
+  topProducts: (params?: ReportParams) => api.get('/reports/top-products', { params }),
+  byCategory: (params?: ReportParams) => api.get('/reports/sales-by-category', { params }),
+  byCashier: (params?: ReportParams) => api.get('/reports/sales-by-cashier', { params }),
+  tipsByCashier: (params?: ReportParams) => api.get('/reports/tips-by-cashier', { params }),
+  foodCost: () => api.get('/reports/food-cost'),
+};
+
+// Eventos en tiempo real (server-sent events). EventSource se reconecta solo;
+// al reconectar conviene volver a consultar lo que se muestra.
+export type ServerEventType =
+  | 'sale.created'
+  | 'sale.cancelled'
+  | 'product.updated'
+  | 'product.deleted'
+  | 'kitchen_ticket.created'
+  | 'kitchen_ticket.status_changed'
+  | 'kitchen_ticket.updated';
+
+export const eventsApi = {
+  subscribe: (types: ServerEventType[], onEvent: (type: ServerEventType, data: unknown) => void) => {
+    let source: EventSource | null = null;
+    let closed = false;
+    // EventSource reconecta con la misma URL; como el access token vence,
+    // ante un error se renueva la sesión y se abre una conexión nueva
+    const connect = () => {
+      const token = localStorage.getItem('token') ?? '';
+      source = new EventSource(`${API_BASE}/events?access_token=${encodeURIComponent(token)}`);
+      for (const type of types) {
+        source.addEventListener(type, (e) => onEvent(type, JSON.parse((e as MessageEvent).data).data));
+      }
+      source.onerror = () => {
+        source?.close();
+        if (closed) return;
+        refreshSession()
+          .catch(() => undefined)
+          .then(() => {
+            if (!closed) setTimeout(connect, 1000);
+          });
+      };
+    };
+    connect();
+    return () => {
+      closed = true;
+      source?.close();
+    };
+  },
 };
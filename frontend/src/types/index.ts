@@ -7,8 +7,34 @@ export interface Product {
   price: number;
   image_url?: string;
   active: boolean;
+  tax_rate_id?: string;
+  station_id?: string;
+  type: 'simple' | 'combo';
   created_at: string;
   updated_at: string;
+  variants?: ProductVariant[];
+  slots?: ComboSlot[];
+}
+
+// Espacio de un combo; quantity son las unidades del componente por combo
+export interface ComboSlot {
+  id: string;
+  combo_id: string;
+  name: string;
+  quantity: number;
+  required: boolean;
+  sort_order: number;
+  options: { product_id: string; product_name: string; upcharge: number; sort_order: number }[];
+}
+
+export interface ProductVariant {
+  id: string;
+  product_id: string;
+  name: string;
+  sku?: string;
+  price: number;
+  active: boolean;
+  sort_order: number;
 }
 
 export interface Category {
@@ -17,14 +43,293 @@ export interface Category {
   name: string;
   description: string;
   sort_order: number;
+  tax_rate_id?: string;
+  station_id?: string;
+}
+
+export interface ModifierOption {
+  id: string;
+  group_id: string;
+  name: string;
+  price: number;
+  active: boolean;
+  sort_order: number;
+}
+
+export interface ModifierGroup {
+  id: string;
+  restaurant_id: string;
+  name: string;
+  min_select: number;
+  max_select: number;
+  required: boolean;
+  sort_order: number;
+  options: ModifierOption[];
+}
+
+export interface TaxRate {
+  id: string;
+  restaurant_id: string;
+  name: string;
+  rate_bps: number;
+  exempt: boolean;
 }
 
 export interface Sale {
   id: string;
   restaurant_id: string;
   user_id: string;
+  subtotal: number;
+  tax_total: number;
   total: number;
+  discount_type?: 'percent' | 'fixed';
+  discount_value?: number;
+  discount_amount: number;
+  discount_reason?: string;
+  discount_total: number;
+  tip_total: number;
   status: string;
+  cancelled_at?: string;
+  cancelled_by?: string;
+  cancel_reason?: string;
+  table_id?: string;
+  opened_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface SaleCheckItem {
+  id: string;
+  check_id: string;
+  sale_item_id: string;
+  share_num: number;
+  share_den: number;
+  tax_amount: number;
+  total: number;
+}
+
+export interface SaleCheck {
+  id: string;
+  sale_id: string;
+  number: number;
+  tax_total: number;
+  total: number;
+  tip_total: number;
+  status: 'open' | 'paid';
+  paid_at?: string;
+  items?: SaleCheckItem[];
+  payments?: Array<{ id: string; method: string; amount: number; tip: number; reference?: string; check_id?: string }>;
+}
+
+export interface TableArea {
+  id: string;
+  name: string;
+  sort_order: number;
+}
+
+export interface Table {
+  id: string;
+  area_id?: string;
+  name: string;
+  seats: number;
+  active: boolean;
+  sort_order: number;
+  open_orders: number;
+  open_total: number;
+}
+
+export interface CashSession {
+  id: string;
+  restaurant_id: string;
+  user_id: string;
+  status: 'open' | 'closed';
+  opening_float: number;
+  expected_cash?: number;
+  counted_cash?: number;
+  difference?: number;
+  notes?: string;
+  opened_at: string;
+  closed_at?: string;
+}
+
+export interface Ingredient {
+  id: string;
+  restaurant_id: string;
+  name: string;
+  unit: 'unit' | 'g' | 'kg' | 'ml' | 'l';
+  stock: number;
+  low_stock_threshold: number;
+  active: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface RecipeItem {
+  id: string;
+  product_id?: string;
+  variant_id?: string;
+  modifier_option_id?: string;
+  ingredient_id: string;
+  ingredient_name: string;
+  unit: string;
+  quantity: number;
+}
+
+export interface StockMovement {
+  id: string;
+  ingredient_id: string;
+  quantity: number;
+  type: 'sale' | 'sale_cancel' | 'adjustment' | 'purchase';
+  reason?: string;
+  sale_id?: string;
+  goods_receipt_id?: string;
+  user_id?: string;
+  created_at: string;
+}
+
+export interface Supplier {
+  id: string;
+  name: string;
+  contact_name?: string;
+  phone?: string;
+  email?: string;
+  notes?: string;
+  active: boolean;
+}
+
+export interface PurchaseOrderItem {
+  id: string;
+  ingredient_id: string;
+  ingredient_name: string;
+  unit: string;
+  quantity: number;
+  unit_cost: number;
+  received_quantity: number;
+}
+
+export interface GoodsReceiptItem {
+  id: string;
+  goods_receipt_id: string;
+  purchase_order_item_id: string;
+  ingredient_id: string;
+  ingredient_name: string;
+  unit: string;
+  quantity: number;
+  unit_cost: number;
+  total_cost: number;
+  purchase_order_id?: string;
+  supplier_id?: string;
+  supplier_name?: string;
+  created_at: string;
+}
+
+export interface GoodsReceipt {
+  id: string;
+  purchase_order_id: string;
+  received_by: string;
+  total: number;
+  notes?: string;
+  created_at: string;
+  items?: GoodsReceiptItem[];
+}
+
+export interface PurchaseOrder {
+  id: string;
+  supplier_id: string;
+  supplier_name: string;
+  status: 'open' | 'partial' | 'received' | 'cancelled';
+  total: number;
+  notes?: string;
+  expected_at?: string;
+  created_at: string;
+  items?: PurchaseOrderItem[];
+  receipts?: GoodsReceipt[];
+}
+
+export interface ProductCost {
+  product_id: string;
+  variant_id?: string;
+  name: string;
+  type: 'simple' | 'combo';
+  price: number;
+  cost: number;
+  margin: number;
+  food_cost_bps: number;
+  margin_bps: number;
+  has_recipe: boolean;
+  missing_costs?: string[];
+}
+
+export interface KitchenStation {
+  id: string;
+  restaurant_id: string;
+  name: string;
+  active: boolean;
+  sort_order: number;
+}
+
+// voided: la venta se anuló o se quitaron de la cuenta todas sus líneas
+export type KitchenTicketStatus = 'new' | 'in_progress' | 'ready' | 'served' | 'voided';
+
+// modifiers son los toppings ya descritos ("Extra queso x2")
+export interface KitchenTicketItem {
+  id: string;
+  ticket_id: string;
+  sale_item_id?: string;
+  position: number;
+  name: string;
+  quantity: number;
+  notes?: string;
+  modifiers: string[];
+  combo_name?: string;
+}
+
+export interface KitchenTicket {
+  id: string;
+  restaurant_id: string;
+  station_id: string;
+  station_name: string;
+  sale_id: string;
+  table_name?: string;
+  status: KitchenTicketStatus;
+  started_at?: string;
+  ready_at?: string;
+  served_at?: string;
+  voided_at?: string;
+  created_at: string;
+  updated_at: string;
+  items: KitchenTicketItem[];
+}
+
+// Usuario del restaurante; role es un rol predefinido o propio del restaurante
+export interface User {
+  id: string;
+  restaurant_id: string;
+  email: string;
+  role: string;
+  active: boolean;
+  has_pin: boolean; // puede entrar con PIN en una terminal compartida
+  created_at: string;
+  updated_at: string;
+}
+
+// Terminal compartida donde los usuarios cambian de turno con su PIN
+export interface Terminal {
+  id: string;
+  restaurant_id: string;
+  name: string;
+  last_used_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+// Rol propio del restaurante; los predefinidos llegan en GET /roles como builtin
+export interface Role {
+  id: string;
+  restaurant_id: string;
+  name: string;
+  description: string;
+  permissions: string[];
   created_at: string;
   updated_at: string;
 }